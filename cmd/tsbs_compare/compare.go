@@ -0,0 +1,224 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"text/tabwriter"
+)
+
+const (
+	statusOK         = "ok"
+	statusImproved   = "improved"
+	statusRegression = "REGRESSION"
+	// statusNoise is the status of a change over the threshold that is not
+	// statistically significant
+	statusNoise = "not significant"
+)
+
+// thresholds configures when a change is a regression
+type thresholds struct {
+	// rate is the maximum relative decrease of a rate
+	rate float64
+	// latency is the maximum relative increase of a latency quantile
+	latency float64
+	// significance is the p-value below which a change is statistically
+	// significant, if both groups have at least two runs
+	significance float64
+}
+
+// comparison is the result of comparing a value of a candidate group with the
+// same value of the baseline group
+type comparison struct {
+	key       metricKey
+	baseline  float64 // mean of the baseline runs
+	candidate float64 // mean of the candidate runs
+	delta     float64 // relative change of the candidate to the baseline
+	pValue    float64 // NaN if there are not enough runs to test the change
+	status    string
+}
+
+// compareGroups compares every value present in both baseline and candidate.
+// The keys present in only one of them are returned as missing.
+func compareGroups(baseline, candidate *group, t thresholds) (cmps []comparison, missing []metricKey, err error) {
+	if baseline.kind != candidate.kind {
+		return nil, nil, fmt.Errorf("cannot compare %s results of %s with %s results of %s",
+			candidate.kind, candidate.name, baseline.kind, baseline.name)
+	}
+	candidateKeys := make(map[metricKey]bool)
+	for _, k := range candidate.keys() {
+		candidateKeys[k] = true
+	}
+	for _, k := range baseline.keys() {
+		if !candidateKeys[k] {
+			missing = append(missing, k)
+			continue
+		}
+		delete(candidateKeys, k)
+		cmps = append(cmps, compareSamples(k, baseline.samples(k), candidate.samples(k), t))
+	}
+	for _, k := range candidate.keys() {
+		if candidateKeys[k] {
+			missing = append(missing, k)
+		}
+	}
+	return cmps, missing, nil
+}
+
+func compareSamples(key metricKey, baseline, candidate []float64, t thresholds) comparison {
+	c := comparison{
+		key:       key,
+		baseline:  mean(baseline),
+		candidate: mean(candidate),
+		pValue:    welchTTest(baseline, candidate),
+		status:    statusOK,
+	}
+	if c.baseline != 0 {
+		c.delta = (c.candidate - c.baseline) / c.baseline
+	}
+
+	// rates get worse when they decrease, latencies when they increase
+	worse, limit := c.delta, t.latency
+	if isRate(key) {
+		worse, limit = -c.delta, t.rate
+	}
+	switch {
+	case worse > limit:
+		c.status = statusRegression
+	case worse < -limit:
+		c.status = statusImproved
+	default:
+		return c
+	}
+	if !math.IsNaN(c.pValue) && c.pValue >= t.significance {
+		c.status = statusNoise
+	}
+	return c
+}
+
+func isRate(key metricKey) bool {
+	return key.metric == rateMetric || key.metric == "metricRate" || key.metric == "rowRate"
+}
+
+// writeComparisons writes a table of the comparisons and the missing keys to w
+func writeComparisons(w io.Writer, cmps []comparison, missing []metricKey) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if _, err := fmt.Fprintln(tw, "label\tmetric\tbaseline\tcandidate\tdelta\tp-value\tstatus"); err != nil {
+		return err
+	}
+	for _, c := range cmps {
+		p := "-"
+		if !math.IsNaN(c.pValue) {
+			p = fmt.Sprintf("%.4f", c.pValue)
+		}
+		_, err := fmt.Fprintf(tw, "%s\t%s\t%.2f\t%.2f\t%+.2f%%\t%s\t%s\n",
+			c.key.label, c.key.metric, c.baseline, c.candidate, c.delta*100, p, c.status)
+		if err != nil {
+			return err
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	for _, k := range missing {
+		if _, err := fmt.Fprintf(w, "%s %s: present in only one of the results\n", k.label, k.metric); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func mean(s []float64) float64 {
+	sum := 0.0
+	for _, v := range s {
+		sum += v
+	}
+	return sum / float64(len(s))
+}
+
+func variance(s []float64, m float64) float64 {
+	sum := 0.0
+	for _, v := range s {
+		sum += (v - m) * (v - m)
+	}
+	return sum / float64(len(s)-1)
+}
+
+// welchTTest returns the two-sided p-value of Welch's t-test for the means of
+// a and b being equal, or NaN if either has less than two values.
+func welchTTest(a, b []float64) float64 {
+	if len(a) < 2 || len(b) < 2 {
+		return math.NaN()
+	}
+	ma, mb := mean(a), mean(b)
+	va := variance(a, ma) / float64(len(a))
+	vb := variance(b, mb) / float64(len(b))
+	if va+vb == 0 {
+		if ma == mb {
+			return 1
+		}
+		return 0
+	}
+	t := (ma - mb) / math.Sqrt(va+vb)
+	df := (va + vb) * (va + vb) /
+		(va*va/float64(len(a)-1) + vb*vb/float64(len(b)-1))
+	// P(|T| > |t|) of Student's t distribution with df degrees of freedom
+	return regIncBeta(df/2, 0.5, df/(df+t*t))
+}
+
+// regIncBeta returns the regularized incomplete beta function I_x(a, b)
+func regIncBeta(a, b, x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if x >= 1 {
+		return 1
+	}
+	la, _ := math.Lgamma(a)
+	lb, _ := math.Lgamma(b)
+	lab, _ := math.Lgamma(a + b)
+	front := math.Exp(lab - la - lb + a*math.Log(x) + b*math.Log(1-x))
+	// the continued fraction converges quickly for x < (a+1)/(a+b+2)
+	if x < (a+1)/(a+b+2) {
+		return front * betaContinuedFraction(a, b, x) / a
+	}
+	return 1 - front*betaContinuedFraction(b, a, 1-x)/b
+}
+
+// betaContinuedFraction evaluates the continued fraction of the incomplete
+// beta function with the modified Lentz's method.
+func betaContinuedFraction(a, b, x float64) float64 {
+	const (
+		maxIterations = 300
+		epsilon       = 1e-14
+		tiny          = 1e-300
+	)
+	c, d := 1.0, 1-(a+b)*x/(a+1)
+	if math.Abs(d) < tiny {
+		d = tiny
+	}
+	d = 1 / d
+	h := d
+	for m := 1; m <= maxIterations; m++ {
+		fm := float64(m)
+		for _, num := range []float64{
+			fm * (b - fm) * x / ((a + 2*fm - 1) * (a + 2*fm)),
+			-(a + fm) * (a + b + fm) * x / ((a + 2*fm) * (a + 2*fm + 1)),
+		} {
+			d = 1 + num*d
+			if math.Abs(d) < tiny {
+				d = tiny
+			}
+			c = 1 + num/c
+			if math.Abs(c) < tiny {
+				c = tiny
+			}
+			d = 1 / d
+			h *= d * c
+		}
+		if math.Abs(d*c-1) < epsilon {
+			break
+		}
+	}
+	return h
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+)
+
+const queryResults = `{
+ "ResultFormatVersion": "0.1",
+ "Totals": {
+  "burnIn": 0,
+  "overallQuantiles": {
+   "all_queries": {"q0": 1, "q50": 10, "q95": 20, "q99": 30, "q999": 40, "q100": 50},
+   "cpu_max_all_8": {"q0": 1, "q50": 10, "q95": 20, "q99": 30, "q999": 40, "q100": 50}
+  },
+  "overallQueryRates": {
+   "all_queries": 100,
+   "cpu_max_all_8": 100
+  }
+ }
+}`
+
+const loadResults = `{
+ "ResultFormatVersion": "0.1",
+ "Totals": {"metricRate": 1000, "rowRate": 100}
+}`
+
+const loadResultsWithLatencies = `{
+ "ResultFormatVersion": "0.1",
+ "Totals": {
+  "metricRate": 1000,
+  "batchLatencyQuantiles": {
+   "all": {"q0": 1, "q50": 10, "q95": 20, "q99": 30, "q999": 40, "q100": 50},
+   "worker0": {"q0": 1, "q50": 10, "q95": 20, "q99": 30, "q999": 40, "q100": 50}
+  }
+ }
+}`
+
+func TestParseRun(t *testing.T) {
+	r, err := parseRun([]byte(queryResults), []string{"q50", "q99"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r.kind != kindQuery {
+		t.Errorf("incorrect kind: got %s want %s", r.kind, kindQuery)
+	}
+	want := map[metricKey]float64{
+		{"all_queries", rateMetric}:   100,
+		{"all_queries", "q50"}:        10,
+		{"all_queries", "q99"}:        30,
+		{"cpu_max_all_8", rateMetric}: 100,
+		{"cpu_max_all_8", "q50"}:      10,
+		{"cpu_max_all_8", "q99"}:      30,
+	}
+	checkValues(t, r.values, want)
+
+	r, err = parseRun([]byte(loadResults), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r.kind != kindLoad {
+		t.Errorf("incorrect kind: got %s want %s", r.kind, kindLoad)
+	}
+	checkValues(t, r.values, map[metricKey]float64{
+		{loadLabel, "metricRate"}: 1000,
+		{loadLabel, "rowRate"}:    100,
+	})
+
+	r, err = parseRun([]byte(loadResultsWithLatencies), []string{"q99"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	checkValues(t, r.values, map[metricKey]float64{
+		{loadLabel, "metricRate"}: 1000,
+		{loadLabel, "q99"}:        30,
+	})
+
+	if _, err := parseRun([]byte(`{"Totals": {}}`), nil); err == nil {
+		t.Errorf("expected an error for unknown results")
+	}
+}
+
+func checkValues(t *testing.T, got, want map[metricKey]float64) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Errorf("incorrect number of values: got %d want %d", len(got), len(want))
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("incorrect value of %v: got %f want %f", k, got[k], v)
+		}
+	}
+}
+
+func TestCompareSamples(t *testing.T) {
+	th := thresholds{rate: 0.05, latency: 0.1, significance: 0.05}
+	rate := metricKey{"all_queries", rateMetric}
+	latency := metricKey{"all_queries", "q50"}
+	testCases := []struct {
+		desc      string
+		key       metricKey
+		baseline  []float64
+		candidate []float64
+		want      string
+	}{
+		{"rate within threshold", rate, []float64{100}, []float64{96}, statusOK},
+		{"rate decreased", rate, []float64{100}, []float64{90}, statusRegression},
+		{"rate increased", rate, []float64{100}, []float64{110}, statusImproved},
+		{"latency within threshold", latency, []float64{10}, []float64{10.5}, statusOK},
+		{"latency increased", latency, []float64{10}, []float64{12}, statusRegression},
+		{"latency decreased", latency, []float64{10}, []float64{8}, statusImproved},
+		{"significant latency increase", latency, []float64{10, 10.1, 9.9}, []float64{12, 12.1, 11.9}, statusRegression},
+		{"noisy latency increase", latency, []float64{5, 15, 10}, []float64{6, 18, 12}, statusNoise},
+	}
+	for _, tc := range testCases {
+		c := compareSamples(tc.key, tc.baseline, tc.candidate, th)
+		if c.status != tc.want {
+			t.Errorf("%s: incorrect status: got %s want %s", tc.desc, c.status, tc.want)
+		}
+	}
+}
+
+func TestCompareGroups(t *testing.T) {
+	baseline := &group{name: "a", kind: kindQuery, runs: []*run{{values: map[metricKey]float64{
+		{"x", rateMetric}: 100,
+		{"y", rateMetric}: 100,
+	}}}}
+	candidate := &group{name: "b", kind: kindQuery, runs: []*run{{values: map[metricKey]float64{
+		{"x", rateMetric}: 50,
+		{"z", rateMetric}: 100,
+	}}}}
+	cmps, missing, err := compareGroups(baseline, candidate, thresholds{rate: 0.05})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cmps) != 1 || cmps[0].status != statusRegression || cmps[0].delta != -0.5 {
+		t.Errorf("incorrect comparisons: %+v", cmps)
+	}
+	if len(missing) != 2 || missing[0].label != "y" || missing[1].label != "z" {
+		t.Errorf("incorrect missing keys: %v", missing)
+	}
+
+	var b bytes.Buffer
+	if err := writeComparisons(&b, cmps, missing); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(b.String(), "-50.00%") || !strings.Contains(b.String(), statusRegression) {
+		t.Errorf("incorrect output:\n%s", b.String())
+	}
+
+	candidate.kind = kindLoad
+	if _, _, err := compareGroups(baseline, candidate, thresholds{}); err == nil {
+		t.Errorf("expected an error comparing load with query results")
+	}
+}
+
+func TestWelchTTest(t *testing.T) {
+	p := welchTTest([]float64{1, 2, 3, 4, 5}, []float64{2, 3, 4, 5, 6})
+	// t = -1 with 8 degrees of freedom
+	if math.Abs(p-0.3466) > 1e-4 {
+		t.Errorf("incorrect p-value: got %f want 0.3466", p)
+	}
+	if !math.IsNaN(welchTTest([]float64{1}, []float64{1, 2})) {
+		t.Errorf("expected NaN for a single run")
+	}
+	if p := welchTTest([]float64{1, 1}, []float64{1, 1}); p != 1 {
+		t.Errorf("incorrect p-value of equal constant samples: got %f want 1", p)
+	}
+}
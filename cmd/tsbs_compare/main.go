@@ -0,0 +1,103 @@
+// tsbs_compare compares the results files written with --results-file by the
+// TSBS loaders or query runners.
+//
+// The first argument is the baseline, every following argument a candidate
+// that is compared with the baseline. An argument can be a comma-separated
+// list of the results files of repeated runs of the same setup, in which case
+// their mean is compared and changes are tested for statistical significance.
+// The program exits with a nonzero status if any candidate regressed.
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/blagojts/viper"
+	"github.com/spf13/pflag"
+	"github.com/timescale/tsbs/internal/utils"
+)
+
+// config is the configuration of tsbs_compare
+type config struct {
+	RateThreshold    float64 `mapstructure:"rate-threshold"`
+	LatencyThreshold float64 `mapstructure:"latency-threshold"`
+	Significance     float64 `mapstructure:"significance"`
+	Quantiles        string  `mapstructure:"quantiles"`
+}
+
+var (
+	conf  config
+	files []string
+)
+
+// parseArgs parses the flags and the results files to compare. It is not run
+// in init so that the tests of this package are not given the flags.
+func parseArgs() {
+	pflag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [flags] baseline.json[,baseline.json...] candidate.json[,candidate.json...]...\n", os.Args[0])
+		pflag.PrintDefaults()
+	}
+	pflag.Float64("rate-threshold", 0.05, "Maximum relative decrease of a rate (metrics, rows or queries per second) before it is a regression")
+	pflag.Float64("latency-threshold", 0.1, "Maximum relative increase of a query latency quantile before it is a regression")
+	pflag.Float64("significance", 0.05, "P-value below which a change is statistically significant, used if both sides have at least two runs")
+	pflag.String("quantiles", "q50,q95,q99", "Comma-separated list of query latency quantiles to compare (q0, q50, q95, q99, q999, q100)")
+
+	pflag.Parse()
+
+	if err := utils.SetupConfigFile(); err != nil {
+		panic(fmt.Errorf("fatal error config file: %s", err))
+	}
+	if err := viper.Unmarshal(&conf); err != nil {
+		panic(fmt.Errorf("unable to decode config: %s", err))
+	}
+
+	files = pflag.Args()
+	if len(files) < 2 {
+		pflag.Usage()
+		os.Exit(2)
+	}
+}
+
+func main() {
+	parseArgs()
+	quantiles := strings.Split(conf.Quantiles, ",")
+	t := thresholds{
+		rate:         conf.RateThreshold,
+		latency:      conf.LatencyThreshold,
+		significance: conf.Significance,
+	}
+
+	baseline, err := readGroup(files[0], quantiles)
+	if err != nil {
+		log.Fatal(err)
+	}
+	regressions := 0
+	for _, f := range files[1:] {
+		candidate, err := readGroup(f, quantiles)
+		if err != nil {
+			log.Fatal(err)
+		}
+		cmps, missing, err := compareGroups(baseline, candidate, t)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		fmt.Printf("Comparing %s with baseline %s:\n", candidate.name, baseline.name)
+		if err := writeComparisons(os.Stdout, cmps, missing); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println()
+		for _, c := range cmps {
+			if c.status == statusRegression {
+				regressions++
+			}
+		}
+	}
+
+	if regressions > 0 {
+		fmt.Fprintf(os.Stderr, "%d regression(s) found\n", regressions)
+		os.Exit(1)
+	}
+}
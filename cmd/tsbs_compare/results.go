@@ -0,0 +1,194 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+)
+
+const (
+	kindLoad  = "load"
+	kindQuery = "query"
+
+	// loadLabel is the label of the metrics of a load results file
+	loadLabel = "load"
+	// rateMetric is the name of the query rate of a query label
+	rateMetric = "rate"
+)
+
+// metricKey identifies a compared value of a results file
+type metricKey struct {
+	label  string
+	metric string
+}
+
+// resultsFile holds the parts of a results file written with --results-file
+// by the loaders (load.LoaderTestResult) or the query runners
+// (query.LoaderTestResult) that can be compared.
+type resultsFile struct {
+	ResultFormatVersion string                 `json:"ResultFormatVersion"`
+	Totals              map[string]interface{} `json:"Totals"`
+}
+
+// run holds the comparable values of one results file
+type run struct {
+	name   string
+	kind   string
+	values map[metricKey]float64
+}
+
+// readRun reads the results file fileName, keeping the quantiles in
+// quantiles of query results.
+func readRun(fileName string, quantiles []string) (*run, error) {
+	b, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	r, err := parseRun(b, quantiles)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read results file %s: %v", fileName, err)
+	}
+	r.name = fileName
+	return r, nil
+}
+
+func parseRun(b []byte, quantiles []string) (*run, error) {
+	var f resultsFile
+	if err := json.Unmarshal(b, &f); err != nil {
+		return nil, err
+	}
+	r := &run{values: make(map[metricKey]float64)}
+	if _, ok := f.Totals["overallQuantiles"]; ok {
+		r.kind = kindQuery
+		rates, err := asMap(f.Totals, "overallQueryRates")
+		if err != nil {
+			return nil, err
+		}
+		for label, v := range rates {
+			if err := r.set(label, rateMetric, v); err != nil {
+				return nil, err
+			}
+		}
+		labels, err := asMap(f.Totals, "overallQuantiles")
+		if err != nil {
+			return nil, err
+		}
+		for label, v := range labels {
+			qs, ok := v.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("quantiles of %s are not an object", label)
+			}
+			for _, q := range quantiles {
+				if qv, ok := qs[q]; ok {
+					if err := r.set(label, q, qv); err != nil {
+						return nil, err
+					}
+				}
+			}
+		}
+		return r, nil
+	}
+
+	if _, ok := f.Totals["metricRate"]; !ok {
+		return nil, fmt.Errorf("neither load nor query results")
+	}
+	r.kind = kindLoad
+	for _, m := range []string{"metricRate", "rowRate"} {
+		if v, ok := f.Totals[m]; ok {
+			if err := r.set(loadLabel, m, v); err != nil {
+				return nil, err
+			}
+		}
+	}
+	// the batch latencies are only in the results of newer loaders
+	if latencies, ok := f.Totals["batchLatencyQuantiles"].(map[string]interface{}); ok {
+		qs, ok := latencies["all"].(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("batch latency quantiles of all workers are not an object")
+		}
+		for _, q := range quantiles {
+			if qv, ok := qs[q]; ok {
+				if err := r.set(loadLabel, q, qv); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return r, nil
+}
+
+func (r *run) set(label, metric string, v interface{}) error {
+	f, ok := v.(float64)
+	if !ok {
+		return fmt.Errorf("%s of %s is not a number: %v", metric, label, v)
+	}
+	r.values[metricKey{label: label, metric: metric}] = f
+	return nil
+}
+
+func asMap(totals map[string]interface{}, key string) (map[string]interface{}, error) {
+	m, ok := totals[key].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s is missing or not an object", key)
+	}
+	return m, nil
+}
+
+// group holds repeated runs of the same benchmark setup, e.g. the runs of
+// the baseline.
+type group struct {
+	name string
+	kind string
+	runs []*run
+}
+
+// readGroup reads the comma-separated results files in files as one group
+func readGroup(files string, quantiles []string) (*group, error) {
+	g := &group{name: files}
+	for _, fileName := range strings.Split(files, ",") {
+		r, err := readRun(fileName, quantiles)
+		if err != nil {
+			return nil, err
+		}
+		if g.kind != "" && g.kind != r.kind {
+			return nil, fmt.Errorf("cannot group %s results of %s with %s results", r.kind, r.name, g.kind)
+		}
+		g.kind = r.kind
+		g.runs = append(g.runs, r)
+	}
+	return g, nil
+}
+
+// samples returns the values of key of all runs of the group that have it
+func (g *group) samples(key metricKey) []float64 {
+	var s []float64
+	for _, r := range g.runs {
+		if v, ok := r.values[key]; ok {
+			s = append(s, v)
+		}
+	}
+	return s
+}
+
+// keys returns the sorted keys that are present in any run of the group
+func (g *group) keys() []metricKey {
+	seen := make(map[metricKey]bool)
+	var keys []metricKey
+	for _, r := range g.runs {
+		for k := range r.values {
+			if !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].label != keys[j].label {
+			return keys[i].label < keys[j].label
+		}
+		return keys[i].metric < keys[j].metric
+	})
+	return keys
+}
@@ -0,0 +1,85 @@
+// tsbs_coordinator coordinates a load or query benchmark run by several TSBS
+// loaders or query runners, the agents, usually on several machines.
+//
+// The agents are started with --coordinator set to the address of the
+// coordinator. Each agent is assigned a shard of the data or queries, only one
+// of them creates the database, and all of them start at the same time once
+// the given number of agents registered. Their throughput and latency
+// histograms are merged into one combined result.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net"
+	"os"
+	"time"
+
+	"github.com/blagojts/viper"
+	"github.com/spf13/pflag"
+	"github.com/timescale/tsbs/internal/coordinator"
+	"github.com/timescale/tsbs/internal/utils"
+)
+
+// config is the configuration of tsbs_coordinator
+type config struct {
+	Listen      string        `mapstructure:"listen"`
+	Agents      int           `mapstructure:"agents"`
+	ResultsFile string        `mapstructure:"results-file"`
+	Timeout     time.Duration `mapstructure:"timeout"`
+}
+
+var conf config
+
+func init() {
+	pflag.String("listen", ":7070", "Address to listen for agents on")
+	pflag.Int("agents", 0, "Number of agents to wait for before starting the run")
+	pflag.String("results-file", "", "Write the combined results of all agents to this file")
+	pflag.Duration("timeout", 0, "Fail the run if the agents did not all send their results within this duration, e.g. because an agent died or never registered (0 = wait forever)")
+
+	pflag.Parse()
+
+	if err := utils.SetupConfigFile(); err != nil {
+		panic(fmt.Errorf("fatal error config file: %s", err))
+	}
+	if err := viper.Unmarshal(&conf); err != nil {
+		panic(fmt.Errorf("unable to decode config: %s", err))
+	}
+	if conf.Agents <= 0 {
+		log.Fatal("agents must be at least 1")
+	}
+}
+
+func main() {
+	l, err := net.Listen("tcp", conf.Listen)
+	if err != nil {
+		log.Fatal(err)
+	}
+	c := coordinator.New(conf.Agents)
+	if err := c.Serve(l); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("waiting for %d agents on %s\n", conf.Agents, l.Addr())
+
+	kind, results, err := c.Wait(conf.Timeout)
+	if err != nil {
+		log.Fatal(err)
+	}
+	combined := coordinator.Combine(kind, results)
+	if err := combined.WriteSummary(os.Stdout); err != nil {
+		log.Fatal(err)
+	}
+
+	if len(conf.ResultsFile) > 0 {
+		fmt.Printf("Saving results json file to %s\n", conf.ResultsFile)
+		file, err := json.MarshalIndent(combined, "", " ")
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := ioutil.WriteFile(conf.ResultsFile, file, 0644); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
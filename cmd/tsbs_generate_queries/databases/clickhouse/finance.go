@@ -0,0 +1,179 @@
+package clickhouse
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/finance"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+// Finance produces ClickHouse-specific queries for all the finance query types.
+// The symbols are always looked up in the tags table, as only the first tag
+// can be stored in the tables of the measurements.
+type Finance struct {
+	*BaseGenerator
+	*finance.Core
+}
+
+// getSymbolsWhereString gets nSymbols random symbols and creates a WHERE SQL
+// statement for them.
+func (f *Finance) getSymbolsWhereString(nSymbols int) string {
+	symbols, err := f.GetRandomSymbols(nSymbols)
+	panicIfErr(err)
+	return fmt.Sprintf("tags_id IN (SELECT id FROM tags WHERE symbol IN ('%s'))", strings.Join(symbols, "','"))
+}
+
+// VWAP computes the volume weighted average price of nSymbols random symbols
+// in windows over a random period,
+// e.g. in pseudo-SQL:
+//
+// SELECT symbol, bucket, sum(price * size) / sum(size)
+// FROM trades
+// WHERE symbol IN ('$SYMBOL_1',...,'$SYMBOL_N')
+// AND time >= '$START' AND time < '$END'
+// GROUP BY symbol, bucket ORDER BY symbol, bucket
+//
+// Resultsets:
+// vwap-1
+// vwap-10
+func (f *Finance) VWAP(qi query.Query, nSymbols int) {
+	interval := f.Interval.MustRandWindow(finance.VWAPDuration)
+
+	sql := fmt.Sprintf(`
+        SELECT
+            t.symbol AS symbol,
+            v.bucket,
+            v.vwap,
+            v.volume
+        FROM
+        (
+            SELECT
+                tags_id,
+                toStartOfInterval(created_at, INTERVAL %d minute) AS bucket,
+                sum(price * size) / sum(size) AS vwap,
+                sum(size) AS volume
+            FROM trades
+            WHERE %s AND (created_at >= '%s') AND (created_at < '%s')
+            GROUP BY
+                tags_id,
+                bucket
+        ) AS v
+        ANY INNER JOIN tags AS t ON v.tags_id = t.id
+        ORDER BY
+            symbol ASC,
+            bucket ASC
+        `,
+		int(finance.VWAPWindow.Minutes()),
+		f.getSymbolsWhereString(nSymbols),
+		interval.Start().Format(clickhouseTimeStringFormat),
+		interval.End().Format(clickhouseTimeStringFormat))
+
+	humanLabel := fmt.Sprintf("ClickHouse VWAP per %s, random %4d symbols, random %s", finance.VWAPWindow, nSymbols, finance.VWAPDuration)
+	humanDesc := fmt.Sprintf("%s: %s", humanLabel, interval.StartString())
+	f.fillInQuery(qi, humanLabel, humanDesc, finance.TradesTableName, sql)
+}
+
+// LastQuotePerSymbol finds the last quote of all the symbols of a random exchange.
+//
+// Resultsets:
+// last-quote
+func (f *Finance) LastQuotePerSymbol(qi query.Query) {
+	exchange := f.GetRandomExchange()
+
+	sql := fmt.Sprintf(`
+        SELECT
+            t.symbol AS symbol,
+            q.created_at,
+            q.bid_price,
+            q.bid_size,
+            q.ask_price,
+            q.ask_size
+        FROM
+        (
+            SELECT
+                tags_id,
+                max(created_at) AS created_at,
+                argMax(bid_price, created_at) AS bid_price,
+                argMax(bid_size, created_at) AS bid_size,
+                argMax(ask_price, created_at) AS ask_price,
+                argMax(ask_size, created_at) AS ask_size
+            FROM quotes
+            WHERE tags_id IN (SELECT id FROM tags WHERE exchange = '%s')
+            GROUP BY tags_id
+        ) AS q
+        ANY INNER JOIN tags AS t ON q.tags_id = t.id
+        ORDER BY symbol ASC
+        `,
+		exchange)
+
+	humanLabel := "ClickHouse last quote per symbol"
+	humanDesc := fmt.Sprintf("%s: %s", humanLabel, exchange)
+	f.fillInQuery(qi, humanLabel, humanDesc, finance.QuotesTableName, sql)
+}
+
+// TopMovers finds the symbols of which the price changed the most, up or
+// down, over a random day.
+//
+// Resultsets:
+// top-movers
+func (f *Finance) TopMovers(qi query.Query) {
+	interval := f.Interval.MustRandWindow(finance.TopMoversDuration)
+
+	sql := fmt.Sprintf(`
+        SELECT
+            t.symbol AS symbol,
+            b.first_open,
+            b.last_close,
+            (b.last_close - b.first_open) / b.first_open AS change
+        FROM
+        (
+            SELECT
+                tags_id,
+                argMin(open, created_at) AS first_open,
+                argMax(close, created_at) AS last_close
+            FROM bars
+            WHERE (created_at >= '%s') AND (created_at < '%s')
+            GROUP BY tags_id
+        ) AS b
+        ANY INNER JOIN tags AS t ON b.tags_id = t.id
+        ORDER BY abs(change) DESC
+        LIMIT %d
+        `,
+		interval.Start().Format(clickhouseTimeStringFormat),
+		interval.End().Format(clickhouseTimeStringFormat),
+		finance.TopMoversLimit)
+
+	humanLabel := fmt.Sprintf("ClickHouse top %d movers, random %s", finance.TopMoversLimit, finance.TopMoversDuration)
+	humanDesc := fmt.Sprintf("%s: %s", humanLabel, interval.StartString())
+	f.fillInQuery(qi, humanLabel, humanDesc, finance.BarsTableName, sql)
+}
+
+// AsOfJoin joins the trades of a random symbol over a random period with the
+// quote prevailing at the time of each trade.
+//
+// Resultsets:
+// asof-join
+func (f *Finance) AsOfJoin(qi query.Query) {
+	interval := f.Interval.MustRandWindow(finance.AsOfJoinDuration)
+
+	sql := fmt.Sprintf(`
+        SELECT
+            tr.created_at,
+            tr.price,
+            tr.size,
+            q.bid_price,
+            q.ask_price
+        FROM trades AS tr
+        ASOF LEFT JOIN quotes AS q ON (tr.tags_id = q.tags_id) AND (tr.created_at >= q.created_at)
+        WHERE tr.%s AND (tr.created_at >= '%s') AND (tr.created_at < '%s')
+        ORDER BY tr.created_at ASC
+        `,
+		f.getSymbolsWhereString(1),
+		interval.Start().Format(clickhouseTimeStringFormat),
+		interval.End().Format(clickhouseTimeStringFormat))
+
+	humanLabel := fmt.Sprintf("ClickHouse trades as-of joined with quotes, random %4d symbols, random %s", 1, finance.AsOfJoinDuration)
+	humanDesc := fmt.Sprintf("%s: %s", humanLabel, interval.StartString())
+	f.fillInQuery(qi, humanLabel, humanDesc, finance.TradesTableName, sql)
+}
@@ -0,0 +1,143 @@
+package clickhouse
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func newTestFinance(t *testing.T) *Finance {
+	rand.Seed(123) // Setting seed for testing purposes.
+	s := time.Unix(0, 0)
+	e := s.Add(48 * time.Hour)
+	b := BaseGenerator{}
+	fq, err := b.NewFinance(s, e, 10)
+	if err != nil {
+		t.Fatalf("Error while creating finance generator")
+	}
+	return fq.(*Finance)
+}
+
+func TestFinanceVWAP(t *testing.T) {
+	expectedHumanLabel := "ClickHouse VWAP per 5m0s, random    2 symbols, random 1h0m0s"
+	expectedHumanDesc := "ClickHouse VWAP per 5m0s, random    2 symbols, random 1h0m0s: 1970-01-02T02:16:22Z"
+	expectedQuery := `
+        SELECT
+            t.symbol AS symbol,
+            v.bucket,
+            v.vwap,
+            v.volume
+        FROM
+        (
+            SELECT
+                tags_id,
+                toStartOfInterval(created_at, INTERVAL 5 minute) AS bucket,
+                sum(price * size) / sum(size) AS vwap,
+                sum(size) AS volume
+            FROM trades
+            WHERE tags_id IN (SELECT id FROM tags WHERE symbol IN ('sym_9','sym_3')) AND (created_at >= '1970-01-02 02:16:22') AND (created_at < '1970-01-02 03:16:22')
+            GROUP BY
+                tags_id,
+                bucket
+        ) AS v
+        ANY INNER JOIN tags AS t ON v.tags_id = t.id
+        ORDER BY
+            symbol ASC,
+            bucket ASC
+        `
+
+	f := newTestFinance(t)
+	q := f.GenerateEmptyQuery()
+	f.VWAP(q, 2)
+
+	verifyQuery(t, q, expectedHumanLabel, expectedHumanDesc, expectedQuery)
+}
+
+func TestFinanceLastQuotePerSymbol(t *testing.T) {
+	expectedHumanLabel := "ClickHouse last quote per symbol"
+	expectedHumanDesc := "ClickHouse last quote per symbol: NYSE"
+	expectedQuery := `
+        SELECT
+            t.symbol AS symbol,
+            q.created_at,
+            q.bid_price,
+            q.bid_size,
+            q.ask_price,
+            q.ask_size
+        FROM
+        (
+            SELECT
+                tags_id,
+                max(created_at) AS created_at,
+                argMax(bid_price, created_at) AS bid_price,
+                argMax(bid_size, created_at) AS bid_size,
+                argMax(ask_price, created_at) AS ask_price,
+                argMax(ask_size, created_at) AS ask_size
+            FROM quotes
+            WHERE tags_id IN (SELECT id FROM tags WHERE exchange = 'NYSE')
+            GROUP BY tags_id
+        ) AS q
+        ANY INNER JOIN tags AS t ON q.tags_id = t.id
+        ORDER BY symbol ASC
+        `
+
+	f := newTestFinance(t)
+	q := f.GenerateEmptyQuery()
+	f.LastQuotePerSymbol(q)
+
+	verifyQuery(t, q, expectedHumanLabel, expectedHumanDesc, expectedQuery)
+}
+
+func TestFinanceTopMovers(t *testing.T) {
+	expectedHumanLabel := "ClickHouse top 10 movers, random 24h0m0s"
+	expectedHumanDesc := "ClickHouse top 10 movers, random 24h0m0s: 1970-01-01T18:16:22Z"
+	expectedQuery := `
+        SELECT
+            t.symbol AS symbol,
+            b.first_open,
+            b.last_close,
+            (b.last_close - b.first_open) / b.first_open AS change
+        FROM
+        (
+            SELECT
+                tags_id,
+                argMin(open, created_at) AS first_open,
+                argMax(close, created_at) AS last_close
+            FROM bars
+            WHERE (created_at >= '1970-01-01 18:16:22') AND (created_at < '1970-01-02 18:16:22')
+            GROUP BY tags_id
+        ) AS b
+        ANY INNER JOIN tags AS t ON b.tags_id = t.id
+        ORDER BY abs(change) DESC
+        LIMIT 10
+        `
+
+	f := newTestFinance(t)
+	q := f.GenerateEmptyQuery()
+	f.TopMovers(q)
+
+	verifyQuery(t, q, expectedHumanLabel, expectedHumanDesc, expectedQuery)
+}
+
+func TestFinanceAsOfJoin(t *testing.T) {
+	expectedHumanLabel := "ClickHouse trades as-of joined with quotes, random    1 symbols, random 1h0m0s"
+	expectedHumanDesc := "ClickHouse trades as-of joined with quotes, random    1 symbols, random 1h0m0s: 1970-01-02T02:16:22Z"
+	expectedQuery := `
+        SELECT
+            tr.created_at,
+            tr.price,
+            tr.size,
+            q.bid_price,
+            q.ask_price
+        FROM trades AS tr
+        ASOF LEFT JOIN quotes AS q ON (tr.tags_id = q.tags_id) AND (tr.created_at >= q.created_at)
+        WHERE tr.tags_id IN (SELECT id FROM tags WHERE symbol IN ('sym_9')) AND (tr.created_at >= '1970-01-02 02:16:22') AND (tr.created_at < '1970-01-02 03:16:22')
+        ORDER BY tr.created_at ASC
+        `
+
+	f := newTestFinance(t)
+	q := f.GenerateEmptyQuery()
+	f.AsOfJoin(q)
+
+	verifyQuery(t, q, expectedHumanLabel, expectedHumanDesc, expectedQuery)
+}
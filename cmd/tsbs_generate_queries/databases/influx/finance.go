@@ -0,0 +1,103 @@
+package influx
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/databases"
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/finance"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+// Finance produces Influx-specific queries for the finance query types. The
+// as-of join of trades and quotes is not supported, as InfluxQL has no joins.
+type Finance struct {
+	*finance.Core
+	*BaseGenerator
+}
+
+// NewFinance makes a Finance object ready to generate Queries.
+func NewFinance(start, end time.Time, scale int, g *BaseGenerator) *Finance {
+	c, err := finance.NewCore(start, end, scale)
+	databases.PanicIfErr(err)
+	return &Finance{
+		Core:          c,
+		BaseGenerator: g,
+	}
+}
+
+func (f *Finance) getSymbolWhereString(nSymbols int) string {
+	symbols, err := f.GetRandomSymbols(nSymbols)
+	databases.PanicIfErr(err)
+
+	symbolClauses := []string{}
+	for _, s := range symbols {
+		symbolClauses = append(symbolClauses, fmt.Sprintf("\"symbol\" = '%s'", s))
+	}
+	return "(" + strings.Join(symbolClauses, " or ") + ")"
+}
+
+// VWAP computes the volume weighted average price of nSymbols random symbols
+// in windows over a random period. The notional value of the trades is
+// computed in a subquery, as InfluxQL has no math within aggregations.
+func (f *Finance) VWAP(qi query.Query, nSymbols int) {
+	interval := f.Interval.MustRandWindow(finance.VWAPDuration)
+	influxql := fmt.Sprintf(`SELECT sum("notional") / sum("size") AS "vwap", sum("size") AS "volume"
+		FROM (
+			SELECT "price" * "size" AS "notional", "size"
+			FROM "trades"
+			WHERE %s AND time >= '%s' AND time < '%s'
+			GROUP BY "symbol")
+		WHERE time >= '%s' AND time < '%s'
+		GROUP BY time(%dm), "symbol"`,
+		f.getSymbolWhereString(nSymbols),
+		interval.StartString(),
+		interval.EndString(),
+		interval.StartString(),
+		interval.EndString(),
+		int(finance.VWAPWindow.Minutes()))
+
+	humanLabel := fmt.Sprintf("Influx VWAP per %s, random %4d symbols, random %s", finance.VWAPWindow, nSymbols, finance.VWAPDuration)
+	humanDesc := fmt.Sprintf("%s: %s", humanLabel, interval.StartString())
+
+	f.fillInQuery(qi, humanLabel, humanDesc, influxql)
+}
+
+// LastQuotePerSymbol finds the last quote of all the symbols of a random exchange.
+func (f *Finance) LastQuotePerSymbol(qi query.Query) {
+	exchange := f.GetRandomExchange()
+	influxql := fmt.Sprintf(`SELECT "bid_price", "bid_size", "ask_price", "ask_size"
+		FROM "quotes"
+		WHERE "exchange"='%s'
+		GROUP BY "symbol"
+		ORDER BY "time" DESC
+		LIMIT 1`,
+		exchange)
+
+	humanLabel := "Influx last quote per symbol"
+	humanDesc := fmt.Sprintf("%s: %s", humanLabel, exchange)
+
+	f.fillInQuery(qi, humanLabel, humanDesc, influxql)
+}
+
+// TopMovers finds the symbols of which the price changed the most, up or
+// down, over a random day.
+func (f *Finance) TopMovers(qi query.Query) {
+	interval := f.Interval.MustRandWindow(finance.TopMoversDuration)
+	influxql := fmt.Sprintf(`SELECT top("move", "symbol", %d), "change"
+		FROM (
+			SELECT (last("close") - first("open")) / first("open") AS "change",
+				abs((last("close") - first("open")) / first("open")) AS "move"
+			FROM "bars"
+			WHERE time >= '%s' AND time < '%s'
+			GROUP BY "symbol")`,
+		finance.TopMoversLimit,
+		interval.StartString(),
+		interval.EndString())
+
+	humanLabel := fmt.Sprintf("Influx top %d movers, random %s", finance.TopMoversLimit, finance.TopMoversDuration)
+	humanDesc := fmt.Sprintf("%s: %s", humanLabel, interval.StartString())
+
+	f.fillInQuery(qi, humanLabel, humanDesc, influxql)
+}
@@ -0,0 +1,87 @@
+package influx
+
+import (
+	"fmt"
+	"math/rand"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func newTestFinance(t *testing.T) *Finance {
+	rand.Seed(123) // Setting seed for testing purposes.
+	s := time.Unix(0, 0)
+	e := s.Add(48 * time.Hour)
+	b := BaseGenerator{}
+	fq, err := b.NewFinance(s, e, 10)
+	if err != nil {
+		t.Fatalf("Error while creating finance generator")
+	}
+	return fq.(*Finance)
+}
+
+func TestFinanceVWAP(t *testing.T) {
+	expectedHumanLabel := "Influx VWAP per 5m0s, random    2 symbols, random 1h0m0s"
+	expectedHumanDesc := "Influx VWAP per 5m0s, random    2 symbols, random 1h0m0s: 1970-01-02T02:16:22Z"
+	expectedQuery := `SELECT sum("notional") / sum("size") AS "vwap", sum("size") AS "volume"
+		FROM (
+			SELECT "price" * "size" AS "notional", "size"
+			FROM "trades"
+			WHERE ("symbol" = 'sym_9' or "symbol" = 'sym_3') AND time >= '1970-01-02T02:16:22Z' AND time < '1970-01-02T03:16:22Z'
+			GROUP BY "symbol")
+		WHERE time >= '1970-01-02T02:16:22Z' AND time < '1970-01-02T03:16:22Z'
+		GROUP BY time(5m), "symbol"`
+
+	f := newTestFinance(t)
+	q := f.GenerateEmptyQuery()
+	f.VWAP(q, 2)
+
+	v := url.Values{}
+	v.Set("q", expectedQuery)
+	expectedPath := fmt.Sprintf("/query?%s", v.Encode())
+
+	verifyQuery(t, q, expectedHumanLabel, expectedHumanDesc, expectedPath)
+}
+
+func TestFinanceLastQuotePerSymbol(t *testing.T) {
+	expectedHumanLabel := "Influx last quote per symbol"
+	expectedHumanDesc := "Influx last quote per symbol: NYSE"
+	expectedQuery := `SELECT "bid_price", "bid_size", "ask_price", "ask_size"
+		FROM "quotes"
+		WHERE "exchange"='NYSE'
+		GROUP BY "symbol"
+		ORDER BY "time" DESC
+		LIMIT 1`
+
+	f := newTestFinance(t)
+	q := f.GenerateEmptyQuery()
+	f.LastQuotePerSymbol(q)
+
+	v := url.Values{}
+	v.Set("q", expectedQuery)
+	expectedPath := fmt.Sprintf("/query?%s", v.Encode())
+
+	verifyQuery(t, q, expectedHumanLabel, expectedHumanDesc, expectedPath)
+}
+
+func TestFinanceTopMovers(t *testing.T) {
+	expectedHumanLabel := "Influx top 10 movers, random 24h0m0s"
+	expectedHumanDesc := "Influx top 10 movers, random 24h0m0s: 1970-01-01T18:16:22Z"
+	expectedQuery := `SELECT top("move", "symbol", 10), "change"
+		FROM (
+			SELECT (last("close") - first("open")) / first("open") AS "change",
+				abs((last("close") - first("open")) / first("open")) AS "move"
+			FROM "bars"
+			WHERE time >= '1970-01-01T18:16:22Z' AND time < '1970-01-02T18:16:22Z'
+			GROUP BY "symbol")`
+
+	f := newTestFinance(t)
+	q := f.GenerateEmptyQuery()
+	f.TopMovers(q)
+
+	v := url.Values{}
+	v.Set("q", expectedQuery)
+	expectedPath := fmt.Sprintf("/query?%s", v.Encode())
+
+	verifyQuery(t, q, expectedHumanLabel, expectedHumanDesc, expectedPath)
+}
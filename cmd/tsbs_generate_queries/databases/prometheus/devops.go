@@ -0,0 +1,153 @@
+package prometheus
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/devops"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+// metricLabel is the label that keeps the metric name in the results, since
+// functions like max_over_time drop the metric name of their series.
+const metricLabel = "metric"
+
+// Devops produces PromQL queries for all the devops query types.
+//
+// The queries follow the ones of the VictoriaMetrics generator. Unlike
+// MetricsQL, PromQL drops the metric name when applying functions such as
+// max_over_time, so queries over several metrics are built from a query per
+// metric that is labeled with the metric name and combined with 'or'.
+// Metric names are the field names, as written by tsbs_load_prometheus.
+type Devops struct {
+	*BaseGenerator
+	*devops.Core
+}
+
+// mustGetRandomHosts is the form of GetRandomHosts that cannot error; if it does error,
+// it causes a panic.
+func (d *Devops) mustGetRandomHosts(nHosts int) []string {
+	hosts, err := d.GetRandomHosts(nHosts)
+	if err != nil {
+		panic(err.Error())
+	}
+	return hosts
+}
+
+func (d *Devops) GroupByOrderByLimit(qi query.Query) {
+	panic("GroupByOrderByLimit not supported in PromQL")
+}
+
+func (d *Devops) LastPointPerHost(qq query.Query) {
+	panic("LastPointPerHost not supported in PromQL")
+}
+
+func (d *Devops) HighCPUForHosts(qi query.Query, nHosts int) {
+	panic("HighCPUForHosts not supported in PromQL")
+}
+
+// GroupByTime selects the MAX for numMetrics metrics under 'cpu'
+// per minute for nhosts hosts,
+// e.g. in pseudo-PromQL:
+//
+// label_replace(
+// 	max(max_over_time(metric1{hostname=~"hostname1|...|hostnameN"}[1m])),
+// 	"metric", "metric1", "", ""
+// ) or ... or label_replace(
+// 	max(max_over_time(metricN{hostname=~"hostname1|...|hostnameN"}[1m])),
+// 	"metric", "metricN", "", ""
+// )
+func (d *Devops) GroupByTime(qq query.Query, nHosts, numMetrics int, timeRange time.Duration) {
+	metrics := mustGetCPUMetricsSlice(numMetrics)
+	hosts := d.mustGetRandomHosts(nHosts)
+	qi := &queryInfo{
+		query:    perMetric(metrics, hosts, "max(max_over_time(%s[1m]))"),
+		label:    fmt.Sprintf("Prometheus %d cpu metric(s), random %4d hosts, random %s by 1m", numMetrics, nHosts, timeRange),
+		interval: d.MustRandWindow(timeRange),
+		step:     "60",
+	}
+	d.fillInQuery(qq, qi)
+}
+
+// GroupByTimeAndPrimaryTag selects the AVG of numMetrics metrics under 'cpu' per device per hour for a day,
+// e.g. in pseudo-PromQL:
+//
+// label_replace(
+// 	avg(avg_over_time(metric1[1h])) by (hostname),
+// 	"metric", "metric1", "", ""
+// ) or ... or label_replace(
+// 	avg(avg_over_time(metricN[1h])) by (hostname),
+// 	"metric", "metricN", "", ""
+// )
+//
+// Resultsets:
+// double-groupby-1
+// double-groupby-5
+// double-groupby-all
+func (d *Devops) GroupByTimeAndPrimaryTag(qq query.Query, numMetrics int) {
+	metrics := mustGetCPUMetricsSlice(numMetrics)
+	qi := &queryInfo{
+		query:    perMetric(metrics, nil, "avg(avg_over_time(%s[1h])) by (hostname)"),
+		label:    devops.GetDoubleGroupByLabel("Prometheus", numMetrics),
+		interval: d.MustRandWindow(devops.DoubleGroupByDuration),
+		step:     "3600",
+	}
+	d.fillInQuery(qq, qi)
+}
+
+// MaxAllCPU selects the MAX of all metrics under 'cpu' per hour for nhosts hosts,
+// e.g. in pseudo-PromQL:
+//
+// label_replace(
+// 	max(max_over_time(metric1{hostname=~"hostname1|...|hostnameN"}[1h])),
+// 	"metric", "metric1", "", ""
+// ) or ... for all the cpu metrics
+func (d *Devops) MaxAllCPU(qq query.Query, nHosts int, duration time.Duration) {
+	hosts := d.mustGetRandomHosts(nHosts)
+	qi := &queryInfo{
+		query:    perMetric(devops.GetAllCPUMetrics(), hosts, "max(max_over_time(%s[1h]))"),
+		label:    devops.GetMaxAllLabel("Prometheus", nHosts),
+		interval: d.MustRandWindow(duration),
+		step:     "3600",
+	}
+	d.fillInQuery(qq, qi)
+}
+
+// perMetric applies the aggregation format to the selector of every metric
+// and combines the results. With a single metric no label is added.
+func perMetric(metrics, hosts []string, format string) string {
+	if len(metrics) == 0 {
+		panic("BUG: must be at least one metric name in clause")
+	}
+	if len(metrics) == 1 {
+		return fmt.Sprintf(format, getSelector(metrics[0], hosts))
+	}
+
+	clauses := make([]string, len(metrics))
+	for i, m := range metrics {
+		clauses[i] = fmt.Sprintf("label_replace(%s, '%s', '%s', '', '')",
+			fmt.Sprintf(format, getSelector(m, hosts)), metricLabel, m)
+	}
+	return strings.Join(clauses, " or ")
+}
+
+func getSelector(metric string, hosts []string) string {
+	if len(hosts) == 0 {
+		return metric
+	}
+	if len(hosts) == 1 {
+		return fmt.Sprintf("%s{hostname='%s'}", metric, hosts[0])
+	}
+	return fmt.Sprintf("%s{hostname=~'%s'}", metric, strings.Join(hosts, "|"))
+}
+
+// mustGetCPUMetricsSlice is the form of GetCPUMetricsSlice that cannot error; if it does error,
+// it causes a panic.
+func mustGetCPUMetricsSlice(numMetrics int) []string {
+	metrics, err := devops.GetCPUMetricsSlice(numMetrics)
+	if err != nil {
+		panic(err.Error())
+	}
+	return metrics
+}
@@ -0,0 +1,141 @@
+package prometheus
+
+import (
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/devops"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+func TestDevopsQueries(t *testing.T) {
+	testCases := map[string]struct {
+		fn        func(g *Devops, q *query.HTTP)
+		expQuery  string
+		expStep   string
+		expToFail bool
+	}{
+		"GroupByTime_1_1": {
+			fn: func(g *Devops, q *query.HTTP) {
+				g.GroupByTime(q, 1, 1, time.Hour)
+			},
+			expQuery: "max(max_over_time(usage_user{hostname='host_5'}[1m]))",
+			expStep:  "60",
+		},
+		"GroupByTime_5_1": {
+			fn: func(g *Devops, q *query.HTTP) {
+				g.GroupByTime(q, 5, 1, time.Hour)
+			},
+			expQuery: "max(max_over_time(usage_user{hostname=~'host_5|host_9|host_3|host_1|host_7'}[1m]))",
+			expStep:  "60",
+		},
+		"GroupByTime_5_2": {
+			fn: func(g *Devops, q *query.HTTP) {
+				g.GroupByTime(q, 5, 2, time.Hour)
+			},
+			expQuery: "label_replace(max(max_over_time(usage_user{hostname=~'host_5|host_9|host_3|host_1|host_7'}[1m])), 'metric', 'usage_user', '', '')" +
+				" or label_replace(max(max_over_time(usage_system{hostname=~'host_5|host_9|host_3|host_1|host_7'}[1m])), 'metric', 'usage_system', '', '')",
+			expStep: "60",
+		},
+		"GroupByTimeAndPrimaryTag": {
+			fn: func(g *Devops, q *query.HTTP) {
+				g.GroupByTimeAndPrimaryTag(q, 2)
+			},
+			expQuery: "label_replace(avg(avg_over_time(usage_user[1h])) by (hostname), 'metric', 'usage_user', '', '')" +
+				" or label_replace(avg(avg_over_time(usage_system[1h])) by (hostname), 'metric', 'usage_system', '', '')",
+			expStep: "3600",
+		},
+		"MaxAllCPU": {
+			fn: func(g *Devops, q *query.HTTP) {
+				g.MaxAllCPU(q, 1, devops.MaxAllDuration)
+			},
+			expQuery: buildMaxAll("host_5"),
+			expStep:  "3600",
+		},
+		"GroupByOrderByLimit": {
+			fn: func(g *Devops, q *query.HTTP) {
+				g.GroupByOrderByLimit(q)
+			},
+			expToFail: true,
+		},
+		"LastPointPerHost": {
+			fn: func(g *Devops, q *query.HTTP) {
+				g.LastPointPerHost(q)
+			},
+			expToFail: true,
+		},
+		"HighCPUForHosts": {
+			fn: func(g *Devops, q *query.HTTP) {
+				g.HighCPUForHosts(q, 6)
+			},
+			expToFail: true,
+		},
+		"GroupByTime_negative_metrics": {
+			fn: func(g *Devops, q *query.HTTP) {
+				g.GroupByTime(q, 1, -1, time.Hour)
+			},
+			expToFail: true,
+		},
+	}
+	g := acquireGenerator(t, time.Hour*24, 10)
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			rand.Seed(123) // Setting seed for testing purposes.
+			q := g.GenerateEmptyQuery().(*query.HTTP)
+			if tc.expToFail {
+				func() {
+					defer func() {
+						if recover() == nil {
+							t.Errorf("expected to panic")
+						}
+					}()
+					tc.fn(g, q)
+				}()
+				return
+			}
+
+			tc.fn(g, q)
+			u, err := url.Parse(string(q.Path))
+			if err != nil {
+				t.Fatalf("unexpected err while parsing query: %s", err)
+			}
+			vals := u.Query()
+			checkEqual(t, "path", "/api/v1/query_range", u.Path)
+			checkEqual(t, "query", tc.expQuery, vals.Get("query"))
+			checkEqual(t, "step", tc.expStep, vals.Get("step"))
+			checkEqual(t, "method", http.MethodGet, string(q.Method))
+			if !strings.HasPrefix(string(q.HumanLabel), "Prometheus ") {
+				t.Errorf("unexpected label: %s", q.HumanLabel)
+			}
+		})
+	}
+}
+
+func buildMaxAll(host string) string {
+	var clauses []string
+	for _, m := range devops.GetAllCPUMetrics() {
+		clauses = append(clauses, "label_replace(max(max_over_time("+m+"{hostname='"+host+"'}[1h])), 'metric', '"+m+"', '', '')")
+	}
+	return strings.Join(clauses, " or ")
+}
+
+func checkEqual(t *testing.T, name, a, b string) {
+	if a != b {
+		t.Fatalf("values for %q are not equal \na: %q \nb: %q", name, a, b)
+	}
+}
+
+func acquireGenerator(t *testing.T, interval time.Duration, scale int) *Devops {
+	b := &BaseGenerator{}
+	s := time.Unix(0, 0)
+	e := s.Add(interval)
+	g, err := b.NewDevops(s, e, scale)
+	if err != nil {
+		t.Fatalf("Error while creating devops generator")
+	}
+	return g.(*Devops)
+}
@@ -0,0 +1,123 @@
+package questdb
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/finance"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+// Finance produces QuestDB-specific queries for all the finance query types.
+type Finance struct {
+	*BaseGenerator
+	*finance.Core
+}
+
+// VWAP computes the volume weighted average price of nSymbols random symbols
+// in windows over a random period
+//
+// Queries:
+// vwap-1
+// vwap-10
+func (f *Finance) VWAP(qi query.Query, nSymbols int) {
+	interval := f.Interval.MustRandWindow(finance.VWAPDuration)
+	symbols, err := f.GetRandomSymbols(nSymbols)
+	panicIfErr(err)
+
+	sql := fmt.Sprintf(`
+		SELECT timestamp, symbol,
+			sum(price * size) / sum(size) AS vwap,
+			sum(size) AS volume
+		FROM trades
+		WHERE symbol IN ('%s')
+		  AND timestamp >= '%s'
+		  AND timestamp < '%s'
+		SAMPLE BY %dm`,
+		strings.Join(symbols, "', '"),
+		interval.StartString(),
+		interval.EndString(),
+		int(finance.VWAPWindow.Minutes()))
+
+	humanLabel := fmt.Sprintf("QuestDB VWAP per %s, random %4d symbols, random %s", finance.VWAPWindow, nSymbols, finance.VWAPDuration)
+	humanDesc := fmt.Sprintf("%s: %s", humanLabel, interval.StartString())
+	f.fillInQuery(qi, humanLabel, humanDesc, sql)
+}
+
+// LastQuotePerSymbol finds the last quote of all the symbols of a random
+// exchange
+//
+// Queries:
+// last-quote
+func (f *Finance) LastQuotePerSymbol(qi query.Query) {
+	exchange := f.GetRandomExchange()
+
+	sql := fmt.Sprintf(`
+		SELECT * FROM quotes
+		latest by symbol
+		WHERE exchange = '%s'`,
+		exchange)
+
+	humanLabel := "QuestDB last quote per symbol"
+	humanDesc := fmt.Sprintf("%s: %s", humanLabel, exchange)
+	f.fillInQuery(qi, humanLabel, humanDesc, sql)
+}
+
+// TopMovers finds the symbols of which the price changed the most, up or
+// down, over a random day
+//
+// Queries:
+// top-movers
+func (f *Finance) TopMovers(qi query.Query) {
+	interval := f.Interval.MustRandWindow(finance.TopMoversDuration)
+
+	sql := fmt.Sprintf(`
+		SELECT symbol, first_open, last_close,
+			(last_close - first_open) / first_open AS change,
+			abs((last_close - first_open) / first_open) AS move
+		FROM (
+			SELECT symbol,
+				first(open) AS first_open,
+				last(close) AS last_close
+			FROM bars
+			WHERE timestamp >= '%s'
+			  AND timestamp < '%s'
+		)
+		ORDER BY move DESC
+		LIMIT %d`,
+		interval.StartString(),
+		interval.EndString(),
+		finance.TopMoversLimit)
+
+	humanLabel := fmt.Sprintf("QuestDB top %d movers, random %s", finance.TopMoversLimit, finance.TopMoversDuration)
+	humanDesc := fmt.Sprintf("%s: %s", humanLabel, interval.StartString())
+	f.fillInQuery(qi, humanLabel, humanDesc, sql)
+}
+
+// AsOfJoin joins the trades of a random symbol over a random period with the
+// quote prevailing at the time of each trade
+//
+// Queries:
+// asof-join
+func (f *Finance) AsOfJoin(qi query.Query) {
+	interval := f.Interval.MustRandWindow(finance.AsOfJoinDuration)
+	symbols, err := f.GetRandomSymbols(1)
+	panicIfErr(err)
+
+	sql := fmt.Sprintf(`
+		SELECT t.timestamp, t.price, t.size, q.bid_price, q.ask_price
+		FROM (
+			SELECT * FROM trades
+			WHERE symbol = '%s'
+			  AND timestamp >= '%s'
+			  AND timestamp < '%s'
+		) t
+		ASOF JOIN quotes q ON (symbol)`,
+		symbols[0],
+		interval.StartString(),
+		interval.EndString())
+
+	humanLabel := fmt.Sprintf("QuestDB trades as-of joined with quotes, random %4d symbols, random %s", 1, finance.AsOfJoinDuration)
+	humanDesc := fmt.Sprintf("%s: %s", humanLabel, interval.StartString())
+	f.fillInQuery(qi, humanLabel, humanDesc, sql)
+}
@@ -0,0 +1,67 @@
+package questdb
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func newTestFinance(t *testing.T) *Finance {
+	rand.Seed(123) // Setting seed for testing purposes.
+	s := time.Unix(0, 0)
+	e := s.Add(48 * time.Hour)
+	b := BaseGenerator{}
+	fq, err := b.NewFinance(s, e, 10)
+	if err != nil {
+		t.Fatalf("Error while creating finance generator")
+	}
+	return fq.(*Finance)
+}
+
+func TestFinanceVWAP(t *testing.T) {
+	expectedHumanLabel := "QuestDB VWAP per 5m0s, random    2 symbols, random 1h0m0s"
+	expectedHumanDesc := "QuestDB VWAP per 5m0s, random    2 symbols, random 1h0m0s: 1970-01-02T02:16:22Z"
+	expectedQuery := "SELECT timestamp, symbol, sum(price * size) / sum(size) AS vwap, sum(size) AS volume FROM trades WHERE symbol IN ('sym_9', 'sym_3') AND timestamp >= '1970-01-02T02:16:22Z' AND timestamp < '1970-01-02T03:16:22Z' SAMPLE BY 5m"
+
+	f := newTestFinance(t)
+	q := f.GenerateEmptyQuery()
+	f.VWAP(q, 2)
+
+	verifyQuery(t, q, expectedHumanLabel, expectedHumanDesc, expectedQuery)
+}
+
+func TestFinanceLastQuotePerSymbol(t *testing.T) {
+	expectedHumanLabel := "QuestDB last quote per symbol"
+	expectedHumanDesc := "QuestDB last quote per symbol: NYSE"
+	expectedQuery := "SELECT * FROM quotes latest by symbol WHERE exchange = 'NYSE'"
+
+	f := newTestFinance(t)
+	q := f.GenerateEmptyQuery()
+	f.LastQuotePerSymbol(q)
+
+	verifyQuery(t, q, expectedHumanLabel, expectedHumanDesc, expectedQuery)
+}
+
+func TestFinanceTopMovers(t *testing.T) {
+	expectedHumanLabel := "QuestDB top 10 movers, random 24h0m0s"
+	expectedHumanDesc := "QuestDB top 10 movers, random 24h0m0s: 1970-01-01T18:16:22Z"
+	expectedQuery := "SELECT symbol, first_open, last_close, (last_close - first_open) / first_open AS change, abs((last_close - first_open) / first_open) AS move FROM ( SELECT symbol, first(open) AS first_open, last(close) AS last_close FROM bars WHERE timestamp >= '1970-01-01T18:16:22Z' AND timestamp < '1970-01-02T18:16:22Z' ) ORDER BY move DESC LIMIT 10"
+
+	f := newTestFinance(t)
+	q := f.GenerateEmptyQuery()
+	f.TopMovers(q)
+
+	verifyQuery(t, q, expectedHumanLabel, expectedHumanDesc, expectedQuery)
+}
+
+func TestFinanceAsOfJoin(t *testing.T) {
+	expectedHumanLabel := "QuestDB trades as-of joined with quotes, random    1 symbols, random 1h0m0s"
+	expectedHumanDesc := "QuestDB trades as-of joined with quotes, random    1 symbols, random 1h0m0s: 1970-01-02T02:16:22Z"
+	expectedQuery := "SELECT t.timestamp, t.price, t.size, q.bid_price, q.ask_price FROM ( SELECT * FROM trades WHERE symbol = 'sym_9' AND timestamp >= '1970-01-02T02:16:22Z' AND timestamp < '1970-01-02T03:16:22Z' ) t ASOF JOIN quotes q ON (symbol)"
+
+	f := newTestFinance(t)
+	q := f.GenerateEmptyQuery()
+	f.AsOfJoin(q)
+
+	verifyQuery(t, q, expectedHumanLabel, expectedHumanDesc, expectedQuery)
+}
@@ -0,0 +1,148 @@
+package timescaledb
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/finance"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+// Finance produces TimescaleDB-specific queries for all the finance query types.
+type Finance struct {
+	*finance.Core
+	*BaseGenerator
+}
+
+// NewFinance makes a Finance object ready to generate Queries.
+func NewFinance(start, end time.Time, scale int, g *BaseGenerator) *Finance {
+	c, err := finance.NewCore(start, end, scale)
+	panicIfErr(err)
+	return &Finance{
+		Core:          c,
+		BaseGenerator: g,
+	}
+}
+
+// columnSelect returns the column of a tag of the tags table t.
+func (f *Finance) columnSelect(column string) string {
+	if f.UseJSON {
+		return fmt.Sprintf("t.tagset->>'%s'", column)
+	}
+
+	return "t." + column
+}
+
+func (f *Finance) getTimeBucket(seconds int) string {
+	if f.UseTimeBucket {
+		return fmt.Sprintf(timeBucketFmt, seconds)
+	}
+	return fmt.Sprintf(nonTimeBucketFmt, seconds, seconds)
+}
+
+// getSymbolsWhereString gets nSymbols random symbols and creates a WHERE SQL
+// statement for them on the tags table t.
+func (f *Finance) getSymbolsWhereString(nSymbols int) string {
+	symbols, err := f.GetRandomSymbols(nSymbols)
+	panicIfErr(err)
+	return fmt.Sprintf("%s IN ('%s')", f.columnSelect("symbol"), strings.Join(symbols, "','"))
+}
+
+// VWAP computes the volume weighted average price of nSymbols random symbols
+// in windows over a random period,
+// e.g. in pseudo-SQL:
+//
+// SELECT symbol, bucket, sum(price * size) / sum(size)
+// FROM trades
+// WHERE symbol IN ('$SYMBOL_1',...,'$SYMBOL_N')
+// AND time >= '$START' AND time < '$END'
+// GROUP BY symbol, bucket ORDER BY symbol, bucket
+func (f *Finance) VWAP(qi query.Query, nSymbols int) {
+	interval := f.Interval.MustRandWindow(finance.VWAPDuration)
+	sql := fmt.Sprintf(`SELECT %s AS symbol, %s AS bucket,
+		sum(tr.price * tr.size) / sum(tr.size) AS vwap, sum(tr.size) AS volume
+		FROM trades tr INNER JOIN tags t ON tr.tags_id = t.id
+		WHERE %s AND tr.time >= '%s' AND tr.time < '%s'
+		GROUP BY 1, 2
+		ORDER BY 1, 2`,
+		f.columnSelect("symbol"),
+		f.getTimeBucket(int(finance.VWAPWindow.Seconds())),
+		f.getSymbolsWhereString(nSymbols),
+		interval.Start().Format(goTimeFmt),
+		interval.End().Format(goTimeFmt))
+
+	humanLabel := fmt.Sprintf("TimescaleDB VWAP per %s, random %4d symbols, random %s", finance.VWAPWindow, nSymbols, finance.VWAPDuration)
+	humanDesc := fmt.Sprintf("%s: %s", humanLabel, interval.StartString())
+
+	f.fillInQuery(qi, humanLabel, humanDesc, finance.TradesTableName, sql)
+}
+
+// LastQuotePerSymbol finds the last quote of all the symbols of a random exchange.
+func (f *Finance) LastQuotePerSymbol(qi query.Query) {
+	exchange := f.GetRandomExchange()
+	sql := fmt.Sprintf(`SELECT %s AS symbol, q.*
+		FROM tags t INNER JOIN LATERAL
+			(SELECT time, bid_price, bid_size, ask_price, ask_size
+			FROM quotes q
+			WHERE q.tags_id = t.id
+			ORDER BY time DESC LIMIT 1) q ON true
+		WHERE %s = '%s'`,
+		f.columnSelect("symbol"),
+		f.columnSelect("exchange"),
+		exchange)
+
+	humanLabel := "TimescaleDB last quote per symbol"
+	humanDesc := fmt.Sprintf("%s: %s", humanLabel, exchange)
+
+	f.fillInQuery(qi, humanLabel, humanDesc, finance.QuotesTableName, sql)
+}
+
+// TopMovers finds the symbols of which the price changed the most, up or
+// down, over a random day.
+func (f *Finance) TopMovers(qi query.Query) {
+	interval := f.Interval.MustRandWindow(finance.TopMoversDuration)
+	sql := fmt.Sprintf(`SELECT %s AS symbol, b.first_open, b.last_close,
+		(b.last_close - b.first_open) / b.first_open AS change
+		FROM
+			(SELECT tags_id, first(open, time) AS first_open, last(close, time) AS last_close
+			FROM bars
+			WHERE time >= '%s' AND time < '%s'
+			GROUP BY tags_id) b
+		INNER JOIN tags t ON b.tags_id = t.id
+		ORDER BY abs((b.last_close - b.first_open) / b.first_open) DESC
+		LIMIT %d`,
+		f.columnSelect("symbol"),
+		interval.Start().Format(goTimeFmt),
+		interval.End().Format(goTimeFmt),
+		finance.TopMoversLimit)
+
+	humanLabel := fmt.Sprintf("TimescaleDB top %d movers, random %s", finance.TopMoversLimit, finance.TopMoversDuration)
+	humanDesc := fmt.Sprintf("%s: %s", humanLabel, interval.StartString())
+
+	f.fillInQuery(qi, humanLabel, humanDesc, finance.BarsTableName, sql)
+}
+
+// AsOfJoin joins the trades of a random symbol over a random period with the
+// quote prevailing at the time of each trade.
+func (f *Finance) AsOfJoin(qi query.Query) {
+	interval := f.Interval.MustRandWindow(finance.AsOfJoinDuration)
+	sql := fmt.Sprintf(`SELECT tr.time, tr.price, tr.size, q.bid_price, q.ask_price
+		FROM trades tr
+		INNER JOIN tags t ON tr.tags_id = t.id
+		LEFT JOIN LATERAL
+			(SELECT bid_price, ask_price
+			FROM quotes q
+			WHERE q.tags_id = tr.tags_id AND q.time <= tr.time
+			ORDER BY q.time DESC LIMIT 1) q ON true
+		WHERE %s AND tr.time >= '%s' AND tr.time < '%s'
+		ORDER BY tr.time`,
+		f.getSymbolsWhereString(1),
+		interval.Start().Format(goTimeFmt),
+		interval.End().Format(goTimeFmt))
+
+	humanLabel := fmt.Sprintf("TimescaleDB trades as-of joined with quotes, random %4d symbols, random %s", 1, finance.AsOfJoinDuration)
+	humanDesc := fmt.Sprintf("%s: %s", humanLabel, interval.StartString())
+
+	f.fillInQuery(qi, humanLabel, humanDesc, finance.TradesTableName, sql)
+}
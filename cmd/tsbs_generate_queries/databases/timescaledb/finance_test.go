@@ -0,0 +1,98 @@
+package timescaledb
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/finance"
+)
+
+func newTestFinance(t *testing.T) *Finance {
+	rand.Seed(123) // Setting seed for testing purposes.
+	s := time.Unix(0, 0)
+	e := s.Add(48 * time.Hour)
+	b := BaseGenerator{}
+	fq, err := b.NewFinance(s, e, 10)
+	if err != nil {
+		t.Fatalf("Error while creating finance generator")
+	}
+	return fq.(*Finance)
+}
+
+func TestFinanceVWAP(t *testing.T) {
+	expectedHumanLabel := "TimescaleDB VWAP per 5m0s, random    2 symbols, random 1h0m0s"
+	expectedHumanDesc := "TimescaleDB VWAP per 5m0s, random    2 symbols, random 1h0m0s: 1970-01-02T02:16:22Z"
+	expectedQuery := `SELECT t.symbol AS symbol, to_timestamp(((extract(epoch from time)::int)/300)*300) AS bucket,
+		sum(tr.price * tr.size) / sum(tr.size) AS vwap, sum(tr.size) AS volume
+		FROM trades tr INNER JOIN tags t ON tr.tags_id = t.id
+		WHERE t.symbol IN ('sym_9','sym_3') AND tr.time >= '1970-01-02 02:16:22.646325 +0000' AND tr.time < '1970-01-02 03:16:22.646325 +0000'
+		GROUP BY 1, 2
+		ORDER BY 1, 2`
+
+	f := newTestFinance(t)
+	q := f.GenerateEmptyQuery()
+	f.VWAP(q, 2)
+
+	verifyQuery(t, q, expectedHumanLabel, expectedHumanDesc, finance.TradesTableName, expectedQuery)
+}
+
+func TestFinanceLastQuotePerSymbol(t *testing.T) {
+	expectedHumanLabel := "TimescaleDB last quote per symbol"
+	expectedHumanDesc := "TimescaleDB last quote per symbol: NYSE"
+	expectedQuery := `SELECT t.symbol AS symbol, q.*
+		FROM tags t INNER JOIN LATERAL
+			(SELECT time, bid_price, bid_size, ask_price, ask_size
+			FROM quotes q
+			WHERE q.tags_id = t.id
+			ORDER BY time DESC LIMIT 1) q ON true
+		WHERE t.exchange = 'NYSE'`
+
+	f := newTestFinance(t)
+	q := f.GenerateEmptyQuery()
+	f.LastQuotePerSymbol(q)
+
+	verifyQuery(t, q, expectedHumanLabel, expectedHumanDesc, finance.QuotesTableName, expectedQuery)
+}
+
+func TestFinanceTopMovers(t *testing.T) {
+	expectedHumanLabel := "TimescaleDB top 10 movers, random 24h0m0s"
+	expectedHumanDesc := "TimescaleDB top 10 movers, random 24h0m0s: 1970-01-01T18:16:22Z"
+	expectedQuery := `SELECT t.symbol AS symbol, b.first_open, b.last_close,
+		(b.last_close - b.first_open) / b.first_open AS change
+		FROM
+			(SELECT tags_id, first(open, time) AS first_open, last(close, time) AS last_close
+			FROM bars
+			WHERE time >= '1970-01-01 18:16:22.646325 +0000' AND time < '1970-01-02 18:16:22.646325 +0000'
+			GROUP BY tags_id) b
+		INNER JOIN tags t ON b.tags_id = t.id
+		ORDER BY abs((b.last_close - b.first_open) / b.first_open) DESC
+		LIMIT 10`
+
+	f := newTestFinance(t)
+	q := f.GenerateEmptyQuery()
+	f.TopMovers(q)
+
+	verifyQuery(t, q, expectedHumanLabel, expectedHumanDesc, finance.BarsTableName, expectedQuery)
+}
+
+func TestFinanceAsOfJoin(t *testing.T) {
+	expectedHumanLabel := "TimescaleDB trades as-of joined with quotes, random    1 symbols, random 1h0m0s"
+	expectedHumanDesc := "TimescaleDB trades as-of joined with quotes, random    1 symbols, random 1h0m0s: 1970-01-02T02:16:22Z"
+	expectedQuery := `SELECT tr.time, tr.price, tr.size, q.bid_price, q.ask_price
+		FROM trades tr
+		INNER JOIN tags t ON tr.tags_id = t.id
+		LEFT JOIN LATERAL
+			(SELECT bid_price, ask_price
+			FROM quotes q
+			WHERE q.tags_id = tr.tags_id AND q.time <= tr.time
+			ORDER BY q.time DESC LIMIT 1) q ON true
+		WHERE t.symbol IN ('sym_9') AND tr.time >= '1970-01-02 02:16:22.646325 +0000' AND tr.time < '1970-01-02 03:16:22.646325 +0000'
+		ORDER BY tr.time`
+
+	f := newTestFinance(t)
+	q := f.GenerateEmptyQuery()
+	f.AsOfJoin(q)
+
+	verifyQuery(t, q, expectedHumanLabel, expectedHumanDesc, finance.TradesTableName, expectedQuery)
+}
@@ -52,7 +52,7 @@ func (d *Devops) GroupByTime(qq query.Query, nHosts, numMetrics int, timeRange t
 	qi := &queryInfo{
 		query:    fmt.Sprintf("max(max_over_time(%s[1m])) by (__name__)", selectClause),
 		label:    fmt.Sprintf("VictoriaMetrics %d cpu metric(s), random %4d hosts, random %s by 1m", numMetrics, nHosts, timeRange),
-		interval: d.Interval.MustRandWindow(timeRange),
+		interval: d.MustRandWindow(timeRange),
 		step:     "60",
 	}
 	d.fillInQuery(qq, qi)
@@ -77,7 +77,7 @@ func (d *Devops) GroupByTimeAndPrimaryTag(qq query.Query, numMetrics int) {
 	qi := &queryInfo{
 		query:    fmt.Sprintf("avg(avg_over_time(%s[1h])) by (__name__, hostname)", selectClause),
 		label:    devops.GetDoubleGroupByLabel("VictoriaMetrics", numMetrics),
-		interval: d.Interval.MustRandWindow(devops.DoubleGroupByDuration),
+		interval: d.MustRandWindow(devops.DoubleGroupByDuration),
 		step:     "3600",
 	}
 	d.fillInQuery(qq, qi)
@@ -97,7 +97,7 @@ func (d *Devops) MaxAllCPU(qq query.Query, nHosts int, duration time.Duration) {
 	qi := &queryInfo{
 		query:    fmt.Sprintf("max(max_over_time(%s[1h])) by (__name__)", selectClause),
 		label:    devops.GetMaxAllLabel("VictoriaMetrics", nHosts),
-		interval: d.Interval.MustRandWindow(duration),
+		interval: d.MustRandWindow(duration),
 		step:     "3600",
 	}
 	d.fillInQuery(qq, qi)
@@ -11,6 +11,7 @@ import (
 	"github.com/blagojts/viper"
 	"github.com/spf13/pflag"
 	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/devops"
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/finance"
 	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/iot"
 	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/utils"
 	"github.com/timescale/tsbs/internal/inputs"
@@ -51,6 +52,13 @@ var useCaseMatrix = map[string]map[string]utils.QueryFillerMaker{
 		iot.LabelDailyActivity:                 iot.NewDailyTruckActivity,
 		iot.LabelBreakdownFrequency:            iot.NewTruckBreakdownFrequency,
 	},
+	"finance": {
+		finance.LabelVWAP + "-1":  finance.NewVWAP(1),
+		finance.LabelVWAP + "-10": finance.NewVWAP(10),
+		finance.LabelLastQuote:    finance.NewLastQuotePerSymbol,
+		finance.LabelTopMovers:    finance.NewTopMovers,
+		finance.LabelAsOfJoin:     finance.NewAsOfJoin,
+	},
 }
 
 var conf = &config.QueryGeneratorConfig{}
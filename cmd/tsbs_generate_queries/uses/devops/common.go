@@ -5,6 +5,8 @@ import (
 	"time"
 
 	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/common"
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/utils"
+	internalutils "github.com/timescale/tsbs/internal/utils"
 	"github.com/timescale/tsbs/pkg/query"
 )
 
@@ -38,9 +40,25 @@ const (
 	LabelHighCPU = "high-cpu"
 )
 
+// QueryParams describes a generated query independently of the database it
+// was generated for, so that its expected result can be computed in memory.
+type QueryParams struct {
+	// Kind is the label prefix of the query type, e.g. LabelSingleGroupby
+	Kind string
+	// Metrics are the CPU metrics the query aggregates or returns
+	Metrics []string
+	// Hosts are the hosts the query is restricted to, nil for all hosts
+	Hosts []string
+	// Interval is the random time window of the query, nil if it has none
+	Interval *internalutils.TimeInterval
+}
+
 // Core is the common component of all generators for all systems
 type Core struct {
 	*common.Core
+
+	// params of the query currently being filled in
+	params *QueryParams
 }
 
 // NewCore returns a new Core for the given time range and cardinality
@@ -52,7 +70,44 @@ func NewCore(start, end time.Time, scale int) (*Core, error) {
 
 // GetRandomHosts returns a random set of nHosts from a given Core
 func (d *Core) GetRandomHosts(nHosts int) ([]string, error) {
-	return getRandomHosts(nHosts, d.Scale)
+	hosts, err := getRandomHosts(nHosts, d.Scale)
+	if err == nil && d.params != nil {
+		d.params.Hosts = hosts
+	}
+	return hosts, err
+}
+
+// MustRandWindow returns a random window of the given duration within the
+// time range of the dataset, panicking if the window does not fit.
+func (d *Core) MustRandWindow(window time.Duration) *internalutils.TimeInterval {
+	interval := d.Interval.MustRandWindow(window)
+	if d.params != nil {
+		d.params.Interval = interval
+	}
+	return interval
+}
+
+// LastQueryParams returns the parameters of the last query filled in by
+// this Core, or nil if no query has been filled in.
+func (d *Core) LastQueryParams() *QueryParams {
+	return d.params
+}
+
+func (d *Core) startQuery(kind string, metrics []string) {
+	d.params = &QueryParams{Kind: kind, Metrics: metrics}
+}
+
+// queryStarter is implemented by every generator that embeds a Core
+type queryStarter interface {
+	startQuery(kind string, metrics []string)
+}
+
+// startQuery resets the recorded parameters of core, if it embeds a Core,
+// before a query of the given kind is filled in.
+func startQuery(core utils.QueryGenerator, kind string, metrics []string) {
+	if qs, ok := core.(queryStarter); ok {
+		qs.startQuery(kind, metrics)
+	}
 }
 
 // cpuMetrics is the list of metric names for CPU
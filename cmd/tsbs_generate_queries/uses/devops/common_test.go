@@ -68,6 +68,41 @@ func TestCoreGetRandomHosts(t *testing.T) {
 	}
 }
 
+func TestCoreLastQueryParams(t *testing.T) {
+	s := time.Now()
+	e := s.Add(24 * time.Hour)
+	c, err := NewCore(s, e, 10)
+	if err != nil {
+		t.Fatalf("unexpected error for NewCore: %v", err)
+	}
+	if c.LastQueryParams() != nil {
+		t.Fatalf("expected no params before any query")
+	}
+
+	c.startQuery(LabelSingleGroupby, cpuMetrics[:1])
+	hosts, err := c.GetRandomHosts(2)
+	if err != nil {
+		t.Fatalf("unexpected error for GetRandomHosts: %v", err)
+	}
+	interval := c.MustRandWindow(time.Hour)
+
+	p := c.LastQueryParams()
+	if p.Kind != LabelSingleGroupby || len(p.Metrics) != 1 || p.Metrics[0] != cpuMetrics[0] {
+		t.Errorf("incorrect kind or metrics recorded: %v", p)
+	}
+	if strings.Join(p.Hosts, ",") != strings.Join(hosts, ",") {
+		t.Errorf("incorrect hosts recorded: got %v want %v", p.Hosts, hosts)
+	}
+	if p.Interval != interval {
+		t.Errorf("incorrect interval recorded: got %v want %v", p.Interval, interval)
+	}
+
+	c.startQuery(LabelLastpoint, cpuMetrics)
+	if p := c.LastQueryParams(); p.Hosts != nil || p.Interval != nil {
+		t.Errorf("params not reset for new query: %v", p)
+	}
+}
+
 func TestGetCPUMetricsSlice(t *testing.T) {
 	cases := []struct {
 		desc      string
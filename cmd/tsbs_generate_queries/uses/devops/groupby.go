@@ -28,6 +28,8 @@ func (d *Groupby) Fill(q query.Query) query.Query {
 	if !ok {
 		common.PanicUnimplementedQuery(d.core)
 	}
+	metrics, _ := GetCPUMetricsSlice(d.numMetrics)
+	startQuery(d.core, LabelDoubleGroupby, metrics)
 	fc.GroupByTimeAndPrimaryTag(q, d.numMetrics)
 	return q
 }
@@ -22,6 +22,7 @@ func (d *GroupByOrderByLimit) Fill(q query.Query) query.Query {
 	if !ok {
 		common.PanicUnimplementedQuery(d.core)
 	}
+	startQuery(d.core, LabelGroupbyOrderbyLimit, cpuMetrics[:1])
 	fc.GroupByOrderByLimit(q)
 	return q
 }
@@ -28,6 +28,7 @@ func (d *HighCPU) Fill(q query.Query) query.Query {
 	if !ok {
 		common.PanicUnimplementedQuery(d.core)
 	}
+	startQuery(d.core, LabelHighCPU, cpuMetrics)
 	fc.HighCPUForHosts(q, d.hosts)
 	return q
 }
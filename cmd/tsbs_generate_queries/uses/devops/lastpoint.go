@@ -22,6 +22,7 @@ func (d *LastPointPerHost) Fill(q query.Query) query.Query {
 	if !ok {
 		common.PanicUnimplementedQuery(d.core)
 	}
+	startQuery(d.core, LabelLastpoint, cpuMetrics)
 	fc.LastPointPerHost(q)
 	return q
 }
@@ -32,6 +32,7 @@ func (d *MaxAllCPU) Fill(q query.Query) query.Query {
 	if !ok {
 		common.PanicUnimplementedQuery(d.core)
 	}
+	startQuery(d.core, LabelMaxAll, cpuMetrics)
 	fc.MaxAllCPU(q, d.hosts, d.duration)
 	return q
 }
@@ -34,6 +34,8 @@ func (d *SingleGroupby) Fill(q query.Query) query.Query {
 	if !ok {
 		common.PanicUnimplementedQuery(d.core)
 	}
+	metrics, _ := GetCPUMetricsSlice(d.metrics)
+	startQuery(d.core, LabelSingleGroupby, metrics)
 	fc.GroupByTime(q, d.hosts, d.metrics, time.Duration(int64(d.hours)*int64(time.Hour)))
 	return q
 }
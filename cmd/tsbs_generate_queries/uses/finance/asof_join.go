@@ -0,0 +1,29 @@
+package finance
+
+import (
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/common"
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/utils"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+// AsOfJoin contains info for filling in trades joined with the prevailing quotes queries.
+type AsOfJoin struct {
+	core utils.QueryGenerator
+}
+
+// NewAsOfJoin creates a new trades joined with the prevailing quotes query filler.
+func NewAsOfJoin(core utils.QueryGenerator) utils.QueryFiller {
+	return &AsOfJoin{
+		core: core,
+	}
+}
+
+// Fill fills in the query.Query with query details.
+func (i *AsOfJoin) Fill(q query.Query) query.Query {
+	fc, ok := i.core.(AsOfJoinFiller)
+	if !ok {
+		common.PanicUnimplementedQuery(i.core)
+	}
+	fc.AsOfJoin(q)
+	return q
+}
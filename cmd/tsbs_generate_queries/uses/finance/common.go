@@ -0,0 +1,105 @@
+package finance
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/common"
+	"github.com/timescale/tsbs/pkg/data/usecases/finance"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+const (
+	// TradesTableName is the name of the table where all the trades are stored.
+	TradesTableName = "trades"
+	// QuotesTableName is the name of the table where all the quote updates are stored.
+	QuotesTableName = "quotes"
+	// BarsTableName is the name of the table where all the OHLCV bars are stored.
+	BarsTableName = "bars"
+
+	// VWAPDuration is the time duration over which the VWAP is computed.
+	VWAPDuration = time.Hour
+	// VWAPWindow is the window of each VWAP.
+	VWAPWindow = 5 * time.Minute
+	// TopMoversDuration is the time duration over which the top movers are found.
+	TopMoversDuration = 24 * time.Hour
+	// TopMoversLimit is the number of top movers.
+	TopMoversLimit = 10
+	// AsOfJoinDuration is the time duration of the trades joined with their quotes.
+	AsOfJoinDuration = time.Hour
+
+	// LabelVWAP is the label for the volume weighted average price query.
+	LabelVWAP = "vwap"
+	// LabelLastQuote is the label for the last quote per symbol query.
+	LabelLastQuote = "last-quote"
+	// LabelTopMovers is the label for the top movers query.
+	LabelTopMovers = "top-movers"
+	// LabelAsOfJoin is the label for the trades joined with the prevailing quotes query.
+	LabelAsOfJoin = "asof-join"
+)
+
+// Core is the common component of all generators for all systems.
+type Core struct {
+	*common.Core
+}
+
+// NewCore returns a new Core for the given time range and cardinality
+func NewCore(start, end time.Time, scale int) (*Core, error) {
+	c, err := common.NewCore(start, end, scale)
+	return &Core{Core: c}, err
+}
+
+// GetRandomExchange returns one of the exchange choices by random.
+func (c Core) GetRandomExchange() string {
+	return finance.ExchangeChoices[rand.Intn(len(finance.ExchangeChoices))]
+}
+
+// GetRandomSymbols returns a random set of nSymbols from a given Core
+func (c *Core) GetRandomSymbols(nSymbols int) ([]string, error) {
+	return getRandomSymbols(nSymbols, c.Scale)
+}
+
+// getRandomSymbols returns a subset of numSymbols names of a permutation of
+// symbol names, numbered from 0 to totalSymbols.
+// Ex.: sym_12, sym_7, sym_25 for numSymbols=3 and totalSymbols=30 (3 out of 30)
+func getRandomSymbols(numSymbols int, totalSymbols int) ([]string, error) {
+	if numSymbols < 1 {
+		return nil, fmt.Errorf("number of symbols cannot be < 1; got %d", numSymbols)
+	}
+	if numSymbols > totalSymbols {
+		return nil, fmt.Errorf("number of symbols (%d) larger than total symbols. See --scale (%d)", numSymbols, totalSymbols)
+	}
+
+	randomNumbers, err := common.GetRandomSubsetPerm(numSymbols, totalSymbols)
+	if err != nil {
+		return nil, err
+	}
+
+	symbols := []string{}
+	for _, n := range randomNumbers {
+		symbols = append(symbols, fmt.Sprintf(finance.SymbolNameFmt, n))
+	}
+
+	return symbols, nil
+}
+
+// VWAPFiller is a type that can fill in a volume weighted average price query.
+type VWAPFiller interface {
+	VWAP(query.Query, int)
+}
+
+// LastQuoteFiller is a type that can fill in a last quote per symbol query.
+type LastQuoteFiller interface {
+	LastQuotePerSymbol(query.Query)
+}
+
+// TopMoversFiller is a type that can fill in a top movers query.
+type TopMoversFiller interface {
+	TopMovers(query.Query)
+}
+
+// AsOfJoinFiller is a type that can fill in a trades joined with the prevailing quotes query.
+type AsOfJoinFiller interface {
+	AsOfJoin(query.Query)
+}
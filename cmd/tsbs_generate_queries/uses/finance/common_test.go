@@ -0,0 +1,87 @@
+package finance
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/data/usecases/finance"
+)
+
+func TestCoreGetRandomExchange(t *testing.T) {
+	c, err := NewCore(time.Now(), time.Now(), 10)
+	if err != nil {
+		t.Fatalf("unexpected error for NewCore: %v", err)
+	}
+
+	rand.Seed(100) // Resetting seed to get a deterministic output.
+	for i := 0; i < 100; i++ {
+		exchange := c.GetRandomExchange()
+		found := false
+		for _, e := range finance.ExchangeChoices {
+			if e == exchange {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Fatalf("random exchange %s is not one of the exchange choices", exchange)
+		}
+	}
+}
+
+func TestGetRandomSymbols(t *testing.T) {
+	cases := []struct {
+		desc      string
+		scale     int
+		nSymbols  int
+		want      string
+		shouldErr bool
+		errMsg    string
+	}{
+		{
+			desc:      "0 symbols out of 100",
+			scale:     100,
+			nSymbols:  0,
+			shouldErr: true,
+			errMsg:    "number of symbols cannot be < 1; got 0",
+		},
+		{
+			desc:     "1 symbol out of 100",
+			scale:    100,
+			nSymbols: 1,
+			want:     "sym_83",
+		},
+		{
+			desc:     "5 symbols out of 100",
+			scale:    100,
+			nSymbols: 5,
+			want:     "sym_83,sym_68,sym_80,sym_60,sym_62",
+		},
+		{
+			desc:      "5 symbols out of 1",
+			scale:     1,
+			nSymbols:  5,
+			shouldErr: true,
+			errMsg:    "number of symbols (5) larger than total symbols. See --scale (1)",
+		},
+	}
+
+	for _, c := range cases {
+		rand.Seed(100) // always reset the random number generator
+		symbols, err := getRandomSymbols(c.nSymbols, c.scale)
+		if c.shouldErr {
+			if symbols != nil {
+				t.Errorf("%s: errored but with non-nil return: %v", c.desc, symbols)
+			}
+			if got := err.Error(); got != c.errMsg {
+				t.Errorf("%s: incorrect error:\ngot\n%s\nwant\n%s", c.desc, got, c.errMsg)
+			}
+		} else if err != nil {
+			t.Fatalf("%s: unexpected error: got %v", c.desc, err)
+		} else if got := strings.Join(symbols, ","); got != c.want {
+			t.Errorf("%s: incorrect output: got %s want %s", c.desc, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package finance
+
+import (
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/common"
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/utils"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+// LastQuotePerSymbol contains info for filling in last quote per symbol queries.
+type LastQuotePerSymbol struct {
+	core utils.QueryGenerator
+}
+
+// NewLastQuotePerSymbol creates a new last quote per symbol query filler.
+func NewLastQuotePerSymbol(core utils.QueryGenerator) utils.QueryFiller {
+	return &LastQuotePerSymbol{
+		core: core,
+	}
+}
+
+// Fill fills in the query.Query with query details.
+func (i *LastQuotePerSymbol) Fill(q query.Query) query.Query {
+	fc, ok := i.core.(LastQuoteFiller)
+	if !ok {
+		common.PanicUnimplementedQuery(i.core)
+	}
+	fc.LastQuotePerSymbol(q)
+	return q
+}
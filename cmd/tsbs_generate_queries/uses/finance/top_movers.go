@@ -0,0 +1,29 @@
+package finance
+
+import (
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/common"
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/utils"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+// TopMovers contains info for filling in top movers queries.
+type TopMovers struct {
+	core utils.QueryGenerator
+}
+
+// NewTopMovers creates a new top movers query filler.
+func NewTopMovers(core utils.QueryGenerator) utils.QueryFiller {
+	return &TopMovers{
+		core: core,
+	}
+}
+
+// Fill fills in the query.Query with query details.
+func (i *TopMovers) Fill(q query.Query) query.Query {
+	fc, ok := i.core.(TopMoversFiller)
+	if !ok {
+		common.PanicUnimplementedQuery(i.core)
+	}
+	fc.TopMovers(q)
+	return q
+}
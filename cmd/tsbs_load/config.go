@@ -29,6 +29,27 @@ type RunnerConfig struct {
 	InsertIntervals string `yaml:"insert-intervals" mapstructure:"insert-intervals"`
 	FlowControl     bool   `yaml:"flow-control" mapstructure:"flow-control"`
 	ChannelCapacity uint   `yaml:"channel-capacity" mapstructure:"channel-capacity"`
+	DecodeWorkers   uint   `yaml:"decode-workers" mapstructure:"decode-workers"`
+
+	MaxRetries       uint          `yaml:"max-retries" mapstructure:"max-retries"`
+	RetryBackoff     time.Duration `yaml:"retry-backoff" mapstructure:"retry-backoff"`
+	MaxFailedBatches uint64        `yaml:"max-failed-batches" mapstructure:"max-failed-batches"`
+	MetricsListen    string        `yaml:"metrics-listen" mapstructure:"metrics-listen"`
+	PipelineStats    bool          `yaml:"pipeline-stats" mapstructure:"pipeline-stats"`
+	FlushInterval    time.Duration `yaml:"flush-interval" mapstructure:"flush-interval"`
+	Coordinator      string
+
+	CheckpointFile     string        `yaml:"checkpoint-file" mapstructure:"checkpoint-file"`
+	CheckpointInterval time.Duration `yaml:"checkpoint-interval" mapstructure:"checkpoint-interval"`
+	ResumeFrom         string        `yaml:"resume-from" mapstructure:"resume-from"`
+
+	TargetRate     string `yaml:"target-rate" mapstructure:"target-rate"`
+	TargetRateUnit string `yaml:"target-rate-unit" mapstructure:"target-rate-unit"`
+
+	TuneBatchSize    bool   `yaml:"tune-batch-size" mapstructure:"tune-batch-size"`
+	TuneMinBatchSize uint   `yaml:"tune-min-batch-size" mapstructure:"tune-min-batch-size"`
+	TuneMaxBatchSize uint   `yaml:"tune-max-batch-size" mapstructure:"tune-max-batch-size"`
+	TuneProbeItems   uint64 `yaml:"tune-probe-items" mapstructure:"tune-probe-items"`
 }
 
 type DataSourceConfig struct {
@@ -51,4 +72,9 @@ type SimulatorDataSourceConfig struct {
 	Limit                 uint64        `yaml:"max-data-points" mapstructure:"max-data-points"`
 	LogInterval           time.Duration `yaml:"log-interval" mapstructure:"log-interval"`
 	MaxMetricCountPerHost uint64        `yaml:"max-metric-count" mapstructure:"max-metric-count"`
+	RealTime              bool          `yaml:"real-time" mapstructure:"real-time"`
+	UseCaseFile           string        `yaml:"use-case-file" mapstructure:"use-case-file"`
+	ChurnRate             float64       `yaml:"churn-rate" mapstructure:"churn-rate"`
+	ScaleStrategy         string        `yaml:"scale-strategy" mapstructure:"scale-strategy"`
+	ScaleSteps            uint64        `yaml:"scale-steps" mapstructure:"scale-steps"`
 }
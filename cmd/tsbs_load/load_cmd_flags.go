@@ -5,6 +5,7 @@ import (
 	"github.com/spf13/pflag"
 	"github.com/timescale/tsbs/load"
 	"github.com/timescale/tsbs/pkg/data/source"
+	"github.com/timescale/tsbs/pkg/data/usecases/common"
 	"strings"
 	"time"
 )
@@ -14,6 +15,11 @@ const (
 	defaultTimeEnd     = "2020-01-02T00:00:00Z"
 	defaultLogInterval = 10 * time.Second
 	defaultScale       = 1
+	defaultChurnRate   = 0.001
+	defaultScaleSteps  = 4
+
+	// defaultRealTimeFlushInterval is the flush interval of real-time simulations without one
+	defaultRealTimeFlushInterval = 100 * time.Millisecond
 )
 
 func addLoaderRunnerFlags(fs *pflag.FlagSet) {
@@ -67,6 +73,27 @@ func addLoaderRunnerFlags(fs *pflag.FlagSet) {
 			"Default 0 means that:\n\tif hash-workers=false then capacity = 5 * number of workers\n\t"+
 			"if hash-workers=true, then capacity = 5 for each worker",
 	)
+	load.AddRetryFlags(fs, "loader.runner.")
+	fs.String(
+		"loader.runner.metrics-listen",
+		"",
+		"Serve the progress of the load as OpenMetrics under /metrics on this address, e.g. ':9090'",
+	)
+	fs.Bool(
+		"loader.runner.pipeline-stats",
+		false,
+		"Time the decoding, batching, waiting and writing of the items, to find out whether the loader or the database is the bottleneck",
+	)
+	fs.String(
+		"loader.runner.coordinator",
+		"",
+		"Load a shard of the data as an agent of the tsbs_coordinator at this address",
+	)
+	load.AddCheckpointFlags(fs, "loader.runner.")
+	load.AddTargetRateFlags(fs, "loader.runner.")
+	load.AddBatchSizeTuningFlags(fs, "loader.runner.")
+	load.AddParallelDecodingFlags(fs, "loader.runner.")
+	load.AddFlushFlags(fs, "loader.runner.")
 }
 
 func addDataSourceFlags(fs *pflag.FlagSet) {
@@ -96,9 +123,35 @@ func addDataSourceFlags(fs *pflag.FlagSet) {
 		100,
 		"Max number of metric fields to generate per host. Used only in devops-generic use-case",
 	)
+	fs.String(
+		"data-source.simulator.use-case-file",
+		"",
+		"YAML schema of the measurements to generate. Used only in custom use-case",
+	)
+	fs.Float64(
+		"data-source.simulator.churn-rate",
+		defaultChurnRate,
+		"Fraction of the pods replaced by new pods every log interval. Used only in k8s use-case",
+	)
+	fs.String(
+		"data-source.simulator.scale-strategy",
+		common.ScaleStrategyLinear,
+		fmt.Sprintf("How the number of reporting entities changes between initial-scale and scale over time. (choices: %s)", strings.Join(common.ScaleStrategyChoices, ", ")),
+	)
+	fs.Uint64(
+		"data-source.simulator.scale-steps",
+		defaultScaleSteps,
+		"Number of steps to grow from initial-scale to scale in. Used only with the step scale strategy",
+	)
 	fs.Uint64(
 		"data-source.simulator.scale",
 		defaultScale,
 		"Scaling value specific to use case (e.g., devices in 'devops', trucks in iot).")
 	fs.Duration("data-source.simulator.log-interval", defaultLogInterval, "Duration between data points")
+	fs.Bool(
+		"data-source.simulator.real-time",
+		false,
+		"Release the points at the pace of the log interval, timestamped with the current time, as a live feed "+
+			"lasting from timestamp-start to timestamp-end",
+	)
 }
@@ -8,6 +8,7 @@ import (
 	"github.com/timescale/tsbs/pkg/data/source"
 	"github.com/timescale/tsbs/pkg/data/usecases/common"
 	"github.com/timescale/tsbs/pkg/targets"
+	"log"
 )
 
 func parseConfig(target targets.ImplementedTarget, v *viper.Viper) (targets.Benchmark, load.BenchmarkRunner, error) {
@@ -37,6 +38,10 @@ func parseConfig(target targets.ImplementedTarget, v *viper.Viper) (targets.Benc
 	}
 
 	loaderConfigInternal := convertRunnerConfigToInternalRep(loaderConfig)
+	if dataSource.Simulator != nil && dataSource.Simulator.RealTime && loaderConfigInternal.FlushInterval == 0 {
+		// a live feed must not hold points back until a batch is full
+		loaderConfigInternal.FlushInterval = defaultRealTimeFlushInterval
+	}
 
 	dbSpecificViper := loaderViper.Sub("db-specific")
 	if dbSpecificViper == nil {
@@ -47,6 +52,10 @@ func parseConfig(target targets.ImplementedTarget, v *viper.Viper) (targets.Benc
 	if err != nil {
 		return nil, nil, err
 	}
+	if hb, ok := benchmark.(targets.HashingBenchmark); ok && hb.RequiresHashWorkers() && !loaderConfigInternal.HashWorkers {
+		log.Printf("%s requires hash-workers with this config, loading with hash-workers: true", target.TargetName())
+		loaderConfigInternal.HashWorkers = true
+	}
 
 	return benchmark, load.GetBenchmarkRunner(*loaderConfigInternal), nil
 }
@@ -74,6 +83,27 @@ func convertRunnerConfigToInternalRep(r *RunnerConfig) *load.BenchmarkRunnerConf
 		InsertIntervals: r.InsertIntervals,
 		NoFlowControl:   !r.FlowControl,
 		ChannelCapacity: r.ChannelCapacity,
+		DecodeWorkers:   r.DecodeWorkers,
+
+		MaxRetries:       r.MaxRetries,
+		RetryBackoff:     r.RetryBackoff,
+		MaxFailedBatches: r.MaxFailedBatches,
+		MetricsListen:    r.MetricsListen,
+		PipelineStats:    r.PipelineStats,
+		FlushInterval:    r.FlushInterval,
+		Coordinator:      r.Coordinator,
+
+		CheckpointFile:     r.CheckpointFile,
+		CheckpointInterval: r.CheckpointInterval,
+		ResumeFrom:         r.ResumeFrom,
+
+		TargetRate:     r.TargetRate,
+		TargetRateUnit: r.TargetRateUnit,
+
+		TuneBatchSize:    r.TuneBatchSize,
+		TuneMinBatchSize: r.TuneMinBatchSize,
+		TuneMaxBatchSize: r.TuneMaxBatchSize,
+		TuneProbeItems:   r.TuneProbeItems,
 	}
 }
 
@@ -138,6 +168,11 @@ func convertDataSourceConfigToInternalRepresentation(format string, d *DataSourc
 			LogInterval:           d.Simulator.LogInterval,
 			MaxMetricCountPerHost: d.Simulator.MaxMetricCountPerHost,
 			InterleavedNumGroups:  1,
+			RealTime:              d.Simulator.RealTime,
+			UseCaseFile:           d.Simulator.UseCaseFile,
+			ChurnRate:             d.Simulator.ChurnRate,
+			ScaleStrategy:         d.Simulator.ScaleStrategy,
+			ScaleSteps:            d.Simulator.ScaleSteps,
 		}
 	}
 	return &source.DataSourceConfig{
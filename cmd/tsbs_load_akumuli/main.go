@@ -5,15 +5,14 @@
 package main
 
 import (
-	"bytes"
 	"fmt"
 	"log"
-	"sync"
 
 	"github.com/blagojts/viper"
 	"github.com/spf13/pflag"
 	"github.com/timescale/tsbs/internal/utils"
 	"github.com/timescale/tsbs/load"
+	"github.com/timescale/tsbs/pkg/data/source"
 	"github.com/timescale/tsbs/pkg/targets"
 	"github.com/timescale/tsbs/pkg/targets/akumuli"
 	"github.com/timescale/tsbs/pkg/targets/constants"
@@ -59,11 +58,12 @@ func init() {
 }
 
 func main() {
-	bufPool := sync.Pool{
-		New: func() interface{} {
-			return bytes.NewBuffer(make([]byte, 0, 4*1024*1024))
-		},
+	benchmark, err := akumuli.NewBenchmark(&akumuli.SpecificConfig{Endpoint: endpoint}, &source.DataSourceConfig{
+		Type: source.FileDataSourceType,
+		File: &source.FileDataSourceConfig{Location: loaderConf.FileName},
+	})
+	if err != nil {
+		fatal("%v", err)
 	}
-	benchmark := akumuli.NewBenchmark(loaderConf.FileName, endpoint, &bufPool)
 	loader.RunBenchmark(benchmark)
 }
@@ -5,44 +5,22 @@ package main
 
 import (
 	"fmt"
-	"time"
+	"log"
 
 	"github.com/blagojts/viper"
 	"github.com/spf13/pflag"
 	"github.com/timescale/tsbs/internal/utils"
 	"github.com/timescale/tsbs/load"
-	"github.com/timescale/tsbs/pkg/targets"
+	"github.com/timescale/tsbs/pkg/data/source"
 	"github.com/timescale/tsbs/pkg/targets/constants"
 	"github.com/timescale/tsbs/pkg/targets/initializers"
-)
-
-const (
-	collectionName     = "point_data"
-	aggDocID           = "doc_id"
-	aggDateFmt         = "20060102_15" // see Go docs for how we arrive at this time format
-	aggKeyID           = "key_id"
-	aggInsertBatchSize = 500 // found via trial-and-error
-	timestampField     = "timestamp_ns"
-)
-
-// Program option vars:
-var (
-	daemonURL    string
-	documentPer  bool
-	writeTimeout time.Duration
-)
-
-// Global vars
-var (
-	loader load.BenchmarkRunner
-	config load.BenchmarkRunnerConfig
-	target targets.ImplementedTarget
+	"github.com/timescale/tsbs/pkg/targets/mongo"
 )
 
 // Parse args:
-func init() {
-	target = initializers.GetTarget(constants.FormatMongo)
-	config = load.BenchmarkRunnerConfig{}
+func initProgramOptions() (*mongo.SpecificConfig, *load.BenchmarkRunnerConfig, load.BenchmarkRunner) {
+	target := initializers.GetTarget(constants.FormatMongo)
+	config := load.BenchmarkRunnerConfig{}
 	config.AddToFlagSet(pflag.CommandLine)
 	target.TargetSpecificFlags("", pflag.CommandLine)
 
@@ -58,25 +36,26 @@ func init() {
 		panic(fmt.Errorf("unable to decode config: %s", err))
 	}
 
-	daemonURL = viper.GetString("url")
-	writeTimeout = viper.GetDuration("write-timeout")
-	documentPer = viper.GetBool("document-per-event")
-	if documentPer {
-		config.HashWorkers = false
-	} else {
-		config.HashWorkers = true
+	dbConfig := &mongo.SpecificConfig{
+		URL:              viper.GetString("url"),
+		WriteTimeout:     viper.GetDuration("write-timeout"),
+		DocumentPerEvent: viper.GetBool("document-per-event"),
 	}
+	// the aggregated documents need all data of a host in the same worker
+	config.HashWorkers = !dbConfig.DocumentPerEvent
 
-	loader = load.GetBenchmarkRunner(config)
+	loader := load.GetBenchmarkRunner(config)
+	return dbConfig, &config, loader
 }
 
 func main() {
-	var benchmark targets.Benchmark
-	if documentPer {
-		benchmark = newNaiveBenchmark(loader, &config)
-	} else {
-		benchmark = newAggBenchmark(loader, &config)
+	dbConfig, loaderConf, loader := initProgramOptions()
+	benchmark, err := mongo.NewBenchmark(loaderConf.DBName, dbConfig, &source.DataSourceConfig{
+		Type: source.FileDataSourceType,
+		File: &source.FileDataSourceConfig{Location: loaderConf.FileName},
+	})
+	if err != nil {
+		log.Fatal(err)
 	}
-
 	loader.RunBenchmark(benchmark)
 }
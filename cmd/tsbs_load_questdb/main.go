@@ -5,40 +5,29 @@
 package main
 
 import (
-	"bufio"
-	"bytes"
 	"fmt"
 	"log"
-	"sync"
 	"time"
 
 	"github.com/blagojts/viper"
 	"github.com/spf13/pflag"
 	"github.com/timescale/tsbs/internal/utils"
 	"github.com/timescale/tsbs/load"
+	"github.com/timescale/tsbs/pkg/data/source"
 	"github.com/timescale/tsbs/pkg/targets"
 	"github.com/timescale/tsbs/pkg/targets/constants"
 	"github.com/timescale/tsbs/pkg/targets/initializers"
-)
-
-// Program option vars:
-var (
-	questdbRESTEndPoint string
-	questdbILPBindTo    string
-	doAbortOnExist      bool
+	"github.com/timescale/tsbs/pkg/targets/questdb"
 )
 
 // Global vars
 var (
-	loader  load.BenchmarkRunner
-	config  load.BenchmarkRunnerConfig
-	bufPool sync.Pool
-	target  targets.ImplementedTarget
+	loader   load.BenchmarkRunner
+	config   load.BenchmarkRunnerConfig
+	dbConfig *questdb.SpecificConfig
+	target   targets.ImplementedTarget
 )
 
-// allows for testing
-var fatal = log.Fatalf
-
 // Parse args:
 func init() {
 	target = initializers.GetTarget(constants.FormatQuestDB)
@@ -54,6 +43,15 @@ func init() {
 	pflag.CommandLine.Int64("seed", 0, "PRNG seed (default: 0, which uses the current timestamp)")
 	pflag.CommandLine.String("insert-intervals", "", "Time to wait between each insert, default '' => all workers insert ASAP. '1,2' = worker 1 waits 1s between inserts, worker 2 and others wait 2s")
 	pflag.CommandLine.Bool("hash-workers", false, "Whether to consistently hash insert data to the same workers (i.e., the data for a particular host always goes to the same worker)")
+	pflag.CommandLine.Bool("pipeline-stats", false, "Time the decoding, batching, waiting and writing of the items, to find out whether the loader or the database is the bottleneck")
+	load.AddTargetRateFlags(pflag.CommandLine, "")
+	load.AddReportingFlags(pflag.CommandLine, "")
+	load.AddCoordinatorFlags(pflag.CommandLine, "")
+	load.AddRetryFlags(pflag.CommandLine, "")
+	load.AddCheckpointFlags(pflag.CommandLine, "")
+	load.AddBatchSizeTuningFlags(pflag.CommandLine, "")
+	load.AddParallelDecodingFlags(pflag.CommandLine, "")
+	load.AddFlushFlags(pflag.CommandLine, "")
 	target.TargetSpecificFlags("", pflag.CommandLine)
 	pflag.Parse()
 
@@ -67,40 +65,21 @@ func init() {
 		panic(fmt.Errorf("unable to decode config: %s", err))
 	}
 
-	questdbRESTEndPoint = viper.GetString("url")
-	questdbILPBindTo = viper.GetString("ilp-bind-to")
+	dbConfig = &questdb.SpecificConfig{
+		URL:       viper.GetString("url"),
+		ILPBindTo: viper.GetString("ilp-bind-to"),
+	}
 	config.HashWorkers = false
 	loader = load.GetBenchmarkRunner(config)
 }
 
-type benchmark struct{}
-
-func (b *benchmark) GetDataSource() targets.DataSource {
-	return &fileDataSource{scanner: bufio.NewScanner(load.GetBufferedReader(config.FileName))}
-}
-
-func (b *benchmark) GetBatchFactory() targets.BatchFactory {
-	return &factory{}
-}
-
-func (b *benchmark) GetPointIndexer(_ uint) targets.PointIndexer {
-	return &targets.ConstantIndexer{}
-}
-
-func (b *benchmark) GetProcessor() targets.Processor {
-	return &processor{}
-}
-
-func (b *benchmark) GetDBCreator() targets.DBCreator {
-	return &dbCreator{}
-}
-
 func main() {
-	bufPool = sync.Pool{
-		New: func() interface{} {
-			return bytes.NewBuffer(make([]byte, 0, 4*1024*1024))
-		},
+	benchmark, err := questdb.NewBenchmark(dbConfig, &source.DataSourceConfig{
+		Type: source.FileDataSourceType,
+		File: &source.FileDataSourceConfig{Location: config.FileName},
+	})
+	if err != nil {
+		log.Fatal(err)
 	}
-
-	loader.RunBenchmark(&benchmark{})
+	loader.RunBenchmark(benchmark)
 }
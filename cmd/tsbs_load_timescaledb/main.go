@@ -32,49 +32,10 @@ func initProgramOptions() (*timescaledb.LoadingOptions, load.BenchmarkRunner, *l
 	if err := viper.Unmarshal(&loaderConf); err != nil {
 		panic(fmt.Errorf("unable to decode config: %s", err))
 	}
-	opts := timescaledb.LoadingOptions{}
-	viper.SetTypeByDefaultValue(true)
-	opts.PostgresConnect = viper.GetString("postgres")
-	opts.Host = viper.GetString("host")
-	opts.Port = viper.GetString("port")
-	opts.User = viper.GetString("user")
-	opts.Pass = viper.GetString("pass")
-	opts.ConnDB = viper.GetString("admin-db-name")
-	opts.LogBatches = viper.GetBool("log-batches")
-
-	opts.UseHypertable = viper.GetBool("use-hypertable")
-	opts.ChunkTime = viper.GetDuration("chunk-time")
-
-	opts.UseJSON = viper.GetBool("use-jsonb-tags")
-
-	// This must be set to 'true' if you are going to test
-	// distributed hypertable queries and insert. Replication
-	// factor must also be set to true for distributed hypertables
-	opts.InTableTag = viper.GetBool("in-table-partition-tag")
-
-	// 	We currently use `create_hypertable` for all variations. When
-	//   `replication-factor`>=1, we automatically create a distributed
-	//   hypertable.
-	opts.ReplicationFactor = viper.GetInt("replication-factor")
-	// Currently ignored for distributed hypertables. We assume all
-	// data nodes will be used based on the partition-column above
-	opts.NumberPartitions = viper.GetInt("partitions")
-
-	opts.TimeIndex = viper.GetBool("time-index")
-	opts.TimePartitionIndex = viper.GetBool("time-partition-index")
-	opts.PartitionIndex = viper.GetBool("partition-index")
-	opts.FieldIndex = viper.GetString("field-index")
-	opts.FieldIndexCount = viper.GetInt("field-index-count")
-
-	opts.ProfileFile = viper.GetString("write-profile")
-	opts.ReplicationStatsFile = viper.GetString("write-replication-stats")
-	opts.CreateMetricsTable = viper.GetBool("create-metrics-table")
-
-	opts.ForceTextFormat = viper.GetBool("force-text-format")
-	opts.UseInsert = viper.GetBool("use-insert")
+	opts := timescaledb.ParseLoadingOptions(viper.GetViper())
 
 	loader := load.GetBenchmarkRunner(loaderConf)
-	return &opts, loader, &loaderConf
+	return opts, loader, &loaderConf
 }
 
 func main() {
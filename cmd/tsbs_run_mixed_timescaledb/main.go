@@ -0,0 +1,149 @@
+// tsbs_run_mixed_timescaledb loads a TimescaleDB instance with data from stdin
+// or a file and at the same time runs queries from a file against it.
+//
+// Query latencies are reported per query type and per write pressure at the
+// time the query was sent. If the database exists beforehand, it will be *DROPPED*.
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/blagojts/viper"
+	_ "github.com/lib/pq"
+	"github.com/spf13/pflag"
+	"github.com/timescale/tsbs/internal/utils"
+	"github.com/timescale/tsbs/pkg/data/source"
+	"github.com/timescale/tsbs/pkg/mixed"
+	"github.com/timescale/tsbs/pkg/query"
+	"github.com/timescale/tsbs/pkg/targets/timescaledb"
+)
+
+const pgxDriver = "pgx" // default driver
+const pqDriver = "postgres"
+
+// Global vars:
+var (
+	opts   *timescaledb.LoadingOptions
+	config mixed.BenchmarkRunnerConfig
+	runner *mixed.BenchmarkRunner
+	driver string
+)
+
+// Parse args:
+func init() {
+	target := timescaledb.NewTarget()
+	config.Load.AddToFlagSet(pflag.CommandLine)
+	target.TargetSpecificFlags("", pflag.CommandLine)
+	config.AddToFlagSet(pflag.CommandLine)
+	// the queries always run against the loaded database
+	pflag.CommandLine.MarkHidden(mixed.QueryFlagPrefix + "db-name")
+
+	pflag.Parse()
+
+	err := utils.SetupConfigFile()
+
+	if err != nil {
+		panic(fmt.Errorf("fatal error config file: %s", err))
+	}
+
+	if err := viper.Unmarshal(&config); err != nil {
+		panic(fmt.Errorf("unable to decode config: %s", err))
+	}
+	if err := viper.Unmarshal(&config.Load); err != nil {
+		panic(fmt.Errorf("unable to decode config: %s", err))
+	}
+	if config.Query.FileName == "" {
+		log.Fatalf("--%sfile is required, the queries can not be read from stdin while loading", mixed.QueryFlagPrefix)
+	}
+
+	opts = timescaledb.ParseLoadingOptions(viper.GetViper())
+	if opts.ForceTextFormat {
+		driver = pqDriver
+	} else {
+		driver = pgxDriver
+	}
+
+	runner, err = mixed.NewBenchmarkRunner(config)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func main() {
+	benchmark, err := timescaledb.NewBenchmark(config.Load.DBName, opts, &source.DataSourceConfig{
+		Type: source.FileDataSourceType,
+		File: &source.FileDataSourceConfig{Location: config.Load.FileName},
+	})
+	if err != nil {
+		panic(err)
+	}
+	runner.Run(benchmark, &query.TimescaleDBPool, newProcessor)
+}
+
+// processor runs the queries and fetches their rows, the responses are not
+// printed in a mixed benchmark
+type processor struct {
+	db      *sql.DB
+	timeout time.Duration
+}
+
+func newProcessor() query.Processor { return &processor{} }
+
+func (p *processor) Init(workerNumber int) {
+	p.db = timescaledb.MustConnect(driver, opts.GetConnectString(config.Load.DBName))
+}
+
+// SetQueryTimeout sets the timeout after which queries are cancelled
+func (p *processor) SetQueryTimeout(timeout time.Duration) {
+	p.timeout = timeout
+}
+
+func (p *processor) ProcessQuery(q query.Query, isWarm bool) ([]*query.Stat, error) {
+	stats, _, err := p.processQuery(q, false)
+	return stats, err
+}
+
+// ProcessQueryResult runs the query like ProcessQuery and also returns its normalized result
+func (p *processor) ProcessQueryResult(q query.Query, isWarm bool) ([]*query.Stat, *query.Result, error) {
+	return p.processQuery(q, true)
+}
+
+func (p *processor) processQuery(q query.Query, withResult bool) ([]*query.Stat, *query.Result, error) {
+	tq := q.(*query.TimescaleDB)
+
+	var result *query.Result
+	start := time.Now()
+	ctx := context.Background()
+	if p.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.timeout)
+		defer cancel()
+	}
+	rows, err := p.db.QueryContext(ctx, string(tq.SqlQuery))
+	if err != nil {
+		return nil, nil, err
+	}
+	if withResult {
+		result, err = query.ReadSQLResult(rows)
+		if err != nil {
+			rows.Close()
+			return nil, nil, err
+		}
+	}
+	// Fetching all the rows to confirm that the query is fully completed.
+	for rows.Next() {
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+	took := float64(time.Since(start).Nanoseconds()) / 1e6
+	stat := query.GetStat()
+	stat.Init(q.HumanLabelName(), took)
+
+	return []*query.Stat{stat}, result, nil
+}
@@ -142,14 +142,25 @@ func (p *processor) Init(workerNumber int) {
 
 // query.Processor interface implementation
 func (p *processor) ProcessQuery(q query.Query, isWarm bool) ([]*query.Stat, error) {
+	stats, _, err := p.processQuery(q, isWarm, false)
+	return stats, err
+}
+
+// ProcessQueryResult runs the query like ProcessQuery and also returns its normalized result
+func (p *processor) ProcessQueryResult(q query.Query, isWarm bool) ([]*query.Stat, *query.Result, error) {
+	return p.processQuery(q, isWarm, true)
+}
+
+func (p *processor) processQuery(q query.Query, isWarm bool, withResult bool) ([]*query.Stat, *query.Result, error) {
 	// No need to run again for EXPLAIN
 	if isWarm && p.opts.showExplain {
-		return nil, nil
+		return nil, nil, nil
 	}
 
 	// Ensure ClickHouse query
 	chQuery := q.(*query.ClickHouse)
 
+	var result *query.Result
 	start := time.Now()
 
 	// SqlQuery is []byte, so cast is needed
@@ -158,14 +169,20 @@ func (p *processor) ProcessQuery(q query.Query, isWarm bool) ([]*query.Stat, err
 	// Main action - run the query
 	rows, err := p.db.Queryx(sql)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// Print some extra info if needed
 	if p.opts.debug {
 		fmt.Println(sql)
 	}
-	if p.opts.printResponse {
+	if withResult {
+		result, err = query.ReadSQLResult(rows.Rows)
+		if err != nil {
+			rows.Close()
+			return nil, nil, err
+		}
+	} else if p.opts.printResponse {
 		prettyPrintResponse(rows, chQuery)
 	}
 
@@ -176,5 +193,5 @@ func (p *processor) ProcessQuery(q query.Query, isWarm bool) ([]*query.Stat, err
 	stat := query.GetStat()
 	stat.Init(q.HumanLabelName(), took)
 
-	return []*query.Stat{stat}, err
+	return []*query.Stat{stat}, result, err
 }
@@ -109,12 +109,23 @@ func (p *processor) Init(workerNumber int) {
 }
 
 func (p *processor) ProcessQuery(q query.Query, isWarm bool) ([]*query.Stat, error) {
+	stats, _, err := p.processQuery(q, isWarm, false)
+	return stats, err
+}
+
+// ProcessQueryResult runs the query like ProcessQuery and also returns its normalized result
+func (p *processor) ProcessQueryResult(q query.Query, isWarm bool) ([]*query.Stat, *query.Result, error) {
+	return p.processQuery(q, isWarm, true)
+}
+
+func (p *processor) processQuery(q query.Query, isWarm bool, withResult bool) ([]*query.Stat, *query.Result, error) {
 	// No need to run again for EXPLAIN
 	if isWarm && p.opts.showExplain {
-		return nil, nil
+		return nil, nil, nil
 	}
 	tq := q.(*query.CrateDB)
 
+	var result *query.Result
 	start := time.Now()
 	qry := string(tq.SqlQuery)
 	if showExplain {
@@ -122,7 +133,7 @@ func (p *processor) ProcessQuery(q query.Query, isWarm bool) ([]*query.Stat, err
 	}
 	rows, err := p.conn.Query(context.Background(), qry)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	if p.opts.debug {
@@ -132,6 +143,12 @@ func (p *processor) ProcessQuery(q query.Query, isWarm bool) ([]*query.Stat, err
 		fmt.Printf("Explian Query:\n")
 		prettyPrintResponse(rows, tq)
 		fmt.Printf("\n-----------\n\n")
+	} else if withResult {
+		result, err = readResult(rows)
+		if err != nil {
+			rows.Close()
+			return nil, nil, err
+		}
 	} else if p.opts.printResponse {
 		prettyPrintResponse(rows, tq)
 	}
@@ -141,7 +158,26 @@ func (p *processor) ProcessQuery(q query.Query, isWarm bool) ([]*query.Stat, err
 	stat := query.GetStat()
 	stat.Init(q.HumanLabelName(), took)
 
-	return []*query.Stat{stat}, err
+	return []*query.Stat{stat}, result, err
+}
+
+// readResult reads all the rows into a normalized query.Result
+func readResult(rows pgx.Rows) (*query.Result, error) {
+	fields := rows.FieldDescriptions()
+	cols := make([]string, len(fields))
+	for i, f := range fields {
+		cols[i] = string(f.Name)
+	}
+
+	result := &query.Result{}
+	for rows.Next() {
+		values, err := rows.Values()
+		if err != nil {
+			return nil, errors.Wrap(err, "error while reading values")
+		}
+		result.Rows = append(result.Rows, query.NormalizeRow(cols, values))
+	}
+	return result, rows.Err()
 }
 
 // prettyPrintResponse prints a Query and its response in JSON format with two
@@ -83,7 +83,7 @@ func (p *processor) Init(workerNumber int) {
 
 func (p *processor) ProcessQuery(q query.Query, _ bool) ([]*query.Stat, error) {
 	hq := q.(*query.HTTP)
-	lag, err := p.w.Do(hq, p.opts)
+	lag, _, err := p.w.Do(hq, p.opts)
 	if err != nil {
 		return nil, err
 	}
@@ -91,3 +91,19 @@ func (p *processor) ProcessQuery(q query.Query, _ bool) ([]*query.Stat, error) {
 	stat.Init(q.HumanLabelName(), lag)
 	return []*query.Stat{stat}, nil
 }
+
+// ProcessQueryResult runs the query like ProcessQuery and also returns its normalized result
+func (p *processor) ProcessQueryResult(q query.Query, _ bool) ([]*query.Stat, *query.Result, error) {
+	hq := q.(*query.HTTP)
+	lag, body, err := p.w.Do(hq, p.opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	result, err := readResult(body)
+	if err != nil {
+		return nil, nil, err
+	}
+	stat := query.GetStat()
+	stat.Init(q.HumanLabelName(), lag)
+	return []*query.Stat{stat}, result, nil
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/devops"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+const timeColumn = "time"
+
+// cpuMetricRanks are the positions of the cpu metrics in the reference
+// results, which InfluxDB returns in alphabetical order for 'SELECT *'
+var cpuMetricRanks = func() map[string]int {
+	ranks := make(map[string]int)
+	for i, m := range devops.GetAllCPUMetrics() {
+		ranks[m] = i
+	}
+	return ranks
+}()
+
+// response is the JSON response of the query endpoint. Chunked responses
+// are a stream of them.
+type response struct {
+	Results []struct {
+		Series []struct {
+			Tags    map[string]string
+			Columns []string
+			Values  [][]interface{}
+		}
+		Error string
+	}
+	Error string
+}
+
+// readResult reads the response of the query endpoint into a normalized
+// query.Result. The tags of the series become columns of their rows, and
+// rows without any value, i.e. empty time buckets, are skipped.
+func readResult(body []byte) (*query.Result, error) {
+	result := &query.Result{}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	for {
+		var resp response
+		err := dec.Decode(&resp)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if resp.Error != "" {
+			return nil, errors.New(resp.Error)
+		}
+
+		for _, r := range resp.Results {
+			if r.Error != "" {
+				return nil, errors.New(r.Error)
+			}
+			for _, s := range r.Series {
+				tagKeys := make([]string, 0, len(s.Tags))
+				for k := range s.Tags {
+					tagKeys = append(tagKeys, k)
+				}
+				sort.Strings(tagKeys)
+				cols := orderColumns(append(append([]string{}, s.Columns...), tagKeys...))
+
+				for _, v := range s.Values {
+					if len(v) != len(s.Columns) {
+						return nil, fmt.Errorf("unexpected row in the response: %v", v)
+					}
+					values := v
+					for _, k := range tagKeys {
+						values = append(values, s.Tags[k])
+					}
+					if !hasValues(s.Columns, values) {
+						continue
+					}
+					if err := parseTime(s.Columns, values); err != nil {
+						return nil, err
+					}
+
+					names := make([]string, len(cols))
+					ordered := make([]interface{}, len(cols))
+					for i, idx := range cols {
+						if idx < len(s.Columns) {
+							names[i] = s.Columns[idx]
+						} else {
+							names[i] = tagKeys[idx-len(s.Columns)]
+						}
+						ordered[i] = values[idx]
+					}
+					result.Rows = append(result.Rows, query.NormalizeRow(names, ordered))
+				}
+			}
+		}
+	}
+	return result, nil
+}
+
+// orderColumns returns the indexes of columns with the cpu metrics first,
+// in the order of the reference results, and the other columns after them
+// in their order.
+func orderColumns(columns []string) []int {
+	idx := make([]int, len(columns))
+	for i := range idx {
+		idx[i] = i
+	}
+	rank := func(i int) int {
+		if r, ok := cpuMetricRanks[columns[i]]; ok {
+			return r
+		}
+		return len(cpuMetricRanks) + i
+	}
+	sort.SliceStable(idx, func(a, b int) bool { return rank(idx[a]) < rank(idx[b]) })
+	return idx
+}
+
+// hasValues returns whether any of the columns other than the time is set
+func hasValues(columns []string, values []interface{}) bool {
+	for i, c := range columns {
+		if c != timeColumn && values[i] != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// parseTime replaces the RFC3339 time of the row with a time.Time
+func parseTime(columns []string, values []interface{}) error {
+	for i, c := range columns {
+		s, ok := values[i].(string)
+		if c != timeColumn || !ok {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return fmt.Errorf("could not parse time %s: %v", s, err)
+		}
+		values[i] = t
+	}
+	return nil
+}
@@ -14,6 +14,7 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 
 	"github.com/blagojts/viper"
 	"github.com/spf13/pflag"
@@ -95,7 +96,7 @@ func (p *processor) Init(workerNumber int) {
 
 func (p *processor) ProcessQuery(q query.Query, _ bool) ([]*query.Stat, error) {
 	hq := q.(*query.HTTP)
-	lag, err := p.w.Do(hq, p.opts)
+	lag, _, err := p.w.Do(hq, p.opts)
 	if err != nil {
 		return nil, err
 	}
@@ -104,6 +105,22 @@ func (p *processor) ProcessQuery(q query.Query, _ bool) ([]*query.Stat, error) {
 	return []*query.Stat{stat}, nil
 }
 
+// ProcessQueryResult runs the query like ProcessQuery and also returns its normalized result
+func (p *processor) ProcessQueryResult(q query.Query, _ bool) ([]*query.Stat, *query.Result, error) {
+	hq := q.(*query.HTTP)
+	lag, body, err := p.w.Do(hq, p.opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	result, err := readResult(body)
+	if err != nil {
+		return nil, nil, err
+	}
+	stat := query.GetStat()
+	stat.Init(q.HumanLabelName(), lag)
+	return []*query.Stat{stat}, result, nil
+}
+
 type QueryResponseColumns struct {
 	Name string
 	Type string
@@ -141,3 +158,40 @@ func execQuery(uriRoot string, query string) (QueryResponse, error) {
 	}
 	return qr, nil
 }
+
+// readResult reads the response of the exec endpoint into a normalized
+// query.Result. Timestamps are returned as text and parsed.
+func readResult(body []byte) (*query.Result, error) {
+	var qr QueryResponse
+	if err := json.Unmarshal(body, &qr); err != nil {
+		return nil, err
+	}
+	if qr.Error != "" {
+		return nil, errors.New(qr.Error)
+	}
+
+	cols := make([]string, len(qr.Columns))
+	for i, c := range qr.Columns {
+		cols[i] = c.Name
+	}
+	result := &query.Result{}
+	for _, r := range qr.Dataset {
+		values, ok := r.([]interface{})
+		if !ok || len(values) != len(cols) {
+			return nil, fmt.Errorf("unexpected row in the response: %v", r)
+		}
+		for i, v := range values {
+			s, ok := v.(string)
+			if !ok || (qr.Columns[i].Type != "TIMESTAMP" && qr.Columns[i].Type != "DATE") {
+				continue
+			}
+			t, err := time.Parse(time.RFC3339Nano, s)
+			if err != nil {
+				return nil, fmt.Errorf("could not parse timestamp %s: %v", s, err)
+			}
+			values[i] = t
+		}
+		result.Rows = append(result.Rows, query.NormalizeRow(cols, values))
+	}
+	return result, nil
+}
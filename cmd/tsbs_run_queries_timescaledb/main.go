@@ -6,6 +6,7 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
@@ -174,8 +175,9 @@ type queryExecutorOptions struct {
 }
 
 type processor struct {
-	db   *sql.DB
-	opts *queryExecutorOptions
+	db      *sql.DB
+	opts    *queryExecutorOptions
+	timeout time.Duration
 }
 
 func newProcessor() query.Processor { return &processor{} }
@@ -193,21 +195,43 @@ func (p *processor) Init(workerNumber int) {
 	}
 }
 
+// SetQueryTimeout sets the timeout after which queries are cancelled
+func (p *processor) SetQueryTimeout(timeout time.Duration) {
+	p.timeout = timeout
+}
+
 func (p *processor) ProcessQuery(q query.Query, isWarm bool) ([]*query.Stat, error) {
+	stats, _, err := p.processQuery(q, isWarm, false)
+	return stats, err
+}
+
+// ProcessQueryResult runs the query like ProcessQuery and also returns its normalized result
+func (p *processor) ProcessQueryResult(q query.Query, isWarm bool) ([]*query.Stat, *query.Result, error) {
+	return p.processQuery(q, isWarm, true)
+}
+
+func (p *processor) processQuery(q query.Query, isWarm bool, withResult bool) ([]*query.Stat, *query.Result, error) {
 	// No need to run again for EXPLAIN
 	if isWarm && p.opts.showExplain {
-		return nil, nil
+		return nil, nil, nil
 	}
 	tq := q.(*query.TimescaleDB)
 
+	var result *query.Result
 	start := time.Now()
 	qry := string(tq.SqlQuery)
 	if showExplain {
 		qry = "EXPLAIN ANALYZE " + qry
 	}
-	rows, err := p.db.Query(qry)
+	ctx := context.Background()
+	if p.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.timeout)
+		defer cancel()
+	}
+	rows, err := p.db.QueryContext(ctx, qry)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	if p.opts.debug {
@@ -223,6 +247,12 @@ func (p *processor) ProcessQuery(q query.Query, isWarm bool) ([]*query.Stat, err
 			text += s + "\n"
 		}
 		fmt.Printf("%s\n\n%s\n-----\n\n", qry, text)
+	} else if withResult {
+		result, err = query.ReadSQLResult(rows)
+		if err != nil {
+			rows.Close()
+			return nil, nil, err
+		}
 	} else if p.opts.printResponse {
 		prettyPrintResponse(rows, tq)
 	}
@@ -231,11 +261,11 @@ func (p *processor) ProcessQuery(q query.Query, isWarm bool) ([]*query.Stat, err
 	}
 	rows.Close()
 	if err := rows.Err(); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	took := float64(time.Since(start).Nanoseconds()) / 1e6
 	stat := query.GetStat()
 	stat.Init(q.HumanLabelName(), took)
 
-	return []*query.Stat{stat}, err
+	return []*query.Stat{stat}, result, err
 }
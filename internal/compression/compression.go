@@ -0,0 +1,101 @@
+// Package compression reads and writes data and query files compressed with
+// gzip, zstd or lz4, so that large generated datasets do not have to be piped
+// through external decompressors.
+package compression
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4"
+)
+
+// Supported compression formats
+const (
+	None = "none"
+	Gzip = "gzip"
+	Zstd = "zstd"
+	LZ4  = "lz4"
+)
+
+// Formats are the names of the supported compression formats
+var Formats = []string{None, Gzip, Zstd, LZ4}
+
+var (
+	magicBytes = map[string][]byte{
+		Gzip: {0x1f, 0x8b},
+		Zstd: {0x28, 0xb5, 0x2f, 0xfd},
+		LZ4:  {0x04, 0x22, 0x4d, 0x18},
+	}
+	extensions = map[string]string{
+		".gz":   Gzip,
+		".gzip": Gzip,
+		".zst":  Zstd,
+		".zstd": Zstd,
+		".lz4":  LZ4,
+	}
+)
+
+// FromFileName returns the compression format of a file by its extension
+func FromFileName(fileName string) string {
+	if format, ok := extensions[filepath.Ext(fileName)]; ok {
+		return format
+	}
+	return None
+}
+
+// Detect returns the compression format of the data in br by its magic bytes,
+// or by the extension of fileName if the data starts with none of them
+func Detect(br *bufio.Reader, fileName string) string {
+	for format, magic := range magicBytes {
+		if b, err := br.Peek(len(magic)); err == nil && bytes.Equal(b, magic) {
+			return format
+		}
+	}
+	return FromFileName(fileName)
+}
+
+// NewReader returns a reader of the decompressed data in br, with the
+// compression format detected by Detect. It returns br itself if the data
+// is not compressed.
+func NewReader(br *bufio.Reader, fileName string) (io.Reader, error) {
+	switch format := Detect(br, fileName); format {
+	case Gzip:
+		r, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read gzip data: %v", err)
+		}
+		return r, nil
+	case Zstd:
+		d, err := zstd.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read zstd data: %v", err)
+		}
+		return d.IOReadCloser(), nil
+	case LZ4:
+		return lz4.NewReader(br), nil
+	}
+	return br, nil
+}
+
+// NewWriter returns a writer that compresses the data written to it into w,
+// in the given format. Closing it writes the end of the compressed data,
+// without closing w. It returns nil for the format None.
+func NewWriter(w io.Writer, format string) (io.WriteCloser, error) {
+	switch format {
+	case None:
+		return nil, nil
+	case Gzip:
+		return gzip.NewWriter(w), nil
+	case Zstd:
+		return zstd.NewWriter(w)
+	case LZ4:
+		return lz4.NewWriter(w), nil
+	}
+	return nil, fmt.Errorf("unknown compression format: '%s'", format)
+}
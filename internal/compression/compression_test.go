@@ -0,0 +1,78 @@
+package compression
+
+import (
+	"bufio"
+	"bytes"
+	"io/ioutil"
+	"testing"
+)
+
+func TestRoundTrip(t *testing.T) {
+	want := bytes.Repeat([]byte("cpu,hostname=host_0 usage_user=58i 1451606400000000000\n"), 1000)
+	for _, format := range []string{Gzip, Zstd, LZ4} {
+		var buf bytes.Buffer
+		w, err := NewWriter(&buf, format)
+		if err != nil {
+			t.Fatalf("%s: cannot create writer: %v", format, err)
+		}
+		if _, err = w.Write(want); err != nil {
+			t.Fatalf("%s: cannot write: %v", format, err)
+		}
+		if err = w.Close(); err != nil {
+			t.Fatalf("%s: cannot close: %v", format, err)
+		}
+		if buf.Len() >= len(want) {
+			t.Errorf("%s: data not compressed: %d bytes of %d", format, buf.Len(), len(want))
+		}
+
+		br := bufio.NewReader(&buf)
+		// detected by the magic bytes, not by the file name
+		if got := Detect(br, "data.txt"); got != format {
+			t.Errorf("%s: incorrect format detected: got %s", format, got)
+		}
+		r, err := NewReader(br, "")
+		if err != nil {
+			t.Fatalf("%s: cannot create reader: %v", format, err)
+		}
+		got, err := ioutil.ReadAll(r)
+		if err != nil {
+			t.Fatalf("%s: cannot read: %v", format, err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("%s: incorrect data read: got %d bytes want %d", format, len(got), len(want))
+		}
+	}
+}
+
+func TestNotCompressed(t *testing.T) {
+	br := bufio.NewReader(bytes.NewReader([]byte("plain data")))
+	r, err := NewReader(br, "data.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r != br {
+		t.Errorf("uncompressed data not read as is")
+	}
+	if w, err := NewWriter(&bytes.Buffer{}, None); w != nil || err != nil {
+		t.Errorf("unexpected writer for no compression: got %v, %v", w, err)
+	}
+	if _, err := NewWriter(&bytes.Buffer{}, "rar"); err == nil {
+		t.Errorf("unexpected lack of error for unknown format")
+	}
+}
+
+func TestFromFileName(t *testing.T) {
+	cases := map[string]string{
+		"/tmp/data.gz":      Gzip,
+		"/tmp/data.zst":     Zstd,
+		"/tmp/queries.lz4":  LZ4,
+		"/tmp/data":         None,
+		"":                  None,
+		"/tmp/data.gz.json": None,
+	}
+	for fileName, want := range cases {
+		if got := FromFileName(fileName); got != want {
+			t.Errorf("%q: incorrect format: got %s want %s", fileName, got, want)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package coordinator
+
+import (
+	"fmt"
+	"net/rpc"
+	"os"
+)
+
+// Agent is the connection of an agent to its coordinator
+type Agent struct {
+	Assignment
+	client *rpc.Client
+}
+
+// Dial registers an agent of the given kind with the coordinator at addr
+func Dial(addr, kind string) (*Agent, error) {
+	client, err := rpc.Dial("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("cannot connect to coordinator %s: %v", addr, err)
+	}
+	host, _ := os.Hostname()
+	a := &Agent{client: client}
+	if err := client.Call(serviceName+".Register", &RegisterArgs{Kind: kind, Host: host}, &a.Assignment); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("cannot register with coordinator %s: %v", addr, err)
+	}
+	return a, nil
+}
+
+// Wait blocks until all agents arrived at the barrier name
+func (a *Agent) Wait(name string) error {
+	return a.client.Call(serviceName+".Wait", &BarrierArgs{Agent: a.Agent, Name: name}, &struct{}{})
+}
+
+// Done sends the result of the agent to the coordinator and disconnects
+func (a *Agent) Done(r *Result) error {
+	r.Agent = a.Agent
+	err := a.client.Call(serviceName+".Done", r, &struct{}{})
+	a.client.Close()
+	return err
+}
+
+// InShard returns whether the n-th item of the data or queries is in the shard of the agent
+func (a *Agent) InShard(n uint64) bool {
+	return n%uint64(a.Agents) == uint64(a.Agent)
+}
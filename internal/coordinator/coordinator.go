@@ -0,0 +1,248 @@
+// Package coordinator runs a benchmark on several clients at once. The
+// clients, the agents, register with a coordinator that assigns each one a
+// shard of the data or queries, lets only one of them create the database,
+// starts them at the same time and merges their results.
+package coordinator
+
+import (
+	"fmt"
+	"net"
+	"net/rpc"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/HdrHistogram/hdrhistogram-go"
+)
+
+const (
+	// KindLoad is the kind of the agents of a load benchmark
+	KindLoad = "load"
+	// KindQuery is the kind of the agents of a query benchmark
+	KindQuery = "query"
+
+	// BarrierCreated is passed once the database is created
+	BarrierCreated = "created"
+	// BarrierStart is passed once all agents are ready to start the benchmark
+	BarrierStart = "start"
+
+	serviceName = "Coordinator"
+)
+
+// RegisterArgs are the arguments of an agent registering with the coordinator
+type RegisterArgs struct {
+	Kind string
+	Host string
+}
+
+// Assignment is what the coordinator assigns to a registered agent
+type Assignment struct {
+	// Agent is the number of the agent, which is also the number of its shard
+	Agent int
+	// Agents is the number of agents, and of shards
+	Agents int
+	// CreateDB is only true for the one agent that creates the database
+	CreateDB bool
+}
+
+// BarrierArgs are the arguments of an agent waiting at a barrier
+type BarrierArgs struct {
+	Agent int
+	Name  string
+}
+
+// Result is the result of the run of one agent. Counts are summed over all
+// agents, and Histograms of latencies in microseconds are merged by label.
+type Result struct {
+	Agent      int
+	Start      time.Time
+	End        time.Time
+	Counts     map[string]uint64
+	Histograms map[string]*hdrhistogram.Snapshot
+}
+
+// Coordinator waits for a fixed number of agents and coordinates their run
+type Coordinator struct {
+	agents int
+
+	mu       sync.Mutex
+	kind     string
+	hosts    []string
+	barriers map[string]*barrier
+	results  []*Result
+	done     chan struct{}
+	// failed is closed with err set once the run failed, e.g. timed out
+	failed chan struct{}
+	err    error
+}
+
+type barrier struct {
+	// arrived holds the agents that arrived at the barrier
+	arrived map[int]bool
+	passed  chan struct{}
+}
+
+// New returns a Coordinator for the given number of agents
+func New(agents int) *Coordinator {
+	return &Coordinator{
+		agents:   agents,
+		barriers: make(map[string]*barrier),
+		results:  make([]*Result, agents),
+		done:     make(chan struct{}),
+		failed:   make(chan struct{}),
+	}
+}
+
+// Serve serves the agents connecting on l in the background
+func (c *Coordinator) Serve(l net.Listener) error {
+	server := rpc.NewServer()
+	if err := server.RegisterName(serviceName, &service{c}); err != nil {
+		return err
+	}
+	go server.Accept(l)
+	return nil
+}
+
+// Wait waits until all agents sent their results, and returns the kind of the
+// agents and the results ordered by agent. If timeout is positive and not all
+// results arrived by then, the run fails: the agents waiting at a barrier get
+// an error and the returned error names the agents that are missing.
+func (c *Coordinator) Wait(timeout time.Duration) (string, []*Result, error) {
+	var deadline <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+	select {
+	case <-c.done:
+	case <-deadline:
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.err = fmt.Errorf("run did not finish within %v, still waiting for %s", timeout, c.missing())
+		close(c.failed)
+		return "", nil, c.err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.kind, c.results, nil
+}
+
+// missing describes what the run is waiting for: the agents that did not
+// register, did not arrive at a barrier or did not send their result
+func (c *Coordinator) missing() string {
+	if len(c.hosts) < c.agents {
+		return fmt.Sprintf("%d of %d agents to register", c.agents-len(c.hosts), c.agents)
+	}
+	var names []string
+	for name := range c.barriers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		b := c.barriers[name]
+		if len(b.arrived) < c.agents {
+			return fmt.Sprintf("%s to arrive at barrier %s", c.describeAgents(func(agent int) bool { return b.arrived[agent] }), name)
+		}
+	}
+	return fmt.Sprintf("the results of %s", c.describeAgents(func(agent int) bool { return c.results[agent] != nil }))
+}
+
+// describeAgents lists the agents for which done returns false
+func (c *Coordinator) describeAgents(done func(agent int) bool) string {
+	var agents []string
+	for agent, host := range c.hosts {
+		if !done(agent) {
+			agents = append(agents, fmt.Sprintf("agent %d (%s)", agent, host))
+		}
+	}
+	return strings.Join(agents, ", ")
+}
+
+func (c *Coordinator) register(args *RegisterArgs) (*Assignment, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return nil, c.err
+	}
+	if len(c.hosts) == c.agents {
+		return nil, fmt.Errorf("all %d agents already registered", c.agents)
+	}
+	if c.kind == "" {
+		c.kind = args.Kind
+	} else if c.kind != args.Kind {
+		return nil, fmt.Errorf("cannot register %s agent with %s agents", args.Kind, c.kind)
+	}
+	a := &Assignment{Agent: len(c.hosts), Agents: c.agents, CreateDB: len(c.hosts) == 0}
+	c.hosts = append(c.hosts, args.Host)
+	return a, nil
+}
+
+// wait blocks until all agents arrived at the barrier name, or the run failed
+func (c *Coordinator) wait(agent int, name string) error {
+	c.mu.Lock()
+	b, ok := c.barriers[name]
+	if !ok {
+		b = &barrier{arrived: make(map[int]bool), passed: make(chan struct{})}
+		c.barriers[name] = b
+	}
+	if agent < 0 || agent >= len(c.hosts) || b.arrived[agent] {
+		c.mu.Unlock()
+		return fmt.Errorf("unexpected agent %d at barrier %s", agent, name)
+	}
+	b.arrived[agent] = true
+	if len(b.arrived) == c.agents {
+		close(b.passed)
+	}
+	c.mu.Unlock()
+	select {
+	case <-b.passed:
+		return nil
+	case <-c.failed:
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return c.err
+	}
+}
+
+func (c *Coordinator) finish(r *Result) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return c.err
+	}
+	if r.Agent < 0 || r.Agent >= c.agents || c.results[r.Agent] != nil {
+		return fmt.Errorf("unexpected result of agent %d", r.Agent)
+	}
+	c.results[r.Agent] = r
+	for _, r := range c.results {
+		if r == nil {
+			return nil
+		}
+	}
+	close(c.done)
+	return nil
+}
+
+// service holds the methods called by the agents over net/rpc
+type service struct {
+	c *Coordinator
+}
+
+func (s *service) Register(args *RegisterArgs, reply *Assignment) error {
+	a, err := s.c.register(args)
+	if err != nil {
+		return err
+	}
+	*reply = *a
+	return nil
+}
+
+func (s *service) Wait(args *BarrierArgs, _ *struct{}) error {
+	return s.c.wait(args.Agent, args.Name)
+}
+
+func (s *service) Done(args *Result, _ *struct{}) error {
+	return s.c.finish(args)
+}
@@ -0,0 +1,225 @@
+package coordinator
+
+import (
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/HdrHistogram/hdrhistogram-go"
+)
+
+func startCoordinator(t *testing.T, agents int) (*Coordinator, string) {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("could not listen: %v", err)
+	}
+	c := New(agents)
+	if err := c.Serve(l); err != nil {
+		t.Fatalf("could not serve: %v", err)
+	}
+	return c, l.Addr().String()
+}
+
+func TestCoordinatorRun(t *testing.T) {
+	const agents = 3
+	c, addr := startCoordinator(t, agents)
+
+	var mu sync.Mutex
+	var creators int
+	shards := make(map[int]bool)
+	started := make(chan struct{}, agents)
+	var wg sync.WaitGroup
+	for i := 0; i < agents; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			a, err := Dial(addr, KindLoad)
+			if err != nil {
+				t.Errorf("could not dial: %v", err)
+				return
+			}
+			mu.Lock()
+			shards[a.Agent] = true
+			if a.CreateDB {
+				creators++
+			}
+			mu.Unlock()
+			if a.Agents != agents {
+				t.Errorf("incorrect number of agents: got %d want %d", a.Agents, agents)
+			}
+			if err := a.Wait(BarrierStart); err != nil {
+				t.Errorf("could not wait: %v", err)
+			}
+			started <- struct{}{}
+			if err := a.Done(&Result{Counts: map[string]uint64{"metrics": 10}}); err != nil {
+				t.Errorf("could not send result: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(shards) != agents || creators != 1 {
+		t.Errorf("incorrect assignments: got shards %v and %d creators", shards, creators)
+	}
+	if len(started) != agents {
+		t.Errorf("not all agents passed the barrier: got %d", len(started))
+	}
+	kind, results, err := c.Wait(time.Minute)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if kind != KindLoad || len(results) != agents {
+		t.Fatalf("incorrect results: got %d %s results", len(results), kind)
+	}
+	for i, r := range results {
+		if r.Agent != i || r.Counts["metrics"] != 10 {
+			t.Errorf("incorrect result %d: %+v", i, r)
+		}
+	}
+}
+
+func TestCoordinatorRegisterErrors(t *testing.T) {
+	_, addr := startCoordinator(t, 1)
+	if _, err := Dial(addr, KindQuery); err != nil {
+		t.Fatalf("could not dial: %v", err)
+	}
+	if _, err := Dial(addr, KindQuery); err == nil {
+		t.Errorf("expected an error registering too many agents")
+	}
+
+	c := New(2)
+	if _, err := c.register(&RegisterArgs{Kind: KindLoad}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := c.register(&RegisterArgs{Kind: KindQuery}); err == nil {
+		t.Errorf("expected an error registering agents of different kinds")
+	}
+	if err := c.finish(&Result{Agent: 2}); err == nil {
+		t.Errorf("expected an error for the result of an unknown agent")
+	}
+}
+
+func TestCoordinatorTimeout(t *testing.T) {
+	const timeout = 50 * time.Millisecond
+	c, addr := startCoordinator(t, 2)
+	a, err := Dial(addr, KindLoad)
+	if err != nil {
+		t.Fatalf("could not dial: %v", err)
+	}
+	_, _, err = c.Wait(timeout)
+	if want := "run did not finish within 50ms, still waiting for 1 of 2 agents to register"; err == nil || err.Error() != want {
+		t.Fatalf("incorrect error before all agents registered: got %v want %s", err, want)
+	}
+	if err := a.Wait(BarrierStart); err == nil {
+		t.Errorf("unexpected lack of error at the barrier of a failed run")
+	}
+
+	c, addr = startCoordinator(t, 2)
+	var agents []*Agent
+	for i := 0; i < 2; i++ {
+		a, err := Dial(addr, KindLoad)
+		if err != nil {
+			t.Fatalf("could not dial: %v", err)
+		}
+		agents = append(agents, a)
+	}
+	waitErr := make(chan error)
+	go func() { waitErr <- agents[1].Wait(BarrierStart) }()
+	for arrived := false; !arrived; time.Sleep(time.Millisecond) {
+		c.mu.Lock()
+		arrived = c.barriers[BarrierStart] != nil
+		c.mu.Unlock()
+	}
+	_, _, err = c.Wait(timeout)
+	want := "still waiting for agent 0 (" + hostname(t) + ") to arrive at barrier start"
+	if err == nil || !strings.HasSuffix(err.Error(), want) {
+		t.Fatalf("incorrect error at the barrier: got %v want suffix %s", err, want)
+	}
+	if agentErr := <-waitErr; agentErr == nil || agentErr.Error() != err.Error() {
+		t.Errorf("incorrect error of the waiting agent: got %v want %v", agentErr, err)
+	}
+}
+
+func hostname(t *testing.T) string {
+	t.Helper()
+	host, err := os.Hostname()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return host
+}
+
+func TestAgentInShard(t *testing.T) {
+	a := &Agent{Assignment: Assignment{Agent: 1, Agents: 3}}
+	var got []uint64
+	for n := uint64(0); n < 9; n++ {
+		if a.InShard(n) {
+			got = append(got, n)
+		}
+	}
+	if len(got) != 3 || got[0] != 1 || got[1] != 4 || got[2] != 7 {
+		t.Errorf("incorrect shard: got %v", got)
+	}
+}
+
+func histogram(values ...int64) *hdrhistogram.Snapshot {
+	h := hdrhistogram.New(1, 3600000000, 3)
+	for _, v := range values {
+		h.RecordValue(v)
+	}
+	return h.Export()
+}
+
+func TestCombineLoad(t *testing.T) {
+	start := time.Unix(100, 0)
+	results := []*Result{
+		{Agent: 0, Start: start, End: start.Add(8 * time.Second),
+			Counts:     map[string]uint64{"metrics": 1000, "rows": 100},
+			Histograms: map[string]*hdrhistogram.Snapshot{labelAll: histogram(1000, 2000)}},
+		{Agent: 1, Start: start.Add(time.Second), End: start.Add(10 * time.Second),
+			Counts:     map[string]uint64{"metrics": 1000, "rows": 100},
+			Histograms: map[string]*hdrhistogram.Snapshot{labelAll: histogram(4000, 8000)}},
+	}
+	c := Combine(KindLoad, results)
+	if c.DurationMillis != 10000 || c.Agents != 2 {
+		t.Errorf("incorrect run: got %d ms with %d agents", c.DurationMillis, c.Agents)
+	}
+	if c.Totals["metricRate"] != 200.0 || c.Totals["rowRate"] != 20.0 {
+		t.Errorf("incorrect rates: got %v and %v", c.Totals["metricRate"], c.Totals["rowRate"])
+	}
+	latencies := c.Totals["batchLatencyQuantiles"].(map[string]interface{})
+	if len(latencies) != 3 {
+		t.Errorf("incorrect number of latencies: got %d want 3", len(latencies))
+	}
+	all := latencies[labelAll].(map[string]float64)
+	if all["q50"] < 1.99 || all["q50"] > 2.01 || all["q100"] < 7.99 || all["q100"] > 8.01 {
+		t.Errorf("incorrect merged latencies: got %v", all)
+	}
+}
+
+func TestCombineQuery(t *testing.T) {
+	start := time.Unix(100, 0)
+	results := []*Result{
+		{Agent: 0, Start: start, End: start.Add(2 * time.Second),
+			Counts:     map[string]uint64{"q": 1},
+			Histograms: map[string]*hdrhistogram.Snapshot{"q": histogram(1000, 3000)}},
+		{Agent: 1, Start: start, End: start.Add(2 * time.Second),
+			Histograms: map[string]*hdrhistogram.Snapshot{"q": histogram(2000, 4000)}},
+	}
+	c := Combine(KindQuery, results)
+	rates := c.Totals["overallQueryRates"].(map[string]interface{})
+	if rates["q"] != 2.0 {
+		t.Errorf("incorrect query rate: got %v want 2", rates["q"])
+	}
+	q := c.Totals["overallQuantiles"].(map[string]interface{})["q"].(map[string]float64)
+	if q["q50"] < 1.99 || q["q50"] > 2.01 {
+		t.Errorf("incorrect median: got %v want 2", q["q50"])
+	}
+	if failed := c.Totals["failedQueries"].(map[string]interface{}); failed["q"] != uint64(1) {
+		t.Errorf("incorrect failed queries: got %v", failed)
+	}
+}
@@ -0,0 +1,179 @@
+package coordinator
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/HdrHistogram/hdrhistogram-go"
+)
+
+// CombinedResultVersion is the version of the format of the combined results
+const CombinedResultVersion = "0.1"
+
+// labelAll is the label of the latencies of all batches of a load agent
+const labelAll = "all"
+
+// CombinedResult is the merged result of all agents. The Totals are in the
+// format of the results files of the loaders and query runners, so that they
+// can be compared with tsbs_compare.
+type CombinedResult struct {
+	ResultFormatVersion string                 `json:"ResultFormatVersion"`
+	Kind                string                 `json:"Kind"`
+	Agents              int                    `json:"Agents"`
+	StartTime           int64                  `json:"StartTime"`
+	EndTime             int64                  `json:"EndTime"`
+	DurationMillis      int64                  `json:"DurationMillis"`
+	Totals              map[string]interface{} `json:"Totals"`
+}
+
+// Combine merges the results of the agents of kind. The run lasts from the
+// earliest start to the latest end of the agents.
+func Combine(kind string, results []*Result) *CombinedResult {
+	start, end := results[0].Start, results[0].End
+	counts := make(map[string]uint64)
+	histograms := make(map[string]*hdrhistogram.Histogram)
+	for _, r := range results {
+		if r.Start.Before(start) {
+			start = r.Start
+		}
+		if r.End.After(end) {
+			end = r.End
+		}
+		for k, v := range r.Counts {
+			counts[k] += v
+		}
+		for label, s := range r.Histograms {
+			h := hdrhistogram.Import(s)
+			if merged, ok := histograms[label]; ok {
+				merged.Merge(h)
+			} else {
+				histograms[label] = h
+			}
+		}
+	}
+	took := end.Sub(start)
+
+	c := &CombinedResult{
+		ResultFormatVersion: CombinedResultVersion,
+		Kind:                kind,
+		Agents:              len(results),
+		StartTime:           start.UnixNano() / int64(time.Millisecond),
+		EndTime:             end.UnixNano() / int64(time.Millisecond),
+		DurationMillis:      took.Milliseconds(),
+	}
+	if kind == KindLoad {
+		c.Totals = loadTotals(results, counts, histograms, took)
+	} else {
+		c.Totals = queryTotals(counts, histograms, took)
+	}
+	return c
+}
+
+// loadTotals returns the rates of all agents, and the batch latencies of all
+// agents under "all" and of each agent under "agent<n>"
+func loadTotals(results []*Result, counts map[string]uint64, histograms map[string]*hdrhistogram.Histogram, took time.Duration) map[string]interface{} {
+	totals := map[string]interface{}{
+		"metricRate": float64(counts["metrics"]) / took.Seconds(),
+	}
+	if counts["rows"] > 0 {
+		totals["rowRate"] = float64(counts["rows"]) / took.Seconds()
+	}
+	if all, ok := histograms[labelAll]; ok {
+		latencies := map[string]interface{}{labelAll: quantiles(all)}
+		for _, r := range results {
+			if s, ok := r.Histograms[labelAll]; ok {
+				latencies[fmt.Sprintf("agent%d", r.Agent)] = quantiles(hdrhistogram.Import(s))
+			}
+		}
+		totals["batchLatencyQuantiles"] = latencies
+	}
+	return totals
+}
+
+// queryTotals returns the query rates and latencies by label of all agents,
+// and the failed queries, which the agents count by label
+func queryTotals(counts map[string]uint64, histograms map[string]*hdrhistogram.Histogram, took time.Duration) map[string]interface{} {
+	rates := make(map[string]interface{})
+	latencies := make(map[string]interface{})
+	for label, h := range histograms {
+		rates[label] = float64(h.TotalCount()) / took.Seconds()
+		latencies[label] = quantiles(h)
+	}
+	failed := make(map[string]interface{})
+	for label, n := range counts {
+		failed[label] = n
+	}
+	return map[string]interface{}{
+		"overallQueryRates": rates,
+		"overallQuantiles":  latencies,
+		"failedQueries":     failed,
+	}
+}
+
+// quantiles returns the quantiles of a histogram in microseconds as milliseconds
+func quantiles(h *hdrhistogram.Histogram) map[string]float64 {
+	return map[string]float64{
+		"q0":   float64(h.ValueAtQuantile(0)) / 1e3,
+		"q50":  float64(h.ValueAtQuantile(50)) / 1e3,
+		"q95":  float64(h.ValueAtQuantile(95)) / 1e3,
+		"q99":  float64(h.ValueAtQuantile(99)) / 1e3,
+		"q999": float64(h.ValueAtQuantile(99.9)) / 1e3,
+		"q100": float64(h.ValueAtQuantile(100)) / 1e3,
+	}
+}
+
+// WriteSummary writes the rates and median latencies of the combined result
+func (c *CombinedResult) WriteSummary(w io.Writer) error {
+	took := time.Duration(c.DurationMillis) * time.Millisecond
+	if _, err := fmt.Fprintf(w, "Combined %s results of %d agents in %0.3fsec:\n", c.Kind, c.Agents, took.Seconds()); err != nil {
+		return err
+	}
+	if c.Kind == KindLoad {
+		for _, m := range []string{"metricRate", "rowRate"} {
+			if v, ok := c.Totals[m]; ok {
+				if _, err := fmt.Fprintf(w, "%s: %0.2f/sec\n", m, v); err != nil {
+					return err
+				}
+			}
+		}
+		if latencies, ok := c.Totals["batchLatencyQuantiles"].(map[string]interface{}); ok {
+			return writeQuantiles(w, latencies)
+		}
+		return nil
+	}
+	if _, err := fmt.Fprintln(w, "query rates (queries/sec):"); err != nil {
+		return err
+	}
+	rates := c.Totals["overallQueryRates"].(map[string]interface{})
+	for _, label := range sortedLabels(rates) {
+		if _, err := fmt.Fprintf(w, "%s: %0.2f\n", label, rates[label]); err != nil {
+			return err
+		}
+	}
+	return writeQuantiles(w, c.Totals["overallQuantiles"].(map[string]interface{}))
+}
+
+func writeQuantiles(w io.Writer, latencies map[string]interface{}) error {
+	if _, err := fmt.Fprintln(w, "latencies:"); err != nil {
+		return err
+	}
+	for _, label := range sortedLabels(latencies) {
+		q := latencies[label].(map[string]float64)
+		_, err := fmt.Fprintf(w, "%s: med: %0.2fms, p95: %0.2fms, p99: %0.2fms, max: %0.2fms\n", label, q["q50"], q["q95"], q["q99"], q["q100"])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func sortedLabels(m map[string]interface{}) []string {
+	labels := make([]string, 0, len(m))
+	for label := range m {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	return labels
+}
@@ -36,6 +36,8 @@ type DataGenerator struct {
 	// bufOut represents the buffered writer that should actually be passed to
 	// any operations that write out data.
 	bufOut *bufio.Writer
+	// compressor is the compressor of bufOut, nil unless the output is compressed
+	compressor io.Closer
 }
 
 func (g *DataGenerator) init(config common.GeneratorConfig) error {
@@ -57,7 +59,7 @@ func (g *DataGenerator) init(config common.GeneratorConfig) error {
 	if g.Out == nil {
 		g.Out = os.Stdout
 	}
-	g.bufOut, err = getBufferedWriter(g.config.File, g.Out)
+	g.bufOut, g.compressor, err = getBufferedWriter(g.config.File, g.config.Compress, g.Out)
 	if err != nil {
 		return err
 	}
@@ -84,7 +86,16 @@ func (g *DataGenerator) Generate(config common.GeneratorConfig, target targets.I
 		return err
 	}
 
-	return g.runSimulator(sim, serializer, g.config)
+	if g.config.Workers > 1 {
+		err = g.runSimulatorParallel(sim, target.Serializer, g.config)
+	} else {
+		err = g.runSimulator(sim, serializer, g.config)
+	}
+	if err != nil {
+		return err
+	}
+	reportSeries(os.Stderr, sim)
+	return nil
 }
 
 func (g *DataGenerator) CreateSimulator(config *common.DataGeneratorConfig) (common.Simulator, error) {
@@ -98,12 +109,14 @@ func (g *DataGenerator) CreateSimulator(config *common.DataGeneratorConfig) (com
 		return nil, err
 	}
 
-	return scfg.NewSimulator(g.config.LogInterval, g.config.Limit), nil
+	sim := scfg.NewSimulator(g.config.LogInterval, g.config.Limit)
+	if g.config.RealTime {
+		return newRealTimeSimulator(sim), nil
+	}
+	return sim, nil
 }
 
 func (g *DataGenerator) runSimulator(sim common.Simulator, serializer serialize.PointSerializer, dgc *common.DataGeneratorConfig) error {
-	defer g.bufOut.Flush()
-
 	currGroupID := uint(0)
 	point := data.NewPoint()
 	for !sim.Finished() {
@@ -124,33 +137,49 @@ func (g *DataGenerator) runSimulator(sim common.Simulator, serializer serialize.
 
 		currGroupID = (currGroupID + 1) % dgc.InterleavedNumGroups
 	}
-	return nil
+	return flushOutput(g.bufOut, g.compressor)
+}
+
+// reportSeries writes the number of unique series generated by sim to w, if
+// sim counts them.
+func reportSeries(w io.Writer, sim common.Simulator) {
+	sc, ok := sim.(common.SeriesCounter)
+	if !ok {
+		return
+	}
+	tagSets, series := sc.SeriesCount()
+	fmt.Fprintf(w, "generated %d unique series (%d unique tag sets)\n", series, tagSets)
 }
 
 func (g *DataGenerator) getSerializer(sim common.Simulator, target targets.ImplementedTarget) (serialize.PointSerializer, error) {
-	switch target.TargetName() {
-	case constants.FormatCrateDB:
-		fallthrough
-	case constants.FormatClickhouse:
-		fallthrough
-	case constants.FormatTimescaleDB:
-		g.writeHeader(sim.Headers())
+	if needsHeader(target.TargetName()) {
+		writeHeader(g.bufOut, sim.Headers())
 	}
 	return target.Serializer(), nil
 }
 
+// needsHeader returns whether the data for the target format starts with a
+// header describing the tags and fields of the generated data
+func needsHeader(targetName string) bool {
+	switch targetName {
+	case constants.FormatCrateDB, constants.FormatClickhouse, constants.FormatTimescaleDB:
+		return true
+	}
+	return false
+}
+
 //TODO should be implemented in targets package
-func (g *DataGenerator) writeHeader(headers *common.GeneratedDataHeaders) {
-	g.bufOut.WriteString("tags")
+func writeHeader(w io.Writer, headers *common.GeneratedDataHeaders) {
+	io.WriteString(w, "tags")
 
 	types := headers.TagTypes
 	for i, key := range headers.TagKeys {
-		g.bufOut.WriteString(",")
-		g.bufOut.Write([]byte(key))
-		g.bufOut.WriteString(" ")
-		g.bufOut.WriteString(types[i])
+		io.WriteString(w, ",")
+		io.WriteString(w, key)
+		io.WriteString(w, " ")
+		io.WriteString(w, types[i])
 	}
-	g.bufOut.WriteString("\n")
+	io.WriteString(w, "\n")
 	// sort the keys so the header is deterministic
 	keys := make([]string, 0)
 	fields := headers.FieldKeys
@@ -159,12 +188,12 @@ func (g *DataGenerator) writeHeader(headers *common.GeneratedDataHeaders) {
 	}
 	sort.Strings(keys)
 	for _, measurementName := range keys {
-		g.bufOut.WriteString(measurementName)
+		io.WriteString(w, measurementName)
 		for _, field := range fields[measurementName] {
-			g.bufOut.WriteString(",")
-			g.bufOut.Write([]byte(field))
+			io.WriteString(w, ",")
+			io.WriteString(w, field)
 		}
-		g.bufOut.WriteString("\n")
+		io.WriteString(w, "\n")
 	}
-	g.bufOut.WriteString("\n")
+	io.WriteString(w, "\n")
 }
@@ -0,0 +1,144 @@
+package inputs
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/data/serialize"
+	"github.com/timescale/tsbs/pkg/data/usecases/common"
+)
+
+// pointBatchSize is the number of points serialized by a worker at a time
+const pointBatchSize = 1000
+
+// pointBatch is a batch of simulated points and their serialized output
+type pointBatch struct {
+	points []*data.Point
+	// times holds the timestamps of the points, since the simulators point
+	// them to timestamps that change at the next tick
+	times []time.Time
+	n     int
+
+	out bytes.Buffer
+	err error
+	// serialized is closed once out holds the serialized points or err is set
+	serialized chan struct{}
+}
+
+func newPointBatch() *pointBatch {
+	b := &pointBatch{
+		points: make([]*data.Point, pointBatchSize),
+		times:  make([]time.Time, pointBatchSize),
+	}
+	for i := range b.points {
+		b.points[i] = data.NewPoint()
+	}
+	return b
+}
+
+// keep keeps the point last filled by the simulator in the batch
+func (b *pointBatch) keep() {
+	p := b.points[b.n]
+	if ts := p.Timestamp(); ts != nil {
+		b.times[b.n] = *ts
+		p.SetTimestamp(&b.times[b.n])
+	}
+	b.n++
+}
+
+// serialize writes the points of the batch to out with s, and resets them
+func (b *pointBatch) serialize(s serialize.PointSerializer) {
+	b.out.Reset()
+	for _, p := range b.points[:b.n] {
+		if b.err == nil {
+			b.err = s.Serialize(p, &b.out)
+		}
+		p.Reset()
+	}
+	close(b.serialized)
+}
+
+// runSimulatorParallel writes the points of sim like runSimulator, but
+// serializes them with dgc.Workers serializers made by newSerializer.
+//
+// The simulators are not safe to share between goroutines and draw from the
+// global PRNG, so the points are still simulated in order by one goroutine.
+// Batches of points are serialized by the workers and written in the order
+// they were simulated, so the output is the same as runSimulator's.
+func (g *DataGenerator) runSimulatorParallel(sim common.Simulator, newSerializer func() serialize.PointSerializer, dgc *common.DataGeneratorConfig) error {
+	// the batches are reused once written, so at most maxBatches are in flight
+	maxBatches := 2 * int(dgc.Workers)
+	free := make(chan *pointBatch, maxBatches)
+	for i := 0; i < maxBatches; i++ {
+		free <- newPointBatch()
+	}
+	toSerialize := make(chan *pointBatch, maxBatches)
+	toWrite := make(chan *pointBatch, maxBatches)
+	stop := make(chan struct{})
+
+	for i := uint(0); i < dgc.Workers; i++ {
+		go func(s serialize.PointSerializer) {
+			for b := range toSerialize {
+				b.serialize(s)
+			}
+		}(newSerializer())
+	}
+
+	go func() {
+		defer close(toWrite)
+		defer close(toSerialize)
+
+		currGroupID := uint(0)
+		for !sim.Finished() {
+			var b *pointBatch
+			select {
+			case b = <-free:
+			case <-stop:
+				return
+			}
+			b.n = 0
+			b.err = nil
+			b.serialized = make(chan struct{})
+			for b.n < len(b.points) && !sim.Finished() {
+				write := sim.Next(b.points[b.n])
+				if !write {
+					b.points[b.n].Reset()
+					continue
+				}
+
+				// in the default case this is always true
+				if currGroupID == dgc.InterleavedGroupID {
+					b.keep()
+				} else {
+					b.points[b.n].Reset()
+				}
+
+				currGroupID = (currGroupID + 1) % dgc.InterleavedNumGroups
+			}
+			toWrite <- b
+			toSerialize <- b
+		}
+	}()
+
+	var err error
+	for b := range toWrite {
+		<-b.serialized
+		if err == nil {
+			if b.err != nil {
+				err = fmt.Errorf("can not serialize point: %s", b.err)
+			} else if _, writeErr := g.bufOut.Write(b.out.Bytes()); writeErr != nil {
+				err = fmt.Errorf("can not write points: %s", writeErr)
+			}
+			if err != nil {
+				close(stop)
+			}
+		}
+		free <- b
+	}
+	if err != nil {
+		return err
+	}
+	return flushOutput(g.bufOut, g.compressor)
+}
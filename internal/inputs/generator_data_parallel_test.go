@@ -0,0 +1,191 @@
+package inputs
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"math/rand"
+	"testing"
+
+	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/data/serialize"
+	"github.com/timescale/tsbs/pkg/data/usecases"
+	"github.com/timescale/tsbs/pkg/data/usecases/common"
+	"github.com/timescale/tsbs/pkg/targets/constants"
+)
+
+// fullSerializer writes every part of a point
+type fullSerializer struct{}
+
+func (s *fullSerializer) Serialize(p *data.Point, w io.Writer) error {
+	_, err := fmt.Fprintf(w, "%s %v %v %v %v %d\n", p.MeasurementName(), p.TagKeys(), p.TagValues(),
+		p.FieldKeys(), p.FieldValues(), p.Timestamp().UnixNano())
+	return err
+}
+
+func TestRunSimulatorParallel(t *testing.T) {
+	cases := []struct {
+		desc             string
+		limit            uint64
+		shouldWriteLimit uint64
+		groupID          uint
+		totalGroups      uint
+		workers          uint
+		shouldError      bool
+	}{
+		{
+			desc:             "less than a batch",
+			limit:            10,
+			shouldWriteLimit: 10,
+			totalGroups:      1,
+			workers:          2,
+		},
+		{
+			desc:             "more batches than workers",
+			limit:            10 * pointBatchSize,
+			shouldWriteLimit: 10 * pointBatchSize,
+			totalGroups:      1,
+			workers:          3,
+		},
+		{
+			desc:             "shouldWriteLimit < limit",
+			limit:            3 * pointBatchSize,
+			shouldWriteLimit: pointBatchSize + 5,
+			totalGroups:      1,
+			workers:          2,
+		},
+		{
+			desc:             "totalGroups=3, other group",
+			limit:            5 * pointBatchSize,
+			shouldWriteLimit: 5 * pointBatchSize,
+			groupID:          1,
+			totalGroups:      3,
+			workers:          4,
+		},
+		{
+			desc:             "should error in serializer",
+			limit:            10 * pointBatchSize,
+			shouldWriteLimit: 10 * pointBatchSize,
+			totalGroups:      1,
+			workers:          2,
+			shouldError:      true,
+		},
+	}
+	for _, c := range cases {
+		dgc := &common.DataGeneratorConfig{
+			BaseConfig: common.BaseConfig{
+				Scale: 1,
+			},
+			Limit:                c.limit,
+			InitialScale:         1,
+			LogInterval:          defaultLogInterval,
+			InterleavedGroupID:   c.groupID,
+			InterleavedNumGroups: c.totalGroups,
+			Workers:              c.workers,
+		}
+		run := func(parallel bool) ([]byte, error) {
+			var buf bytes.Buffer
+			g := &DataGenerator{
+				config: dgc,
+				bufOut: bufio.NewWriter(&buf),
+			}
+			sim := &testSimulator{
+				limit:            c.limit,
+				shouldWriteLimit: c.shouldWriteLimit,
+			}
+			newSerializer := func() serialize.PointSerializer {
+				return &testSerializer{shouldError: c.shouldError}
+			}
+			var err error
+			if parallel {
+				err = g.runSimulatorParallel(sim, newSerializer, dgc)
+			} else {
+				err = g.runSimulator(sim, newSerializer(), dgc)
+			}
+			return buf.Bytes(), err
+		}
+
+		want, _ := run(false)
+		got, err := run(true)
+		if c.shouldError {
+			if err == nil {
+				t.Errorf("%s: unexpected lack of error", c.desc)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: got %v", c.desc, err)
+		} else if !bytes.Equal(got, want) {
+			t.Errorf("%s: output differs from the serial output: got %d bytes want %d bytes", c.desc, len(got), len(want))
+		}
+	}
+}
+
+func TestRunSimulatorParallelSameOutput(t *testing.T) {
+	dgc := &common.DataGeneratorConfig{
+		BaseConfig: common.BaseConfig{
+			Scale:     10,
+			TimeStart: defaultTimeStart,
+			TimeEnd:   "2016-01-01T01:00:00Z",
+			Use:       common.UseCaseDevops,
+		},
+		InitialScale:         3,
+		LogInterval:          defaultLogInterval,
+		InterleavedNumGroups: 1,
+		Workers:              4,
+	}
+	run := func(parallel bool) []byte {
+		rand.Seed(123)
+		scfg, err := usecases.GetSimulatorConfig(dgc)
+		if err != nil {
+			t.Fatalf("could not create the simulator config: %v", err)
+		}
+		sim := scfg.NewSimulator(dgc.LogInterval, 0)
+
+		var buf bytes.Buffer
+		g := &DataGenerator{
+			config: dgc,
+			bufOut: bufio.NewWriter(&buf),
+		}
+		if parallel {
+			err = g.runSimulatorParallel(sim, func() serialize.PointSerializer { return &fullSerializer{} }, dgc)
+		} else {
+			err = g.runSimulator(sim, &fullSerializer{}, dgc)
+		}
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return buf.Bytes()
+	}
+
+	want := run(false)
+	if got := run(true); !bytes.Equal(got, want) {
+		t.Errorf("parallel output differs from the serial output: got %d bytes want %d bytes", len(got), len(want))
+	}
+}
+
+func TestDataGeneratorConfigValidateWorkers(t *testing.T) {
+	c := &common.DataGeneratorConfig{
+		BaseConfig: common.BaseConfig{
+			Scale:  1,
+			Format: constants.FormatInflux,
+			Use:    common.UseCaseDevops,
+		},
+		LogInterval:          defaultLogInterval,
+		InterleavedNumGroups: 1,
+	}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c.Workers != 1 {
+		t.Errorf("incorrect default workers: got %d want 1", c.Workers)
+	}
+
+	c.Format = constants.FormatPrometheus
+	c.Workers = 2
+	want := "the prometheus format can not be serialized by more than 1 worker"
+	if err := c.Validate(); err == nil || err.Error() != want {
+		t.Errorf("incorrect error for a stateful serializer: got %v want %s", err, want)
+	}
+}
@@ -373,3 +373,27 @@ func (m *mockTarget) TargetSpecificFlags(string, *pflag.FlagSet) {
 func (m *mockTarget) TargetName() string {
 	return m.name
 }
+
+type seriesCountingSimulator struct {
+	common.Simulator
+	tagSets uint64
+}
+
+func (s *seriesCountingSimulator) SeriesCount() (uint64, uint64) {
+	return s.tagSets, 4 * s.tagSets
+}
+
+func TestReportSeries(t *testing.T) {
+	var buf bytes.Buffer
+	reportSeries(&buf, &seriesCountingSimulator{tagSets: 10})
+	if got, want := buf.String(), "generated 40 unique series (10 unique tag sets)\n"; got != want {
+		t.Errorf("incorrect report: got %q want %q", got, want)
+	}
+
+	// simulators that do not count their series are not reported
+	buf.Reset()
+	reportSeries(&buf, &common.BaseSimulator{})
+	if buf.Len() != 0 {
+		t.Errorf("unexpected report: %q", buf.String())
+	}
+}
@@ -10,9 +10,11 @@ import (
 	"sort"
 	"time"
 
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/devops"
 	queryUtils "github.com/timescale/tsbs/cmd/tsbs_generate_queries/utils"
 	internalUtils "github.com/timescale/tsbs/internal/utils"
 	"github.com/timescale/tsbs/pkg/data/usecases/common"
+	"github.com/timescale/tsbs/pkg/query"
 	"github.com/timescale/tsbs/pkg/query/config"
 	"github.com/timescale/tsbs/pkg/query/factories"
 )
@@ -40,6 +42,11 @@ type IoTGeneratorMaker interface {
 	NewIoT(start, end time.Time, scale int) (queryUtils.QueryGenerator, error)
 }
 
+// FinanceGeneratorMaker creates a query generator for finance use case
+type FinanceGeneratorMaker interface {
+	NewFinance(start, end time.Time, scale int) (queryUtils.QueryGenerator, error)
+}
+
 // QueryGenerator is a type of Generator for creating queries to test against a
 // database. The output is specific to the type of database (due to each using
 // different querying techniques, e.g. SQL or REST), but is consumed by TSBS
@@ -64,6 +71,8 @@ type QueryGenerator struct {
 	// bufOut represents the buffered writer that should actually be passed to
 	// any operations that write out data.
 	bufOut *bufio.Writer
+	// compressor is the compressor of bufOut, nil unless the output is compressed
+	compressor io.Closer
 }
 
 // NewQueryGenerator returns a QueryGenerator that is set up to work with a given
@@ -87,7 +96,15 @@ func (g *QueryGenerator) Generate(config common.GeneratorConfig) error {
 		return err
 	}
 
-	filler := g.useCaseMatrix[g.conf.Use][g.conf.QueryType](useGen)
+	var filler queryUtils.QueryFiller
+	if g.conf.QueryMix != "" {
+		filler, err = g.newQueryMix(useGen)
+		if err != nil {
+			return err
+		}
+	} else {
+		filler = g.useCaseMatrix[g.conf.Use][g.conf.QueryType](useGen)
+	}
 
 	return g.runQueryGeneration(useGen, filler, g.conf)
 }
@@ -116,7 +133,17 @@ func (g *QueryGenerator) init(conf common.GeneratorConfig) error {
 		return fmt.Errorf(errBadUseFmt, g.conf.Use)
 	}
 
-	if _, ok := g.useCaseMatrix[g.conf.Use][g.conf.QueryType]; !ok {
+	if g.conf.QueryMix != "" {
+		entries, err := config.ParseQueryMix(g.conf.QueryMix)
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			if _, ok := g.useCaseMatrix[g.conf.Use][e.QueryType]; !ok {
+				return fmt.Errorf(errBadQueryTypeFmt, g.conf.Use, e.QueryType)
+			}
+		}
+	} else if _, ok := g.useCaseMatrix[g.conf.Use][g.conf.QueryType]; !ok {
 		return fmt.Errorf(errBadQueryTypeFmt, g.conf.Use, g.conf.QueryType)
 	}
 
@@ -132,7 +159,7 @@ func (g *QueryGenerator) init(conf common.GeneratorConfig) error {
 	if g.Out == nil {
 		g.Out = os.Stdout
 	}
-	g.bufOut, err = getBufferedWriter(g.conf.File, g.Out)
+	g.bufOut, g.compressor, err = getBufferedWriter(g.conf.File, g.conf.Compress, g.Out)
 	if err != nil {
 		return err
 	}
@@ -189,6 +216,13 @@ func (g *QueryGenerator) getUseCaseGenerator(c *config.QueryGeneratorConfig) (qu
 		}
 
 		return iotFactory.NewIoT(g.tsStart, g.tsEnd, scale)
+	case common.UseCaseFinance:
+		financeFactory, ok := factory.(FinanceGeneratorMaker)
+		if !ok {
+			return nil, fmt.Errorf(errUseCaseNotImplementedFmt, c.Use, c.Format)
+		}
+
+		return financeFactory.NewFinance(g.tsStart, g.tsEnd, scale)
 	case common.UseCaseDevops, common.UseCaseCPUOnly, common.UseCaseCPUSingle:
 		devopsFactory, ok := factory.(DevopsGeneratorMaker)
 		if !ok {
@@ -203,9 +237,13 @@ func (g *QueryGenerator) getUseCaseGenerator(c *config.QueryGeneratorConfig) (qu
 
 func (g *QueryGenerator) runQueryGeneration(useGen queryUtils.QueryGenerator, filler queryUtils.QueryFiller, c *config.QueryGeneratorConfig) error {
 	stats := make(map[string]int64)
+	total := int64(0)
+	mix, isMix := filler.(*queryMix)
 	currentGroup := uint(0)
+	recorder, _ := useGen.(queryParamsRecorder)
+	var refs []*query.Reference
+	var refQueries []*referenceQuery
 	enc := gob.NewEncoder(g.bufOut)
-	defer g.bufOut.Flush()
 
 	rand.Seed(g.conf.Seed)
 	//fmt.Println(g.config.Seed)
@@ -225,7 +263,24 @@ func (g *QueryGenerator) runQueryGeneration(useGen queryUtils.QueryGenerator, fi
 			if err != nil {
 				return fmt.Errorf(errCouldNotEncodeQueryFmt, err)
 			}
+			if c.ReferenceFile != "" {
+				ref := &query.Reference{
+					ID:          uint64(total),
+					Label:       string(q.HumanLabelName()),
+					Description: string(q.HumanDescriptionName()),
+				}
+				var params *devops.QueryParams
+				if recorder != nil {
+					params = recorder.LastQueryParams()
+				}
+				refs = append(refs, ref)
+				refQueries = append(refQueries, newReferenceQuery(params))
+			}
 			stats[string(q.HumanLabelName())]++
+			total++
+			if isMix {
+				mix.generated[mix.last]++
+			}
 
 			if c.Debug > 0 {
 				var debugMsg string
@@ -250,6 +305,9 @@ func (g *QueryGenerator) runQueryGeneration(useGen queryUtils.QueryGenerator, fi
 			currentGroup = 0
 		}
 	}
+	if err := flushOutput(g.bufOut, g.compressor); err != nil {
+		return err
+	}
 
 	// Print stats:
 	keys := []string{}
@@ -258,10 +316,23 @@ func (g *QueryGenerator) runQueryGeneration(useGen queryUtils.QueryGenerator, fi
 	}
 	sort.Strings(keys)
 	for _, k := range keys {
-		_, err := fmt.Fprintf(g.DebugOut, "%s: %d points\n", k, stats[k])
+		var err error
+		if isMix {
+			_, err = fmt.Fprintf(g.DebugOut, "%s: %d points (%.2f%%)\n", k, stats[k], percentage(stats[k], total))
+		} else {
+			_, err = fmt.Fprintf(g.DebugOut, "%s: %d points\n", k, stats[k])
+		}
 		if err != nil {
 			return fmt.Errorf(errCouldNotQueryStatsFmt, err)
 		}
 	}
+	if isMix {
+		if err := mix.writeSummary(g.DebugOut, total); err != nil {
+			return fmt.Errorf(errCouldNotQueryStatsFmt, err)
+		}
+	}
+	if c.ReferenceFile != "" {
+		return g.writeReferences(refs, refQueries)
+	}
 	return nil
 }
@@ -599,6 +599,11 @@ func TestQueryGeneratorRunQueryGenerationErrors(t *testing.T) {
 	g.bufOut = bufio.NewWriterSize(&badWriter{}, 8) // small buffer forces it to write to underlying
 	want = fmt.Sprintf(errCouldNotEncodeQueryFmt, "error writing")
 	checkErr(want)
+
+	// Test error on flushing the buffered queries
+	g.bufOut = bufio.NewWriterSize(&badWriter{}, 1<<20)
+	want = fmt.Sprintf(errCouldNotFlushFmt, "error writing")
+	checkErr(want)
 }
 
 func TestQueryGeneratorGenerate(t *testing.T) {
@@ -623,3 +628,84 @@ func TestQueryGeneratorGenerate(t *testing.T) {
 	}
 	checkGeneratedOutput(t, &buf)
 }
+
+func TestQueryGeneratorGenerateQueryMix(t *testing.T) {
+	c, g := getTestConfigAndGenerator()
+	g.useCaseMatrix[common.UseCaseCPUOnly]["lastpoint"] = devops.NewLastPointPerHost
+	g.useCaseMatrix[common.UseCaseCPUOnly]["high-cpu-all"] = devops.NewHighCPU(0)
+	c.QueryType = ""
+	c.QueryMix = "single-groupby-1-1-1=2,lastpoint=1,high-cpu-all=1"
+	c.Limit = 8
+
+	var buf bytes.Buffer
+	var debug bytes.Buffer
+	g.Out = &buf
+	g.DebugOut = &debug
+	err := g.Generate(c)
+	if err != nil {
+		t.Fatalf("unexpected error when generating: got %v", err)
+	}
+
+	decoder := gob.NewDecoder(bufio.NewReader(&buf))
+	var labels []string
+	for {
+		var q query.TimescaleDB
+		err := decoder.Decode(&q)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("unexpected error while decoding: got %v", err)
+		}
+		labels = append(labels, string(q.HumanLabel))
+	}
+	if len(labels) != int(c.Limit) {
+		t.Fatalf("incorrect number of queries: got %d want %d", len(labels), c.Limit)
+	}
+	// smooth weighted round-robin interleaves the query types
+	for i := 0; i < 4; i++ {
+		if labels[i] != labels[i+4] {
+			t.Errorf("mix not periodic: query %d is %s, query %d is %s", i, labels[i], i+4, labels[i+4])
+		}
+	}
+	if labels[0] == labels[1] {
+		t.Errorf("query types not interleaved: %v", labels[:4])
+	}
+
+	want := []string{
+		"query mix:",
+		"  single-groupby-1-1-1: 4 queries (50.00%, requested 50.00%)",
+		"  lastpoint: 2 queries (25.00%, requested 25.00%)",
+		"  high-cpu-all: 2 queries (25.00%, requested 25.00%)",
+	}
+	got := debug.String()
+	for _, w := range want {
+		if !strings.Contains(got, w+"\n") {
+			t.Errorf("summary missing line %q:\n%s", w, got)
+		}
+	}
+	if !strings.Contains(got, "4 points (50.00%)") {
+		t.Errorf("per-label summary does not report share:\n%s", got)
+	}
+}
+
+func TestQueryGeneratorInitQueryMix(t *testing.T) {
+	c, g := getTestConfigAndGenerator()
+	c.QueryType = ""
+	c.QueryMix = "single-groupby-1-1-1=1,unknown=2"
+	err := g.init(c)
+	want := fmt.Sprintf(errBadQueryTypeFmt, common.UseCaseCPUOnly, "unknown")
+	if err == nil {
+		t.Errorf("unexpected lack of error with bad query type in mix")
+	} else if got := err.Error(); got != want {
+		t.Errorf("incorrect error for bad query type in mix:\ngot\n%s\nwant\n%s", got, want)
+	}
+
+	c.QueryType = "single-groupby-1-1-1"
+	c.QueryMix = "single-groupby-1-1-1=1"
+	err = g.init(c)
+	if err == nil {
+		t.Errorf("unexpected lack of error with both query type and mix")
+	} else if got := err.Error(); got != config.ErrQueryTypeAndMixSet {
+		t.Errorf("incorrect error:\ngot\n%s\nwant\n%s", got, config.ErrQueryTypeAndMixSet)
+	}
+}
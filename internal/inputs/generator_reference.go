@@ -0,0 +1,292 @@
+package inputs
+
+import (
+	"encoding/gob"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"time"
+
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/devops"
+	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/data/usecases/common"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+const (
+	errCouldNotWriteReferenceFmt = "could not write reference results: %v"
+
+	// groupby-orderby-limit returns the last 5 minutes before a random end
+	referenceOrderbyLimit = 5
+	// high-cpu returns the rows with a usage_user above this threshold
+	referenceHighCPUThreshold = 90.0
+)
+
+var referenceHostTag = []byte("hostname")
+
+// queryParamsRecorder is implemented by the query generators that record the
+// parameters of the queries they fill in, i.e. the devops ones.
+type queryParamsRecorder interface {
+	LastQueryParams() *devops.QueryParams
+}
+
+// referenceQuery computes the expected result of a single generated query
+// from the cpu points it is fed with.
+type referenceQuery struct {
+	kind    string
+	hosts   map[string]bool // nil for all hosts
+	start   int64
+	end     int64
+	metrics []int // indexes of the metrics in the cpu points
+	bucket  int64 // width of the time buckets, 0 for raw rows
+	byHost  bool
+	avg     bool
+
+	buckets map[referenceBucketKey]*referenceBucket
+	rows    []query.ResultRow
+	last    map[string]query.ResultRow
+}
+
+type referenceBucketKey struct {
+	time int64
+	host string
+}
+
+type referenceBucket struct {
+	values []float64
+	count  int
+}
+
+// newReferenceQuery returns a referenceQuery for a query with the given
+// parameters, or nil if its expected result cannot be computed.
+func newReferenceQuery(p *devops.QueryParams) *referenceQuery {
+	if p == nil {
+		return nil
+	}
+
+	q := &referenceQuery{
+		kind:    p.Kind,
+		start:   math.MinInt64,
+		end:     math.MaxInt64,
+		buckets: make(map[referenceBucketKey]*referenceBucket),
+		last:    make(map[string]query.ResultRow),
+	}
+	if p.Hosts != nil {
+		q.hosts = make(map[string]bool, len(p.Hosts))
+		for _, h := range p.Hosts {
+			q.hosts[h] = true
+		}
+	}
+	if p.Interval != nil {
+		q.start = p.Interval.StartUnixNano()
+		q.end = p.Interval.EndUnixNano()
+	}
+	allMetrics := devops.GetAllCPUMetrics()
+	for _, m := range p.Metrics {
+		for i, name := range allMetrics {
+			if m == name {
+				q.metrics = append(q.metrics, i)
+			}
+		}
+	}
+
+	switch p.Kind {
+	case devops.LabelSingleGroupby:
+		q.bucket = int64(time.Minute)
+	case devops.LabelMaxAll:
+		q.bucket = int64(time.Hour)
+	case devops.LabelDoubleGroupby:
+		q.bucket = int64(time.Hour)
+		q.byHost = true
+		q.avg = true
+	case devops.LabelGroupbyOrderbyLimit:
+		// only the end of the window restricts the query
+		q.bucket = int64(time.Minute)
+		q.start = math.MinInt64
+	case devops.LabelHighCPU, devops.LabelLastpoint:
+	default:
+		return nil
+	}
+	return q
+}
+
+// add feeds a cpu point to the referenceQuery
+func (q *referenceQuery) add(ts int64, host string, values []float64) {
+	if ts < q.start || ts >= q.end {
+		return
+	}
+	if q.hosts != nil && !q.hosts[host] {
+		return
+	}
+
+	switch q.kind {
+	case devops.LabelLastpoint:
+		if last, ok := q.last[host]; !ok || ts > last.Time {
+			q.last[host] = query.ResultRow{Time: ts, Group: host, Values: q.selectValues(values)}
+		}
+	case devops.LabelHighCPU:
+		if values[0] > referenceHighCPUThreshold {
+			q.rows = append(q.rows, query.ResultRow{Time: ts, Group: host, Values: q.selectValues(values)})
+		}
+	default:
+		key := referenceBucketKey{time: ts - ts%q.bucket}
+		if q.byHost {
+			key.host = host
+		}
+		b, ok := q.buckets[key]
+		if !ok {
+			b = &referenceBucket{values: q.selectValues(values), count: 1}
+			q.buckets[key] = b
+			return
+		}
+		b.count++
+		for i, idx := range q.metrics {
+			if q.avg {
+				b.values[i] += values[idx]
+			} else if values[idx] > b.values[i] {
+				b.values[i] = values[idx]
+			}
+		}
+	}
+}
+
+func (q *referenceQuery) selectValues(values []float64) []float64 {
+	res := make([]float64, len(q.metrics))
+	for i, idx := range q.metrics {
+		res[i] = values[idx]
+	}
+	return res
+}
+
+// result returns the expected result of the query, once all points were added
+func (q *referenceQuery) result() *query.Result {
+	r := &query.Result{Rows: q.rows}
+	for _, row := range q.last {
+		r.Rows = append(r.Rows, row)
+	}
+	for key, b := range q.buckets {
+		if q.avg {
+			for i := range b.values {
+				b.values[i] /= float64(b.count)
+			}
+		}
+		r.Rows = append(r.Rows, query.ResultRow{Time: key.time, Group: key.host, Values: b.values})
+	}
+	r.Sort()
+
+	if q.kind == devops.LabelGroupbyOrderbyLimit && len(r.Rows) > referenceOrderbyLimit {
+		r.Rows = r.Rows[len(r.Rows)-referenceOrderbyLimit:]
+	}
+	return r
+}
+
+// writeReferences computes the expected results of the queries by simulating
+// the data they run against, and writes them to the reference file. Queries
+// that are nil in the queries slice are written without a result.
+func (g *QueryGenerator) writeReferences(refs []*query.Reference, queries []*referenceQuery) error {
+	if err := g.evaluateReferences(queries); err != nil {
+		return err
+	}
+
+	f, err := os.Create(g.conf.ReferenceFile)
+	if err != nil {
+		return fmt.Errorf(errCouldNotWriteReferenceFmt, err)
+	}
+	defer f.Close()
+
+	enc := gob.NewEncoder(f)
+	for i, ref := range refs {
+		if queries[i] != nil {
+			ref.Result = queries[i].result()
+		}
+		if err := enc.Encode(ref); err != nil {
+			return fmt.Errorf(errCouldNotWriteReferenceFmt, err)
+		}
+	}
+	return nil
+}
+
+// evaluateReferences feeds the cpu points of the simulated data to the
+// queries that are restricted to their host or apply to all hosts.
+func (g *QueryGenerator) evaluateReferences(queries []*referenceQuery) error {
+	dg := &DataGenerator{Out: ioutil.Discard}
+	sim, err := dg.CreateSimulator(&common.DataGeneratorConfig{
+		BaseConfig: common.BaseConfig{
+			Format:    g.conf.Format,
+			Use:       g.conf.Use,
+			Scale:     g.conf.Scale,
+			TimeStart: g.conf.TimeStart,
+			TimeEnd:   g.conf.TimeEnd,
+			Seed:      g.conf.ReferenceSeed,
+		},
+		InitialScale:         g.conf.Scale,
+		LogInterval:          g.conf.ReferenceLogInterval,
+		InterleavedNumGroups: 1,
+	})
+	if err != nil {
+		return err
+	}
+
+	var allHosts []*referenceQuery
+	perHost := make(map[string][]*referenceQuery)
+	for _, q := range queries {
+		if q == nil {
+			continue
+		}
+		if q.hosts == nil {
+			allHosts = append(allHosts, q)
+			continue
+		}
+		for h := range q.hosts {
+			perHost[h] = append(perHost[h], q)
+		}
+	}
+
+	metricIndex := make(map[string]int)
+	for i, m := range devops.GetAllCPUMetrics() {
+		metricIndex[m] = i
+	}
+	values := make([]float64, len(metricIndex))
+
+	p := data.NewPoint()
+	for !sim.Finished() {
+		write := sim.Next(p)
+		if !write || string(p.MeasurementName()) != devops.TableName {
+			p.Reset()
+			continue
+		}
+
+		host := fmt.Sprintf("%v", p.GetTagValue(referenceHostTag))
+		ts := p.Timestamp().UnixNano()
+		fieldValues := p.FieldValues()
+		for i, key := range p.FieldKeys() {
+			if idx, ok := metricIndex[string(key)]; ok {
+				values[idx] = toReferenceValue(fieldValues[i])
+			}
+		}
+
+		for _, q := range allHosts {
+			q.add(ts, host, values)
+		}
+		for _, q := range perHost[host] {
+			q.add(ts, host, values)
+		}
+		p.Reset()
+	}
+	return nil
+}
+
+func toReferenceValue(v interface{}) float64 {
+	switch x := v.(type) {
+	case int64:
+		return float64(x)
+	case float64:
+		return x
+	case int:
+		return float64(x)
+	case float32:
+		return float64(x)
+	}
+	return 0
+}
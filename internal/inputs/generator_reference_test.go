@@ -0,0 +1,186 @@
+package inputs
+
+import (
+	"bytes"
+	"encoding/gob"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/devops"
+	internalUtils "github.com/timescale/tsbs/internal/utils"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+func TestReferenceQuery(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	interval, err := internalUtils.NewTimeInterval(start, start.Add(2*time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	minute := int64(time.Minute)
+	ts := func(seconds int) int64 { return start.Add(time.Duration(seconds) * time.Second).UnixNano() }
+	cpu := func(userUsage, systemUsage float64) []float64 {
+		values := make([]float64, devops.GetCPUMetricsLen())
+		values[0] = userUsage
+		values[1] = systemUsage
+		return values
+	}
+	type point struct {
+		ts     int64
+		host   string
+		values []float64
+	}
+	points := []point{
+		{ts(-10), "host_0", cpu(99, 1)},
+		{ts(0), "host_0", cpu(10, 20)},
+		{ts(0), "host_1", cpu(95, 40)},
+		{ts(30), "host_0", cpu(30, 10)},
+		{ts(60), "host_0", cpu(20, 60)},
+		{ts(60), "host_2", cpu(50, 50)},
+		{ts(120), "host_0", cpu(91, 5)},
+	}
+	metrics, _ := devops.GetCPUMetricsSlice(2)
+
+	cases := []struct {
+		desc   string
+		params *devops.QueryParams
+		want   []query.ResultRow
+	}{
+		{
+			desc:   "single groupby",
+			params: &devops.QueryParams{Kind: devops.LabelSingleGroupby, Metrics: metrics, Hosts: []string{"host_0", "host_1"}, Interval: interval},
+			want: []query.ResultRow{
+				{Time: start.UnixNano(), Values: []float64{95, 40}},
+				{Time: start.UnixNano() + minute, Values: []float64{20, 60}},
+			},
+		},
+		{
+			desc:   "double groupby",
+			params: &devops.QueryParams{Kind: devops.LabelDoubleGroupby, Metrics: metrics[:1], Interval: interval},
+			want: []query.ResultRow{
+				{Time: start.UnixNano(), Group: "host_0", Values: []float64{20}},
+				{Time: start.UnixNano(), Group: "host_1", Values: []float64{95}},
+				{Time: start.UnixNano(), Group: "host_2", Values: []float64{50}},
+			},
+		},
+		{
+			desc:   "groupby orderby limit",
+			params: &devops.QueryParams{Kind: devops.LabelGroupbyOrderbyLimit, Metrics: metrics[:1], Interval: interval},
+			want: []query.ResultRow{
+				{Time: start.UnixNano() - minute, Values: []float64{99}},
+				{Time: start.UnixNano(), Values: []float64{95}},
+				{Time: start.UnixNano() + minute, Values: []float64{50}},
+			},
+		},
+		{
+			desc:   "high cpu",
+			params: &devops.QueryParams{Kind: devops.LabelHighCPU, Metrics: metrics[:1], Interval: interval},
+			want: []query.ResultRow{
+				{Time: ts(0), Group: "host_1", Values: []float64{95}},
+			},
+		},
+		{
+			desc:   "lastpoint",
+			params: &devops.QueryParams{Kind: devops.LabelLastpoint, Metrics: metrics},
+			want: []query.ResultRow{
+				{Time: ts(0), Group: "host_1", Values: []float64{95, 40}},
+				{Time: ts(60), Group: "host_2", Values: []float64{50, 50}},
+				{Time: ts(120), Group: "host_0", Values: []float64{91, 5}},
+			},
+		},
+	}
+	for _, c := range cases {
+		q := newReferenceQuery(c.params)
+		for _, p := range points {
+			q.add(p.ts, p.host, p.values)
+		}
+		got := q.result()
+		want := &query.Result{Rows: c.want}
+		want.Sort()
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: incorrect result:\ngot\n%v\nwant\n%v", c.desc, got, want)
+		}
+	}
+
+	if q := newReferenceQuery(nil); q != nil {
+		t.Errorf("expected no reference query without parameters")
+	}
+	if q := newReferenceQuery(&devops.QueryParams{Kind: "foo"}); q != nil {
+		t.Errorf("expected no reference query for unknown kind")
+	}
+}
+
+func TestQueryGeneratorGenerateReference(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tsbs-reference")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	c, g := getTestConfigAndGenerator()
+	g.useCaseMatrix[c.Use]["lastpoint"] = devops.NewLastPointPerHost
+	c.QueryType = ""
+	c.QueryMix = "single-groupby-1-1-1=1,lastpoint=1"
+	c.Limit = 4
+	c.ReferenceFile = filepath.Join(dir, "reference.gob")
+	c.ReferenceSeed = 123
+	c.ReferenceLogInterval = defaultLogInterval
+
+	g.Out = &bytes.Buffer{}
+	g.DebugOut = ioutil.Discard
+	if err := g.Generate(c); err != nil {
+		t.Fatalf("unexpected error when generating: got %v", err)
+	}
+
+	f, err := os.Open(c.ReferenceFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	decoder := gob.NewDecoder(f)
+	var refs []query.Reference
+	for {
+		var ref query.Reference
+		err := decoder.Decode(&ref)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("unexpected error while decoding: got %v", err)
+		}
+		refs = append(refs, ref)
+	}
+	if len(refs) != int(c.Limit) {
+		t.Fatalf("incorrect number of references: got %d want %d", len(refs), c.Limit)
+	}
+
+	for i, ref := range refs {
+		if ref.ID != uint64(i) {
+			t.Errorf("incorrect reference id: got %d want %d", ref.ID, i)
+		}
+		if ref.Result == nil {
+			t.Fatalf("reference %d (%s) has no result", i, ref.Label)
+		}
+		rows := ref.Result.Rows
+		switch ref.Label {
+		case "TimescaleDB last row per host":
+			if len(rows) != int(c.Scale) {
+				t.Errorf("incorrect number of lastpoint rows: got %d want %d", len(rows), c.Scale)
+			}
+		default:
+			// one hour by minute for a single host
+			if len(rows) == 0 || len(rows) > 61 {
+				t.Errorf("incorrect number of single groupby rows: got %d", len(rows))
+			}
+			for _, row := range rows {
+				if row.Time%int64(time.Minute) != 0 || len(row.Values) != 1 {
+					t.Errorf("incorrect single groupby row: %v", row)
+				}
+			}
+		}
+	}
+}
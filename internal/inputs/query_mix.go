@@ -0,0 +1,85 @@
+package inputs
+
+import (
+	"fmt"
+	"io"
+
+	queryUtils "github.com/timescale/tsbs/cmd/tsbs_generate_queries/utils"
+	"github.com/timescale/tsbs/pkg/query"
+	"github.com/timescale/tsbs/pkg/query/config"
+)
+
+// queryMix is a QueryFiller that interleaves several query types according to
+// their weights. The query types are picked with a smooth weighted round-robin
+// so every prefix of the generated stream follows the requested mix closely,
+// without drawing from the random number generator used to fill the queries.
+type queryMix struct {
+	entries []config.QueryMixEntry
+	fillers []queryUtils.QueryFiller
+	current []int64
+	total   int64
+
+	// last is the index of the query type used for the last filled query
+	last int
+	// generated counts the queries written out per query type
+	generated []int64
+}
+
+func (g *QueryGenerator) newQueryMix(useGen queryUtils.QueryGenerator) (*queryMix, error) {
+	entries, err := config.ParseQueryMix(g.conf.QueryMix)
+	if err != nil {
+		return nil, err
+	}
+	m := &queryMix{
+		entries:   entries,
+		fillers:   make([]queryUtils.QueryFiller, len(entries)),
+		current:   make([]int64, len(entries)),
+		generated: make([]int64, len(entries)),
+	}
+	for i, e := range entries {
+		maker, ok := g.useCaseMatrix[g.conf.Use][e.QueryType]
+		if !ok {
+			return nil, fmt.Errorf(errBadQueryTypeFmt, g.conf.Use, e.QueryType)
+		}
+		m.fillers[i] = maker(useGen)
+		m.total += int64(e.Weight)
+	}
+	return m, nil
+}
+
+// Fill fills q using the query type that is next in the mix
+func (m *queryMix) Fill(q query.Query) query.Query {
+	best := 0
+	for i, e := range m.entries {
+		m.current[i] += int64(e.Weight)
+		if m.current[i] > m.current[best] {
+			best = i
+		}
+	}
+	m.current[best] -= m.total
+	m.last = best
+	return m.fillers[best].Fill(q)
+}
+
+// writeSummary writes the requested and the generated share of each query type
+func (m *queryMix) writeSummary(w io.Writer, generatedTotal int64) error {
+	if _, err := fmt.Fprintf(w, "query mix:\n"); err != nil {
+		return err
+	}
+	for i, e := range m.entries {
+		_, err := fmt.Fprintf(w, "  %s: %d queries (%.2f%%, requested %.2f%%)\n",
+			e.QueryType, m.generated[i], percentage(m.generated[i], generatedTotal),
+			percentage(int64(e.Weight), m.total))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func percentage(part, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return 100 * float64(part) / float64(total)
+}
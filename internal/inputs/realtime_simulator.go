@@ -0,0 +1,90 @@
+package inputs
+
+import (
+	"time"
+
+	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/data/usecases/common"
+)
+
+// realTimeSimulator releases the points of a simulator in real time: each
+// point is timestamped with the wall-clock time at which as much time passed
+// since the first point as between the simulated timestamps of the two, and
+// is not released before then. Points are generated one ahead, so that
+// readers can tell whether the next point is due without waiting for it.
+type realTimeSimulator struct {
+	common.Simulator
+	nowFn   func() time.Time
+	sleepFn func(time.Duration)
+
+	started   bool
+	simStart  time.Time
+	wallStart time.Time
+
+	// next is the point generated ahead, if hasNext
+	next      *data.Point
+	nextWrite bool
+	hasNext   bool
+}
+
+func newRealTimeSimulator(sim common.Simulator) *realTimeSimulator {
+	return &realTimeSimulator{
+		Simulator: sim,
+		nowFn:     time.Now,
+		sleepFn:   time.Sleep,
+		next:      data.NewPoint(),
+	}
+}
+
+// generate generates the next point ahead, if there is none yet
+func (s *realTimeSimulator) generate() bool {
+	if !s.hasNext && !s.Simulator.Finished() {
+		s.nextWrite = s.Simulator.Next(s.next)
+		s.hasNext = true
+	}
+	return s.hasNext
+}
+
+// releaseTime returns the wall-clock time of the point generated ahead
+func (s *realTimeSimulator) releaseTime() time.Time {
+	ts := s.next.Timestamp()
+	if ts == nil {
+		return s.nowFn()
+	}
+	if !s.started {
+		s.started = true
+		s.simStart = *ts
+		s.wallStart = s.nowFn()
+	}
+	return s.wallStart.Add(ts.Sub(s.simStart))
+}
+
+// ready returns whether the next point can be released without waiting
+func (s *realTimeSimulator) ready() bool {
+	return !s.generate() || !s.releaseTime().After(s.nowFn())
+}
+
+func (s *realTimeSimulator) Finished() bool {
+	return !s.hasNext && s.Simulator.Finished()
+}
+
+// Next waits until the next point is due and returns it in p, timestamped
+// with its release time
+func (s *realTimeSimulator) Next(p *data.Point) bool {
+	if !s.generate() {
+		return s.Simulator.Next(p)
+	}
+	at := s.releaseTime()
+	if wait := at.Sub(s.nowFn()); wait > 0 {
+		s.sleepFn(wait)
+	}
+	// hand the point over by swapping, so that p does not share its slices
+	// with the next point generated ahead
+	*p, *s.next = *s.next, *p
+	s.next.Reset()
+	s.hasNext = false
+	if p.Timestamp() != nil {
+		p.SetTimestamp(&at)
+	}
+	return s.nextWrite
+}
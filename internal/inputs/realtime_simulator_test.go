@@ -0,0 +1,83 @@
+package inputs
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/data/usecases/common"
+)
+
+// tickingSimulator simulates two points every 10 seconds
+type tickingSimulator struct {
+	testSimulator
+}
+
+func (s *tickingSimulator) Next(p *data.Point) bool {
+	ts := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC).Add(time.Duration(s.iteration/2) * 10 * time.Second)
+	p.SetTimestamp(&ts)
+	return s.testSimulator.Next(p)
+}
+
+func newTestRealTimeSimulator(limit uint64) (*realTimeSimulator, *time.Time, *time.Duration) {
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	slept := time.Duration(0)
+	s := newRealTimeSimulator(&tickingSimulator{testSimulator{limit: limit, shouldWriteLimit: limit}})
+	s.nowFn = func() time.Time { return now }
+	s.sleepFn = func(d time.Duration) {
+		slept += d
+		now = now.Add(d)
+	}
+	return s, &now, &slept
+}
+
+func TestRealTimeSimulator(t *testing.T) {
+	s, now, slept := newTestRealTimeSimulator(6)
+	start := *now
+	p := data.NewPoint()
+	for i := 0; !s.Finished(); i++ {
+		if !s.Next(p) {
+			t.Fatalf("point %d not written", i)
+		}
+		want := start.Add(time.Duration(i/2) * 10 * time.Second)
+		if got := *p.Timestamp(); !got.Equal(want) {
+			t.Errorf("incorrect timestamp of point %d: got %v want %v", i, got, want)
+		}
+		if got := p.GetFieldValue(keyIteration).(uint64); got != uint64(i) {
+			t.Errorf("incorrect point %d: got iteration %d", i, got)
+		}
+		p.Reset()
+	}
+	if *slept != 20*time.Second {
+		t.Errorf("incorrect time slept: got %v want %v", *slept, 20*time.Second)
+	}
+}
+
+func TestSimulatorReaderRealTime(t *testing.T) {
+	s, now, _ := newTestRealTimeSimulator(4)
+	r := &simulatorReader{
+		sim:        s,
+		serializer: &testSerializer{},
+		config:     &common.DataGeneratorConfig{InterleavedNumGroups: 1},
+		point:      data.NewPoint(),
+	}
+	buf := make([]byte, 1024)
+	n, err := r.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the points of the first tick are read without waiting for the second
+	if got, want := string(buf[:n]), "iteration=0\niteration=1\n"; got != want {
+		t.Errorf("incorrect points read in the first tick: got %q want %q", got, want)
+	}
+
+	*now = now.Add(10 * time.Second)
+	n, err = r.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf[:n], []byte("iteration=2\niteration=3\n"); !bytes.Equal(got, want) {
+		t.Errorf("incorrect points read in the second tick: got %q want %q", got, want)
+	}
+}
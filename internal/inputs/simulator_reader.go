@@ -0,0 +1,95 @@
+package inputs
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/timescale/tsbs/load"
+	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/data/serialize"
+	"github.com/timescale/tsbs/pkg/data/source"
+	"github.com/timescale/tsbs/pkg/data/usecases/common"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+const defaultSimulatorReadSize = 4 << 20 // 4 MB
+
+// GetDataSourceReader returns a buffered reader of the data described by
+// config. A FILE data source reads the file (or STDIN if no location is set),
+// a SIMULATOR data source generates the data on the fly, serialized in the
+// format of target exactly as tsbs_generate_data would write it.
+func GetDataSourceReader(config *source.DataSourceConfig, target targets.ImplementedTarget) (*bufio.Reader, error) {
+	switch config.Type {
+	case source.FileDataSourceType:
+		return load.GetBufferedReader(config.File.Location), nil
+	case source.SimulatorDataSourceType:
+		dataGenerator := &DataGenerator{}
+		r, err := dataGenerator.CreateSimulatorReader(config.Simulator, target)
+		if err != nil {
+			return nil, err
+		}
+		return bufio.NewReaderSize(r, defaultSimulatorReadSize), nil
+	}
+	return nil, fmt.Errorf("unsupported data source type: %s", config.Type)
+}
+
+// CreateSimulatorReader creates a simulator from config and returns a reader
+// of the simulated points serialized for target, including the header for
+// formats that have one.
+func (g *DataGenerator) CreateSimulatorReader(config *common.DataGeneratorConfig, target targets.ImplementedTarget) (io.Reader, error) {
+	sim, err := g.CreateSimulator(config)
+	if err != nil {
+		return nil, err
+	}
+	r := &simulatorReader{
+		sim:        sim,
+		serializer: target.Serializer(),
+		config:     g.config,
+		point:      data.NewPoint(),
+	}
+	if needsHeader(target.TargetName()) {
+		writeHeader(&r.buf, sim.Headers())
+	}
+	return r, nil
+}
+
+// simulatorReader serializes points of a simulator on demand, following the
+// same interleaved group logic as runSimulator
+type simulatorReader struct {
+	sim         common.Simulator
+	serializer  serialize.PointSerializer
+	config      *common.DataGeneratorConfig
+	point       *data.Point
+	currGroupID uint
+	buf         bytes.Buffer
+}
+
+func (r *simulatorReader) Read(p []byte) (int, error) {
+	rt, realTime := r.sim.(*realTimeSimulator)
+	for r.buf.Len() < len(p) && !r.sim.Finished() {
+		// in real time, the points read so far are not held back until the next one is due
+		if realTime && r.buf.Len() > 0 && !rt.ready() {
+			break
+		}
+		write := r.sim.Next(r.point)
+		if !write {
+			r.point.Reset()
+			continue
+		}
+
+		if r.currGroupID == r.config.InterleavedGroupID {
+			if err := r.serializer.Serialize(r.point, &r.buf); err != nil {
+				return 0, fmt.Errorf("can not serialize point: %s", err)
+			}
+		}
+		r.point.Reset()
+
+		r.currGroupID = (r.currGroupID + 1) % r.config.InterleavedNumGroups
+	}
+	if r.buf.Len() == 0 {
+		return 0, io.EOF
+	}
+	return r.buf.Read(p)
+}
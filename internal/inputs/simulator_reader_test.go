@@ -0,0 +1,94 @@
+package inputs
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/data/source"
+	"github.com/timescale/tsbs/pkg/data/usecases/common"
+	"github.com/timescale/tsbs/pkg/targets/constants"
+)
+
+type lineSerializer struct{}
+
+func (s *lineSerializer) Serialize(p *data.Point, w io.Writer) error {
+	_, err := fmt.Fprintf(w, "%s %d\n", p.MeasurementName(), p.Timestamp().UnixNano())
+	return err
+}
+
+// smallReader forces many small reads from the wrapped reader
+type smallReader struct {
+	r io.Reader
+}
+
+func (s *smallReader) Read(p []byte) (int, error) {
+	if len(p) > 7 {
+		p = p[:7]
+	}
+	return s.r.Read(p)
+}
+
+func TestCreateSimulatorReader(t *testing.T) {
+	cases := []struct {
+		desc        string
+		format      string
+		groupID     uint
+		totalGroups uint
+	}{
+		{desc: "no header", format: constants.FormatInflux, totalGroups: 1},
+		{desc: "with header", format: constants.FormatTimescaleDB, totalGroups: 1},
+		{desc: "interleaved groups", format: constants.FormatInflux, groupID: 1, totalGroups: 3},
+	}
+	for _, c := range cases {
+		newConfig := func() *common.DataGeneratorConfig {
+			return &common.DataGeneratorConfig{
+				BaseConfig: common.BaseConfig{
+					Seed:      123,
+					Format:    c.format,
+					Use:       common.UseCaseDevops,
+					Scale:     2,
+					TimeStart: defaultTimeStart,
+					TimeEnd:   defaultTimeEnd,
+				},
+				InitialScale:         2,
+				LogInterval:          defaultLogInterval,
+				Limit:                50,
+				InterleavedGroupID:   c.groupID,
+				InterleavedNumGroups: c.totalGroups,
+			}
+		}
+		target := &mockTarget{name: c.format, serializer: &lineSerializer{}}
+
+		var want bytes.Buffer
+		g := &DataGenerator{Out: &want}
+		if err := g.Generate(newConfig(), target); err != nil {
+			t.Fatalf("%s: unexpected error generating: %v", c.desc, err)
+		}
+
+		r, err := (&DataGenerator{Out: ioutil.Discard}).CreateSimulatorReader(newConfig(), target)
+		if err != nil {
+			t.Fatalf("%s: unexpected error creating reader: %v", c.desc, err)
+		}
+		got, err := ioutil.ReadAll(&smallReader{r})
+		if err != nil {
+			t.Fatalf("%s: unexpected error reading: %v", c.desc, err)
+		}
+		if want.Len() == 0 {
+			t.Fatalf("%s: nothing generated", c.desc)
+		}
+		if !bytes.Equal(got, want.Bytes()) {
+			t.Errorf("%s: reader output differs from generated data:\ngot\n%s\nwant\n%s", c.desc, got, want.Bytes())
+		}
+	}
+}
+
+func TestGetDataSourceReaderUnknownType(t *testing.T) {
+	_, err := GetDataSourceReader(&source.DataSourceConfig{Type: "foo"}, &mockTarget{})
+	if err == nil {
+		t.Errorf("expected error for unknown data source type")
+	}
+}
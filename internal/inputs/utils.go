@@ -5,23 +5,50 @@ import (
 	"fmt"
 	"io"
 	"os"
+
+	"github.com/timescale/tsbs/internal/compression"
 )
 
 const (
 	errUnknownFormatFmt = "unknown format: '%s'"
+	errCouldNotFlushFmt = "could not flush output: %v"
 )
 
 const defaultWriteSize = 4 << 20 // 4 MB
 
-func getBufferedWriter(filename string, fallback io.Writer) (*bufio.Writer, error) {
+// getBufferedWriter returns the buffered writer of the output, compressed in
+// the given format. The returned compressor is nil unless the output is
+// compressed, and must be closed after the writer is flushed.
+func getBufferedWriter(filename, compress string, fallback io.Writer) (*bufio.Writer, io.Closer, error) {
+	out := fallback
 	// If filename is given, output should go to a file
 	if len(filename) > 0 {
 		file, err := os.Create(filename)
 		if err != nil {
-			return nil, fmt.Errorf("cannot open file for write %s: %v", filename, err)
+			return nil, nil, fmt.Errorf("cannot open file for write %s: %v", filename, err)
 		}
-		return bufio.NewWriterSize(file, defaultWriteSize), nil
+		out = file
+	}
+
+	if compress == "" || compress == compression.None {
+		return bufio.NewWriterSize(out, defaultWriteSize), nil, nil
+	}
+	compressor, err := compression.NewWriter(out, compress)
+	if err != nil {
+		return nil, nil, err
 	}
+	return bufio.NewWriterSize(compressor, defaultWriteSize), compressor, nil
+}
 
-	return bufio.NewWriterSize(fallback, defaultWriteSize), nil
+// flushOutput flushes the buffered output and ends its compressed data, if any
+func flushOutput(bufOut *bufio.Writer, compressor io.Closer) error {
+	if err := bufOut.Flush(); err != nil {
+		return fmt.Errorf(errCouldNotFlushFmt, err)
+	}
+	if compressor != nil {
+		if err := compressor.Close(); err != nil {
+			return fmt.Errorf(errCouldNotFlushFmt, err)
+		}
+	}
+	return nil
 }
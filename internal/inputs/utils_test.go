@@ -1,9 +1,14 @@
 package inputs
 
 import (
-	"github.com/timescale/tsbs/internal/utils"
+	"bufio"
+	"bytes"
+	"io/ioutil"
 	"testing"
 	"time"
+
+	"github.com/timescale/tsbs/internal/compression"
+	"github.com/timescale/tsbs/internal/utils"
 )
 
 func TestIsIn(t *testing.T) {
@@ -56,3 +61,33 @@ func TestParseUTCTime(t *testing.T) {
 		t.Errorf("unexpected lack of error")
 	}
 }
+
+func TestGetBufferedWriterCompressed(t *testing.T) {
+	var buf bytes.Buffer
+	bufOut, compressor, err := getBufferedWriter("", compression.Zstd, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = bufOut.WriteString("some data\n"); err != nil {
+		t.Fatal(err)
+	}
+	if err = flushOutput(bufOut, compressor); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := compression.NewReader(bufio.NewReader(&buf), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "some data\n" {
+		t.Errorf("incorrect decompressed output: got %q", got)
+	}
+
+	if _, compressor, _ = getBufferedWriter("", compression.None, &buf); compressor != nil {
+		t.Errorf("unexpected compressor of uncompressed output")
+	}
+}
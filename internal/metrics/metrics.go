@@ -0,0 +1,295 @@
+// Package metrics serves the progress of a benchmark run as OpenMetrics, so
+// that the benchmark clients can be scraped while they run.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const contentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+
+// DefaultBuckets are the upper bounds of histogram buckets of latencies in seconds
+var DefaultBuckets = []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60}
+
+// metric is a metric family that writes itself in the OpenMetrics text format
+type metric interface {
+	write(w io.Writer) error
+}
+
+// Registry holds the metrics served by an endpoint, in the order they were added
+type Registry struct {
+	mu      sync.Mutex
+	metrics []metric
+}
+
+// NewRegistry returns an empty Registry
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+func (r *Registry) add(m metric) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.metrics = append(r.metrics, m)
+}
+
+// CounterFunc adds a counter whose value is read from f on every scrape
+func (r *Registry) CounterFunc(name, help string, f func() float64) {
+	r.add(&funcMetric{name: name, help: help, typ: "counter", f: f})
+}
+
+// GaugeFunc adds a gauge whose value is read from f on every scrape
+func (r *Registry) GaugeFunc(name, help string, f func() float64) {
+	r.add(&funcMetric{name: name, help: help, typ: "gauge", f: f})
+}
+
+// NewCounterVec adds a counter with one label and returns it
+func (r *Registry) NewCounterVec(name, help, label string) *CounterVec {
+	c := &CounterVec{name: name, help: help, label: label, values: make(map[string]float64)}
+	r.add(c)
+	return c
+}
+
+// NewHistogram adds a histogram with the given bucket upper bounds and returns it
+func (r *Registry) NewHistogram(name, help string, buckets []float64) *Histogram {
+	h := newHistogram(buckets)
+	r.add(&histogramFamily{name: name, help: help, histograms: func() map[string]*Histogram {
+		return map[string]*Histogram{"": h}
+	}})
+	return h
+}
+
+// NewHistogramVec adds a histogram with one label and returns it
+func (r *Registry) NewHistogramVec(name, help, label string, buckets []float64) *HistogramVec {
+	h := &HistogramVec{buckets: buckets, histograms: make(map[string]*Histogram)}
+	r.add(&histogramFamily{name: name, help: help, label: label, histograms: h.snapshot})
+	return h
+}
+
+// Write writes all metrics in the OpenMetrics text format
+func (r *Registry) Write(w io.Writer) error {
+	r.mu.Lock()
+	metrics := append([]metric(nil), r.metrics...)
+	r.mu.Unlock()
+	for _, m := range metrics {
+		if err := m.write(w); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, "# EOF\n")
+	return err
+}
+
+// ServeHTTP serves all metrics in the OpenMetrics text format
+func (r *Registry) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", contentType)
+	bw := bufio.NewWriter(w)
+	if err := r.Write(bw); err != nil {
+		return
+	}
+	_ = bw.Flush()
+}
+
+// Serve serves the metrics of r on addr under /metrics in the background. It
+// only returns an error if addr cannot be listened on.
+func Serve(addr string, r *Registry) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("cannot listen for metrics on %s: %v", addr, err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", r)
+	go http.Serve(l, mux)
+	return nil
+}
+
+type funcMetric struct {
+	name, help, typ string
+	f               func() float64
+}
+
+func (m *funcMetric) write(w io.Writer) error {
+	sample := m.name
+	if m.typ == "counter" {
+		sample += "_total"
+	}
+	_, err := fmt.Fprintf(w, "%s%s %s\n", header(m.name, m.typ, m.help), sample, formatFloat(m.f()))
+	return err
+}
+
+// CounterVec is a counter with one label
+type CounterVec struct {
+	name, help, label string
+
+	mu     sync.Mutex
+	values map[string]float64
+}
+
+// Add adds v to the counter with label value lv
+func (c *CounterVec) Add(lv string, v float64) {
+	c.mu.Lock()
+	c.values[lv] += v
+	c.mu.Unlock()
+}
+
+// Inc increments the counter with label value lv
+func (c *CounterVec) Inc(lv string) {
+	c.Add(lv, 1)
+}
+
+func (c *CounterVec) write(w io.Writer) error {
+	c.mu.Lock()
+	values := make(map[string]float64, len(c.values))
+	for lv, v := range c.values {
+		values[lv] = v
+	}
+	c.mu.Unlock()
+
+	var b strings.Builder
+	b.WriteString(header(c.name, "counter", c.help))
+	for _, lv := range sortedKeys(values) {
+		fmt.Fprintf(&b, "%s_total{%s} %s\n", c.name, labelPair(c.label, lv), formatFloat(values[lv]))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Histogram counts observed values in buckets
+type Histogram struct {
+	buckets []float64
+
+	mu     sync.Mutex
+	counts []uint64 // counts[i] is the number of values <= buckets[i], not cumulative
+	sum    float64
+	count  uint64
+}
+
+func newHistogram(buckets []float64) *Histogram {
+	return &Histogram{buckets: buckets, counts: make([]uint64, len(buckets))}
+}
+
+// Observe records the value v
+func (h *Histogram) Observe(v float64) {
+	i := sort.SearchFloat64s(h.buckets, v)
+	h.mu.Lock()
+	if i < len(h.counts) {
+		h.counts[i]++
+	}
+	h.sum += v
+	h.count++
+	h.mu.Unlock()
+}
+
+// HistogramVec is a histogram with one label
+type HistogramVec struct {
+	buckets []float64
+
+	mu         sync.Mutex
+	histograms map[string]*Histogram
+}
+
+// Observe records the value v in the histogram with label value lv
+func (h *HistogramVec) Observe(lv string, v float64) {
+	h.mu.Lock()
+	hist, ok := h.histograms[lv]
+	if !ok {
+		hist = newHistogram(h.buckets)
+		h.histograms[lv] = hist
+	}
+	h.mu.Unlock()
+	hist.Observe(v)
+}
+
+func (h *HistogramVec) snapshot() map[string]*Histogram {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	histograms := make(map[string]*Histogram, len(h.histograms))
+	for lv, hist := range h.histograms {
+		histograms[lv] = hist
+	}
+	return histograms
+}
+
+// histogramFamily writes the histograms of a metric by label value. The
+// histogram of a metric without label is under the empty label value.
+type histogramFamily struct {
+	name, help, label string
+	histograms        func() map[string]*Histogram
+}
+
+func (f *histogramFamily) write(w io.Writer) error {
+	histograms := f.histograms()
+	var b strings.Builder
+	b.WriteString(header(f.name, "histogram", f.help))
+	for _, lv := range sortedKeys(histograms) {
+		labels := ""
+		if f.label != "" {
+			labels = labelPair(f.label, lv) + ","
+		}
+		h := histograms[lv]
+		h.mu.Lock()
+		cumulative := uint64(0)
+		for i, le := range h.buckets {
+			cumulative += h.counts[i]
+			fmt.Fprintf(&b, "%s_bucket{%sle=\"%s\"} %d\n", f.name, labels, formatFloat(le), cumulative)
+		}
+		fmt.Fprintf(&b, "%s_bucket{%sle=\"+Inf\"} %d\n", f.name, labels, h.count)
+		labels = strings.TrimSuffix(labels, ",")
+		if labels != "" {
+			labels = "{" + labels + "}"
+		}
+		fmt.Fprintf(&b, "%s_sum%s %s\n%s_count%s %d\n", f.name, labels, formatFloat(h.sum), f.name, labels, h.count)
+		h.mu.Unlock()
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func header(name, typ, help string) string {
+	return fmt.Sprintf("# TYPE %s %s\n# HELP %s %s\n", name, typ, name, escape(help))
+}
+
+func labelPair(name, value string) string {
+	return fmt.Sprintf("%s=\"%s\"", name, escape(value))
+}
+
+var escaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escape(s string) string {
+	return escaper.Replace(s)
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func sortedKeys(m interface{}) []string {
+	var keys []string
+	switch m := m.(type) {
+	case map[string]float64:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	case map[string]*Histogram:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
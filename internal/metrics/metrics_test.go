@@ -0,0 +1,78 @@
+package metrics
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRegistryWrite(t *testing.T) {
+	r := NewRegistry()
+	loaded := 0.0
+	r.CounterFunc("loaded", "Loaded rows.", func() float64 { return loaded })
+	r.GaugeFunc("in_flight", "Batches in flight.", func() float64 { return 2 })
+	failed := r.NewCounterVec("failed", "Failed \"queries\".", "query")
+	h := r.NewHistogram("duration_seconds", "Duration.", []float64{0.1, 1})
+	hv := r.NewHistogramVec("latency_seconds", "Latency.", "query", []float64{1})
+
+	loaded = 10
+	failed.Inc("b")
+	failed.Add("a", 2)
+	for _, v := range []float64{0.05, 0.1, 0.5, 5} {
+		h.Observe(v)
+	}
+	hv.Observe("q\n1", 0.5)
+
+	var b strings.Builder
+	if err := r.Write(&b); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `# TYPE loaded counter
+# HELP loaded Loaded rows.
+loaded_total 10
+# TYPE in_flight gauge
+# HELP in_flight Batches in flight.
+in_flight 2
+# TYPE failed counter
+# HELP failed Failed \"queries\".
+failed_total{query="a"} 2
+failed_total{query="b"} 1
+# TYPE duration_seconds histogram
+# HELP duration_seconds Duration.
+duration_seconds_bucket{le="0.1"} 2
+duration_seconds_bucket{le="1"} 3
+duration_seconds_bucket{le="+Inf"} 4
+duration_seconds_sum 5.65
+duration_seconds_count 4
+# TYPE latency_seconds histogram
+# HELP latency_seconds Latency.
+latency_seconds_bucket{query="q\n1",le="1"} 1
+latency_seconds_bucket{query="q\n1",le="+Inf"} 1
+latency_seconds_sum{query="q\n1"} 0.5
+latency_seconds_count{query="q\n1"} 1
+# EOF
+`
+	if got := b.String(); got != want {
+		t.Errorf("incorrect output: got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRegistryServeHTTP(t *testing.T) {
+	r := NewRegistry()
+	r.GaugeFunc("up", "Up.", func() float64 { return 1 })
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	if got := w.Header().Get("Content-Type"); got != contentType {
+		t.Errorf("incorrect content type: got %s want %s", got, contentType)
+	}
+	if !strings.HasSuffix(w.Body.String(), "up 1\n# EOF\n") {
+		t.Errorf("incorrect body:\n%s", w.Body.String())
+	}
+}
+
+func TestServe(t *testing.T) {
+	r := NewRegistry()
+	if err := Serve("256.0.0.1:0", r); err == nil {
+		t.Errorf("expected an error for an invalid address")
+	}
+}
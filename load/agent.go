@@ -0,0 +1,77 @@
+package load
+
+import (
+	"time"
+
+	"github.com/HdrHistogram/hdrhistogram-go"
+	"github.com/timescale/tsbs/internal/coordinator"
+	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+// joinCoordinator registers the loader as an agent with the coordinator.
+// Only the agent assigned to create the database may create it.
+func (l *CommonBenchmarkRunner) joinCoordinator() {
+	a, err := coordinator.Dial(l.Coordinator, coordinator.KindLoad)
+	if err != nil {
+		fatal("%v", err)
+		return
+	}
+	l.agent = a
+	if !a.CreateDB {
+		l.DoCreateDB = false
+		l.DoAbortOnExist = false
+	}
+	printFn("loading shard %d of %d\n", a.Agent, a.Agents)
+}
+
+// waitForAgents blocks until all agents arrived at the barrier name
+func (l *CommonBenchmarkRunner) waitForAgents(name string) {
+	if err := l.agent.Wait(name); err != nil {
+		fatal("could not wait for the other agents: %v", err)
+	}
+}
+
+// sendResult sends the counts and the batch latencies of the load to the coordinator
+func (l *CommonBenchmarkRunner) sendResult(start, end time.Time) {
+	metricCnt, rowCnt := l.measuredCounts()
+	r := &coordinator.Result{
+		Start:      start,
+		End:        end,
+		Counts:     map[string]uint64{"metrics": metricCnt, "rows": rowCnt},
+		Histograms: map[string]*hdrhistogram.Snapshot{"all": l.latencies.overall().Export()},
+	}
+	if err := l.agent.Done(r); err != nil {
+		fatal("could not send the result to the coordinator: %v", err)
+	}
+}
+
+// dataSource returns the DataSource of b, decoded in parallel if asked for,
+// and reduced to the shard of the agent if the loader is an agent of a
+// coordinator
+func (l *CommonBenchmarkRunner) dataSource(b targets.Benchmark) targets.DataSource {
+	ds := l.parallelDecoding(b.GetDataSource())
+	if l.agent == nil || l.agent.Agents == 1 {
+		return ds
+	}
+	return &shardedDataSource{DataSource: ds, agent: l.agent}
+}
+
+// shardedDataSource only returns the points of the shard of an agent, every
+// Agents-th point of the wrapped DataSource
+type shardedDataSource struct {
+	targets.DataSource
+	agent *coordinator.Agent
+	n     uint64
+}
+
+func (ds *shardedDataSource) NextItem() data.LoadedPoint {
+	for {
+		item := ds.DataSource.NextItem()
+		n := ds.n
+		ds.n++
+		if item.Data == nil || ds.agent.InShard(n) {
+			return item
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package load
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+
+	"github.com/timescale/tsbs/internal/coordinator"
+)
+
+func TestShardedDataSource(t *testing.T) {
+	ds := &shardedDataSource{
+		DataSource: &testDataSource{br: bufio.NewReader(bytes.NewReader([]byte("abcdefg")))},
+		agent:      &coordinator.Agent{Assignment: coordinator.Assignment{Agent: 1, Agents: 3}},
+	}
+	var got []byte
+	for item := ds.NextItem(); item.Data != nil; item = ds.NextItem() {
+		got = append(got, item.Data.(byte))
+	}
+	if string(got) != "be" {
+		t.Errorf("incorrect points of the shard: got %q want %q", got, "be")
+	}
+}
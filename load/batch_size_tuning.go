@@ -0,0 +1,225 @@
+package load
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+const (
+	defaultTuneMinBatchSize = 100
+	defaultTuneMaxBatchSize = 100000
+	defaultTuneProbeItems   = 100000
+	// tuneBatchesPerWorker is the least number of batches each worker
+	// processes in a probe
+	tuneBatchesPerWorker = 3
+	// tuneMinGain is the gain in throughput over the best probe so far for a
+	// larger batch size to count as an improvement
+	tuneMinGain = 0.05
+	// tuneMaxMisses is the number of probes in a row without improvement
+	// after which the knee is passed
+	tuneMaxMisses = 2
+)
+
+// AddBatchSizeTuningFlags adds the flags of the batch size tuning phase to
+// the flag set, with the given prefix
+func AddBatchSizeTuningFlags(fs *pflag.FlagSet, flagPrefix string) {
+	fs.Bool(flagPrefix+"tune-batch-size", false, "Probe batch sizes before the measured load and use the one where the throughput stops improving, instead of batch-size")
+	fs.Uint(flagPrefix+"tune-min-batch-size", defaultTuneMinBatchSize, "Smallest batch size to probe, doubled for every following probe")
+	fs.Uint(flagPrefix+"tune-max-batch-size", defaultTuneMaxBatchSize, "Largest batch size to probe")
+	fs.Uint64(flagPrefix+"tune-probe-items", defaultTuneProbeItems, "Least number of items to load with each probed batch size")
+}
+
+// batchSizeProbe is the throughput measured with one batch size
+type batchSizeProbe struct {
+	BatchSize  uint    `json:"batchSize"`
+	Items      uint64  `json:"items"`
+	Metrics    uint64  `json:"metrics"`
+	MetricRate float64 `json:"metricRate"`
+}
+
+// batchSizeTuner probes batch sizes, doubling from the smallest one, until
+// the throughput stops improving. The throughput of a probe is computed from
+// the ProcessBatch durations of its batches, as the rate all workers reach
+// when they are kept busy, so that workers waiting for the last batches of a
+// probe do not count against large batch sizes.
+type batchSizeTuner struct {
+	min, max   uint
+	probeItems uint64
+	workers    uint
+
+	// active is set while tuning. The workers only record their batches
+	// then, and are idle whenever it changes.
+	active bool
+	// metrics and busy are the metrics and the ProcessBatch durations of the
+	// current probe
+	mu      sync.Mutex
+	metrics uint64
+	busy    time.Duration
+
+	curve  []batchSizeProbe
+	chosen uint
+	// items, tunedMetrics and tunedRows are the counts loaded while tuning,
+	// and took its duration
+	items        uint64
+	tunedMetrics uint64
+	tunedRows    uint64
+	took         time.Duration
+}
+
+func newBatchSizeTuner(c BenchmarkRunnerConfig) (*batchSizeTuner, error) {
+	t := &batchSizeTuner{
+		min:        c.TuneMinBatchSize,
+		max:        c.TuneMaxBatchSize,
+		probeItems: c.TuneProbeItems,
+		workers:    c.Workers,
+	}
+	if t.min == 0 {
+		t.min = defaultTuneMinBatchSize
+	}
+	if t.max == 0 {
+		t.max = defaultTuneMaxBatchSize
+	}
+	if t.probeItems == 0 {
+		t.probeItems = defaultTuneProbeItems
+	}
+	if t.workers == 0 {
+		t.workers = 1
+	}
+	if t.min > t.max {
+		return nil, fmt.Errorf("tune-min-batch-size %d is larger than tune-max-batch-size %d", t.min, t.max)
+	}
+	return t, nil
+}
+
+// record records a batch processed in took while tuning
+func (t *batchSizeTuner) record(metrics uint64, took time.Duration) {
+	t.mu.Lock()
+	t.metrics += metrics
+	t.busy += took
+	t.mu.Unlock()
+}
+
+// itemsFor returns the number of items to probe batch size size with
+func (t *batchSizeTuner) itemsFor(size uint) uint64 {
+	items := uint64(size) * uint64(t.workers) * tuneBatchesPerWorker
+	if items < t.probeItems {
+		return t.probeItems
+	}
+	return items
+}
+
+// tune probes batch sizes with probe, which loads up to items items in
+// batches of size and returns the number of items it read, once all of its
+// batches are processed. limit is the number of items left to load (0 = all
+// of them). tune returns the number of items read, and whether the data ran
+// out while tuning.
+func (t *batchSizeTuner) tune(limit uint64, probe func(size uint, items uint64) uint64) (uint64, bool) {
+	start := time.Now()
+	t.active = true
+	defer func() { t.active = false }()
+	best, misses := 0.0, 0
+	exhausted := false
+	for size := t.min; size <= t.max && misses < tuneMaxMisses; size *= 2 {
+		items := t.itemsFor(size)
+		if limit > 0 && t.items+items >= limit {
+			items = limit - t.items
+			exhausted = true
+		}
+		t.mu.Lock()
+		t.metrics, t.busy = 0, 0
+		t.mu.Unlock()
+
+		read := probe(size, items)
+		t.items += read
+
+		t.mu.Lock()
+		p := batchSizeProbe{BatchSize: size, Items: read, Metrics: t.metrics}
+		if t.busy > 0 {
+			p.MetricRate = float64(t.metrics) * float64(t.workers) / t.busy.Seconds()
+		}
+		t.mu.Unlock()
+		t.curve = append(t.curve, p)
+
+		if read < items {
+			exhausted = true
+		}
+		if p.MetricRate > best*(1+tuneMinGain) {
+			misses = 0
+		} else {
+			misses++
+		}
+		if p.MetricRate > best {
+			best = p.MetricRate
+		}
+		if exhausted {
+			break
+		}
+	}
+	t.chosen = t.knee(best)
+	t.took = time.Since(start)
+	return t.items, exhausted
+}
+
+// knee returns the smallest probed batch size that reached the best
+// throughput, within tuneMinGain
+func (t *batchSizeTuner) knee(best float64) uint {
+	for _, p := range t.curve {
+		if p.MetricRate >= best*(1-tuneMinGain) {
+			return p.BatchSize
+		}
+	}
+	return t.min
+}
+
+// totals returns the curve and the chosen batch size for the results file
+func (t *batchSizeTuner) totals() map[string]interface{} {
+	return map[string]interface{}{
+		"curve":          t.curve,
+		"batchSize":      t.chosen,
+		"items":          t.items,
+		"metrics":        t.tunedMetrics,
+		"rows":           t.tunedRows,
+		"durationMillis": t.took.Milliseconds(),
+	}
+}
+
+// tuneBatchSize runs the tuning phase with probe and locks in the chosen
+// batch size. The counts of the tuning phase are set aside, so that the
+// results only cover the measured phase, which starts anew at start. It
+// returns the number of items left to load (0 = all of them), and whether
+// the data ran out while tuning.
+func (l *CommonBenchmarkRunner) tuneBatchSize(start *time.Time, limit uint64, probe func(size uint, items uint64) uint64) (uint64, bool) {
+	t := l.batchSizeTuner
+	printFn("tuning the batch size from %d to %d\n", t.min, t.max)
+	read, exhausted := t.tune(limit, probe)
+	for _, p := range t.curve {
+		printFn("batch size %d: %d items, %0.2f metrics/sec\n", p.BatchSize, p.Items, p.MetricRate)
+	}
+	printFn("chosen batch size: %d\n", t.chosen)
+	l.BatchSize = t.chosen
+
+	// all the batches of the probes are processed, so the workers are idle
+	t.tunedMetrics, t.tunedRows = l.metricCnt, l.rowCnt
+	l.latencies = newBatchLatencies(l.Workers)
+	l.pipeline.reset()
+	if l.rateController != nil {
+		l.rateController.Start()
+	}
+	*start = time.Now()
+	if limit > 0 {
+		limit -= read
+	}
+	return limit, exhausted
+}
+
+// measuredCounts returns the metrics and rows loaded in the measured phase,
+// without the ones loaded while tuning the batch size
+func (l *CommonBenchmarkRunner) measuredCounts() (uint64, uint64) {
+	if l.batchSizeTuner == nil {
+		return l.metricCnt, l.rowCnt
+	}
+	return l.metricCnt - l.batchSizeTuner.tunedMetrics, l.rowCnt - l.batchSizeTuner.tunedRows
+}
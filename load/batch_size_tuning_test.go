@@ -0,0 +1,145 @@
+package load
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/timescale/tsbs/load/insertstrategy"
+)
+
+// probeWithRates returns a probe that processes its items in batches that
+// each take as long as the rate of its batch size in rates allows
+func probeWithRates(t *batchSizeTuner, rates map[uint]float64, available uint64) func(uint, uint64) uint64 {
+	return func(size uint, items uint64) uint64 {
+		if items > available {
+			items = available
+		}
+		available -= items
+		for left := items; left > 0; {
+			n := uint64(size)
+			if left < n {
+				n = left
+			}
+			t.record(n, time.Duration(float64(n)/rates[size]*float64(time.Second)))
+			left -= n
+		}
+		return items
+	}
+}
+
+func TestBatchSizeTuner(t *testing.T) {
+	tuner, err := newBatchSizeTuner(BenchmarkRunnerConfig{TuneMinBatchSize: 100, TuneMaxBatchSize: 10000, TuneProbeItems: 1000, Workers: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rates := map[uint]float64{100: 1000, 200: 2000, 400: 3000, 800: 3050, 1600: 3100, 3200: 1000}
+	read, exhausted := tuner.tune(0, probeWithRates(tuner, rates, 1e9))
+	if exhausted {
+		t.Errorf("unexpected end of the data")
+	}
+	// the probes stop after two without improvement: 800 and 1600
+	if got := len(tuner.curve); got != 5 {
+		t.Fatalf("incorrect number of probes: got %d want 5", got)
+	}
+	// 800 and 1600 are within the minimum gain of 400
+	if tuner.chosen != 400 {
+		t.Errorf("incorrect batch size chosen: got %d want 400", tuner.chosen)
+	}
+	// probes load at least 3 batches per worker
+	if want := uint64(1000 + 1200 + 2400 + 4800 + 9600); read != want {
+		t.Errorf("incorrect items read: got %d want %d", read, want)
+	}
+	// the throughput of all workers, as if they are kept busy
+	if got := tuner.curve[0].MetricRate; got < 1999 || got > 2001 {
+		t.Errorf("incorrect rate of the first probe: got %0.2f want 2000", got)
+	}
+	if tuner.active {
+		t.Errorf("tuner still active after tuning")
+	}
+}
+
+func TestBatchSizeTunerLimit(t *testing.T) {
+	tuner, err := newBatchSizeTuner(BenchmarkRunnerConfig{TuneMinBatchSize: 100, TuneMaxBatchSize: 10000, TuneProbeItems: 1000, Workers: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rates := map[uint]float64{100: 1000, 200: 2000, 400: 3000}
+	read, exhausted := tuner.tune(1500, probeWithRates(tuner, rates, 1e9))
+	if !exhausted || read != 1500 {
+		t.Errorf("incorrect end of tuning at the limit: got %d items, exhausted %v", read, exhausted)
+	}
+	if tuner.chosen != 200 {
+		t.Errorf("incorrect batch size chosen: got %d want 200", tuner.chosen)
+	}
+
+	tuner, _ = newBatchSizeTuner(BenchmarkRunnerConfig{TuneMinBatchSize: 100, TuneMaxBatchSize: 10000, TuneProbeItems: 1000, Workers: 1})
+	read, exhausted = tuner.tune(0, probeWithRates(tuner, rates, 1200))
+	if !exhausted || read != 1200 {
+		t.Errorf("incorrect end of tuning at the end of the data: got %d items, exhausted %v", read, exhausted)
+	}
+
+	if _, err = newBatchSizeTuner(BenchmarkRunnerConfig{TuneMinBatchSize: 1000, TuneMaxBatchSize: 100}); err == nil {
+		t.Errorf("unexpected lack of error for an empty range")
+	}
+}
+
+func TestTuneBatchSizeReportedRate(t *testing.T) {
+	var b bytes.Buffer
+	var m sync.Mutex
+	printFn = func(s string, args ...interface{}) (n int, err error) {
+		m.Lock()
+		defer m.Unlock()
+		return fmt.Fprintf(&b, s, args...)
+	}
+	profile, err := insertstrategy.ParseRateProfile("1000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := BenchmarkRunnerConfig{TuneMinBatchSize: 100, TuneMaxBatchSize: 200, TuneProbeItems: 100, Workers: 1}
+	tuner, err := newBatchSizeTuner(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := &CommonBenchmarkRunner{BenchmarkRunnerConfig: config, batchSizeTuner: tuner}
+	l.rateController = insertstrategy.NewRateController(profile)
+
+	period := 50 * time.Millisecond
+	l.reportStop = make(chan struct{})
+	reportDone := make(chan struct{})
+	go func() {
+		l.report(period)
+		close(reportDone)
+	}()
+	// the probes take many more units than the controller counts afterwards
+	l.rateController.Start()
+	l.rateController.Take(100000)
+	time.Sleep(3 * period / 2)
+
+	start := time.Now()
+	l.tuneBatchSize(&start, 0, probeWithRates(tuner, map[uint]float64{100: 1000, 200: 1000}, 1e9))
+	l.rateController.Take(10)
+	time.Sleep(3 * period / 2)
+	close(l.reportStop)
+	<-reportDone
+
+	// the first report after tuning
+	out := b.String()
+	lines := strings.Split(strings.TrimSpace(out[strings.Index(out, "chosen batch size"):]), "\n")
+	if len(lines) < 2 {
+		t.Fatalf("no report after tuning:\n%s", out)
+	}
+	fields := strings.Split(lines[1], ",")
+	rate, err := strconv.ParseFloat(fields[len(fields)-1], 64)
+	if err != nil {
+		t.Fatalf("could not parse the reported rate of %s: %v", lines[1], err)
+	}
+	// 10 units since the restart, at most two periods ago
+	if rate < 10/(2*period.Seconds()) || rate > 10/(period.Seconds()/100) {
+		t.Errorf("incorrect rate reported after tuning: %0.2f", rate)
+	}
+}
@@ -3,6 +3,8 @@ package load
 import (
 	"bufio"
 	"os"
+
+	"github.com/timescale/tsbs/internal/compression"
 )
 
 const (
@@ -10,11 +12,12 @@ const (
 )
 
 // GetBufferedReader returns the buffered Reader that should be used by the file loader
-// if no file name is specified a buffer for STDIN is returned
+// if no file name is specified a buffer for STDIN is returned. Data compressed
+// with gzip, zstd or lz4 is decompressed.
 func GetBufferedReader(fileName string) *bufio.Reader {
 	if len(fileName) == 0 {
 		// Read from STDIN
-		return bufio.NewReaderSize(os.Stdin, defaultReadSize)
+		return decompressed(bufio.NewReaderSize(os.Stdin, defaultReadSize), fileName)
 	}
 	// Read from specified file
 	file, err := os.Open(fileName)
@@ -22,5 +25,19 @@ func GetBufferedReader(fileName string) *bufio.Reader {
 		fatal("cannot open file for read %s: %v", fileName, err)
 		return nil
 	}
-	return bufio.NewReaderSize(file, defaultReadSize)
+	return decompressed(bufio.NewReaderSize(file, defaultReadSize), fileName)
+}
+
+// decompressed returns a buffered Reader of the decompressed data in br, or
+// br itself if the data is not compressed
+func decompressed(br *bufio.Reader, fileName string) *bufio.Reader {
+	r, err := compression.NewReader(br, fileName)
+	if err != nil {
+		fatal("cannot read %s: %v", fileName, err)
+		return nil
+	}
+	if r == br {
+		return br
+	}
+	return bufio.NewReaderSize(r, defaultReadSize)
 }
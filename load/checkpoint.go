@@ -0,0 +1,290 @@
+package load
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+const defaultCheckpointInterval = 10 * time.Second
+
+// checkpoint is the progress of a load written to the checkpoint file. All
+// items before item Items were acknowledged by the workers, and Offset is the
+// byte offset of item Items in the file, or -1 if the DataSource has no
+// offsets. Metrics, Rows and DurationMillis include all previous segments of
+// the load.
+type checkpoint struct {
+	Items          uint64 `json:"items"`
+	Offset         int64  `json:"offset"`
+	Metrics        uint64 `json:"metrics"`
+	Rows           uint64 `json:"rows"`
+	DurationMillis int64  `json:"durationMillis"`
+}
+
+func readCheckpoint(fileName string) (checkpoint, error) {
+	var c checkpoint
+	b, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return c, fmt.Errorf("cannot read checkpoint %s: %v", fileName, err)
+	}
+	if err = json.Unmarshal(b, &c); err != nil {
+		return c, fmt.Errorf("cannot parse checkpoint %s: %v", fileName, err)
+	}
+	return c, nil
+}
+
+// writeCheckpoint replaces the checkpoint file with c. It writes a temporary
+// file first, so that a crash never leaves a partial checkpoint behind.
+func writeCheckpoint(fileName string, c checkpoint) error {
+	b, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	tmp := fileName + ".tmp"
+	if err = ioutil.WriteFile(tmp, b, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, fileName)
+}
+
+// checkpointer keeps track of the items of the DataSource that were
+// acknowledged in order. Batches are acknowledged out of order by the
+// workers, so the checkpoint is the first item of the oldest batch that is
+// still pending, and only the counts of the batches before it are included.
+type checkpointer struct {
+	// base is the progress of the previous segments of the load
+	base  checkpoint
+	start time.Time
+	// limit is the number of items to load over all segments (0 = all of them)
+	limit uint64
+
+	// read is the number of the next item to read, and last, lastOffset and
+	// lastEnd the number, offset and end offset of the last item read. They
+	// are only used by the scanner.
+	read       uint64
+	last       uint64
+	lastOffset int64
+	lastEnd    int64
+
+	mu sync.Mutex
+	// next and nextOffset are the number and offset of the item after the
+	// last item appended to a batch
+	next       uint64
+	nextOffset int64
+	pending    map[*checkpointBatch]struct{}
+	acked      []*checkpointBatch
+	metrics    uint64
+	rows       uint64
+}
+
+func newCheckpointer(base checkpoint, limit uint64) *checkpointer {
+	return &checkpointer{
+		base:       base,
+		start:      time.Now(),
+		limit:      limit,
+		read:       base.Items,
+		next:       base.Items,
+		nextOffset: base.Offset,
+		pending:    make(map[*checkpointBatch]struct{}),
+	}
+}
+
+// resume skips the items of ds loaded in the previous segments, by seeking
+// to the offset of the checkpoint if ds supports it
+func (cp *checkpointer) resume(ds targets.DataSource) error {
+	if cp.base.Items == 0 {
+		return nil
+	}
+	if ods, ok := ds.(targets.OffsetDataSource); ok && cp.base.Offset >= 0 && ods.Offset() >= 0 {
+		printFn("resuming at item %d, byte offset %d\n", cp.base.Items, cp.base.Offset)
+		return ods.ResumeAt(cp.base.Offset)
+	}
+	printFn("resuming at item %d, skipping the items loaded before\n", cp.base.Items)
+	for i := uint64(0); i < cp.base.Items; i++ {
+		if ds.NextItem().Data == nil {
+			return fmt.Errorf("data source ended after %d of the %d items loaded before", i, cp.base.Items)
+		}
+	}
+	return nil
+}
+
+// current returns the checkpoint of the items acknowledged in order so far
+func (cp *checkpointer) current() checkpoint {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	c := checkpoint{Items: cp.next, Offset: cp.nextOffset}
+	for b := range cp.pending {
+		if b.first < c.Items {
+			c.Items, c.Offset = b.first, b.firstOffset
+		}
+	}
+	// batches acknowledged before the checkpoint are final, the others are
+	// kept until the batches before them are acknowledged as well
+	kept := cp.acked[:0]
+	for _, b := range cp.acked {
+		if b.last < c.Items {
+			cp.metrics += b.metrics
+			cp.rows += b.rows
+		} else {
+			kept = append(kept, b)
+		}
+	}
+	cp.acked = kept
+	c.Metrics = cp.base.Metrics + cp.metrics
+	c.Rows = cp.base.Rows + cp.rows
+	c.DurationMillis = cp.base.DurationMillis + time.Since(cp.start).Milliseconds()
+	return c
+}
+
+// ack marks b as acknowledged, with the counts its processing returned
+func (cp *checkpointer) ack(b *checkpointBatch, metrics, rows uint64) {
+	b.metrics, b.rows = metrics, rows
+	cp.mu.Lock()
+	delete(cp.pending, b)
+	cp.acked = append(cp.acked, b)
+	cp.mu.Unlock()
+}
+
+// writePeriodically writes the checkpoint to fileName every interval until done is closed
+func (cp *checkpointer) writePeriodically(fileName string, interval time.Duration, done <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := writeCheckpoint(fileName, cp.current()); err != nil {
+				printFn("could not write checkpoint %s: %v\n", fileName, err)
+			}
+		case <-done:
+			return
+		}
+	}
+}
+
+// checkpointDataSource numbers the items read from the wrapped DataSource
+type checkpointDataSource struct {
+	targets.DataSource
+	cp *checkpointer
+}
+
+func (ds *checkpointDataSource) NextItem() data.LoadedPoint {
+	cp := ds.cp
+	if cp.limit > 0 && cp.read >= cp.limit {
+		return data.LoadedPoint{}
+	}
+	offset := ds.offset()
+	item := ds.DataSource.NextItem()
+	if item.Data == nil {
+		return item
+	}
+	cp.last, cp.lastOffset, cp.lastEnd = cp.read, offset, ds.offset()
+	cp.read++
+	return item
+}
+
+func (ds *checkpointDataSource) offset() int64 {
+	if ods, ok := ds.DataSource.(targets.OffsetDataSource); ok {
+		return ods.Offset()
+	}
+	return -1
+}
+
+// checkpointBatchFactory creates batches that know which items they hold
+type checkpointBatchFactory struct {
+	targets.BatchFactory
+	cp *checkpointer
+}
+
+func (f *checkpointBatchFactory) New() targets.Batch {
+	return &checkpointBatch{Batch: f.BatchFactory.New(), cp: f.cp}
+}
+
+// checkpointBatch is a batch of the items first to last, pending from its
+// first item until the worker that processed it acknowledges it
+type checkpointBatch struct {
+	targets.Batch
+	cp          *checkpointer
+	started     bool
+	first, last uint64
+	firstOffset int64
+	metrics     uint64
+	rows        uint64
+}
+
+func (b *checkpointBatch) Append(item data.LoadedPoint) {
+	cp := b.cp
+	if !b.started {
+		b.started = true
+		b.first, b.firstOffset = cp.last, cp.lastOffset
+	}
+	b.last = cp.last
+	b.Batch.Append(item)
+	cp.mu.Lock()
+	// an item only counts as read once it is in a pending batch
+	cp.pending[b] = struct{}{}
+	cp.next, cp.nextOffset = cp.last+1, cp.lastEnd
+	cp.mu.Unlock()
+}
+
+// useCheckpoints reads the checkpoint to resume from, if any, and starts
+// writing checkpoints, if a checkpoint file is set
+func (l *CommonBenchmarkRunner) useCheckpoints() {
+	if l.CheckpointFile == "" && l.ResumeFrom == "" {
+		return
+	}
+	base := checkpoint{Offset: -1}
+	if l.ResumeFrom != "" {
+		var err error
+		if base, err = readCheckpoint(l.ResumeFrom); err != nil {
+			fatal("%v", err)
+			return
+		}
+	}
+	l.checkpoints = newCheckpointer(base, l.Limit)
+	if l.CheckpointFile != "" {
+		interval := l.CheckpointInterval
+		if interval <= 0 {
+			interval = defaultCheckpointInterval
+		}
+		l.checkpointsDone = make(chan struct{})
+		go l.checkpoints.writePeriodically(l.CheckpointFile, interval, l.checkpointsDone)
+	}
+}
+
+// finishCheckpoints writes the final checkpoint, once all batches are acknowledged
+func (l *CommonBenchmarkRunner) finishCheckpoints() {
+	if l.checkpointsDone == nil {
+		return
+	}
+	close(l.checkpointsDone)
+	if err := writeCheckpoint(l.CheckpointFile, l.checkpoints.current()); err != nil {
+		fatal("could not write checkpoint %s: %v", l.CheckpointFile, err)
+	}
+}
+
+// checkpointed wraps the DataSource and BatchFactory of a load to keep track
+// of its checkpoints, after skipping the items loaded before when resuming.
+// The limit of the load is then enforced by the DataSource.
+func (l *CommonBenchmarkRunner) checkpointed(ds targets.DataSource, bf targets.BatchFactory) (targets.DataSource, targets.BatchFactory, uint64) {
+	if l.checkpoints == nil {
+		return ds, bf, l.Limit
+	}
+	if err := l.checkpoints.resume(ds); err != nil {
+		fatal("could not resume the load: %v", err)
+	}
+	return &checkpointDataSource{DataSource: ds, cp: l.checkpoints}, &checkpointBatchFactory{BatchFactory: bf, cp: l.checkpoints}, 0
+}
+
+// unwrapBatch returns the batch of the target within a checkpointBatch
+func unwrapBatch(batch targets.Batch) (targets.Batch, *checkpointBatch) {
+	if cb, ok := batch.(*checkpointBatch); ok {
+		return cb.Batch, cb
+	}
+	return batch, nil
+}
@@ -0,0 +1,119 @@
+package load
+
+import (
+	"bufio"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckpointerOutOfOrderAcks(t *testing.T) {
+	cp := newCheckpointer(checkpoint{Offset: -1}, 0)
+	ds := &checkpointDataSource{DataSource: &testDataSource{br: bufio.NewReader(bytes.NewReader([]byte("abcde")))}, cp: cp}
+	bf := &checkpointBatchFactory{BatchFactory: &testFactory{}, cp: cp}
+	var batches []*checkpointBatch
+	for _, size := range []int{2, 2, 1} {
+		b := bf.New().(*checkpointBatch)
+		for i := 0; i < size; i++ {
+			b.Append(ds.NextItem())
+		}
+		batches = append(batches, b)
+	}
+
+	check := func(desc string, wantItems, wantMetrics uint64) {
+		c := cp.current()
+		if c.Items != wantItems || c.Metrics != wantMetrics {
+			t.Errorf("%s: incorrect checkpoint: got %d items and %d metrics, want %d items and %d metrics", desc, c.Items, c.Metrics, wantItems, wantMetrics)
+		}
+	}
+	check("nothing acked", 0, 0)
+	cp.ack(batches[1], 20, 0)
+	check("second batch acked", 0, 0)
+	cp.ack(batches[0], 10, 0)
+	check("first two batches acked", 4, 30)
+	cp.ack(batches[2], 5, 0)
+	check("all batches acked", 5, 35)
+}
+
+func TestCheckpointResume(t *testing.T) {
+	dir, err := ioutil.TempDir("", "checkpoint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	fileName := filepath.Join(dir, "checkpoint.json")
+	want := checkpoint{Items: 2, Offset: -1, Metrics: 20, Rows: 2, DurationMillis: 1000}
+	if err = writeCheckpoint(fileName, want); err != nil {
+		t.Fatal(err)
+	}
+
+	printFn = func(string, ...interface{}) (int, error) { return 0, nil }
+	l := &CommonBenchmarkRunner{}
+	l.ResumeFrom = fileName
+	l.Limit = 4
+	l.useCheckpoints()
+	if l.checkpoints.base != want {
+		t.Fatalf("incorrect checkpoint read: got %v want %v", l.checkpoints.base, want)
+	}
+
+	ds, bf, limit := l.checkpointed(&testDataSource{br: bufio.NewReader(bytes.NewReader([]byte("abcdef")))}, &testFactory{})
+	if limit != 0 {
+		t.Errorf("limit of the scan not left to the data source: got %d", limit)
+	}
+	b := bf.New()
+	var got []byte
+	for item := ds.NextItem(); item.Data != nil; item = ds.NextItem() {
+		got = append(got, item.Data.(byte))
+		b.Append(item)
+	}
+	if string(got) != "cd" {
+		t.Errorf("incorrect items after resuming: got %q want %q", got, "cd")
+	}
+
+	inner, cb := unwrapBatch(b)
+	if _, ok := inner.(*testBatch); !ok || cb == nil {
+		t.Fatalf("batch not unwrapped: got %T", inner)
+	}
+	cb.cp.ack(cb, 30, 3)
+	c := l.checkpoints.current()
+	if c.Items != 4 || c.Metrics != 50 || c.Rows != 5 || c.DurationMillis < want.DurationMillis {
+		t.Errorf("incorrect checkpoint after resuming: got %v", c)
+	}
+}
+
+func TestLineOffsets(t *testing.T) {
+	f, err := ioutil.TempFile("", "lines")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	if _, err = f.WriteString("a\nbb\nccc\n"); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	if o := TrackLineOffsets(bufio.NewScanner(bytes.NewReader(nil)), ""); o != nil {
+		t.Errorf("offsets tracked for STDIN")
+	}
+	scanner := bufio.NewScanner(GetBufferedReader(f.Name()))
+	o := TrackLineOffsets(scanner, f.Name())
+	for i := 0; i < 2; i++ {
+		scanner.Scan()
+	}
+	if got := o.Offset(); got != 5 {
+		t.Errorf("incorrect offset after two lines: got %d want 5", got)
+	}
+	scanner, err = o.Reopen(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scanner.Scan()
+	if got := scanner.Text(); got != "bb" {
+		t.Errorf("incorrect line after reopening: got %q want %q", got, "bb")
+	}
+	if got := o.Offset(); got != 5 {
+		t.Errorf("incorrect offset after reopening: got %d want 5", got)
+	}
+}
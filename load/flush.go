@@ -0,0 +1,100 @@
+package load
+
+import (
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+// flushReadAhead is the number of items a flushingDataSource reads ahead of
+// the scanner
+const flushReadAhead = 1000
+
+// AddFlushFlags adds the flag of sending partly filled batches to the flag
+// set, with the given prefix
+func AddFlushFlags(fs *pflag.FlagSet, flagPrefix string) {
+	fs.Duration(flagPrefix+"flush-interval", 0, "Send the partly filled batches once no item arrived for this long, e.g. for real-time data sources (0 = only send full batches)")
+}
+
+// flushingDataSource reads the items of the wrapped DataSource in its own
+// goroutine, so that the scanner can tell when no item arrived for interval
+// and send its partly filled batches instead of holding them back until
+// they are full
+type flushingDataSource struct {
+	targets.DataSource
+	interval time.Duration
+
+	started bool
+	items   chan data.LoadedPoint
+	// flushed is set once a flush was returned, there is only one flush
+	// between two items
+	flushed bool
+}
+
+func newFlushingDataSource(ds targets.DataSource, interval time.Duration) *flushingDataSource {
+	return &flushingDataSource{DataSource: ds, interval: interval}
+}
+
+func (ds *flushingDataSource) start() {
+	ds.started = true
+	ds.items = make(chan data.LoadedPoint, flushReadAhead)
+	go func() {
+		for {
+			item := ds.DataSource.NextItem()
+			if item.Data == nil {
+				close(ds.items)
+				return
+			}
+			ds.items <- item
+		}
+	}()
+}
+
+// NextItem waits for the next item without ever flushing
+func (ds *flushingDataSource) NextItem() data.LoadedPoint {
+	if !ds.started {
+		ds.start()
+	}
+	ds.flushed = false
+	return <-ds.items
+}
+
+// nextItemOrFlush returns the next item, or flush true if no item arrived
+// within the interval since the call
+func (ds *flushingDataSource) nextItemOrFlush() (item data.LoadedPoint, flush bool) {
+	if !ds.started {
+		ds.start()
+	}
+	if ds.flushed {
+		ds.flushed = false
+		return <-ds.items, false
+	}
+	timer := time.NewTimer(ds.interval)
+	defer timer.Stop()
+	select {
+	case item = <-ds.items:
+		return item, false
+	case <-timer.C:
+		ds.flushed = true
+		return data.LoadedPoint{}, true
+	}
+}
+
+// flushing wraps ds in a flushingDataSource if a flush interval is set
+func (l *CommonBenchmarkRunner) flushing(ds targets.DataSource) targets.DataSource {
+	if l.FlushInterval <= 0 {
+		return ds
+	}
+	return newFlushingDataSource(ds, l.FlushInterval)
+}
+
+// nextItem returns the next item of ds, or flush true if ds is a
+// flushingDataSource and no item arrived within its flush interval
+func nextItem(ds targets.DataSource) (item data.LoadedPoint, flush bool) {
+	if fds, ok := ds.(*flushingDataSource); ok {
+		return fds.nextItemOrFlush()
+	}
+	return ds.NextItem(), false
+}
@@ -0,0 +1,51 @@
+package load
+
+import (
+	"bufio"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+func TestScanFlushesPartlyFilledBatches(t *testing.T) {
+	pr, pw := io.Pipe()
+	ds := newFlushingDataSource(&testDataSource{br: bufio.NewReader(pr)}, 10*time.Millisecond)
+	channels := []chan targets.Batch{make(chan targets.Batch, 2)}
+	done := make(chan uint64)
+	go func() {
+		done <- scanWithoutFlowControl(ds, &targets.ConstantIndexer{}, &testFactory{}, channels, 10, 0, nil)
+	}()
+
+	// the items of the first burst must not wait for the batch to be full
+	if _, err := pw.Write([]byte{0x00, 0x01, 0x02}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case b := <-channels[0]:
+		if b.Len() != 3 {
+			t.Errorf("incorrect flushed batch: got %d items want 3", b.Len())
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("partly filled batch was not flushed")
+	}
+
+	if _, err := pw.Write([]byte{0x03, 0x04}); err != nil {
+		t.Fatal(err)
+	}
+	pw.Close()
+	if read := <-done; read != 5 {
+		t.Errorf("incorrect items read: got %d want 5", read)
+	}
+	if b := <-channels[0]; b.Len() != 2 {
+		t.Errorf("incorrect last batch: got %d items want 2", b.Len())
+	}
+}
+
+func TestFlushingDataSourceNextItem(t *testing.T) {
+	ds := newFlushingDataSource(&testDataSource{br: bufio.NewReader(io.MultiReader())}, time.Nanosecond)
+	if item := ds.NextItem(); item.Data != nil {
+		t.Errorf("unexpected item from an empty data source: %v", item.Data)
+	}
+}
@@ -0,0 +1,116 @@
+package insertstrategy
+
+import (
+	"sync"
+	"time"
+)
+
+// maxRateWait is the longest a worker sleeps before checking the bucket again
+const maxRateWait = 100 * time.Millisecond
+
+// RateController is a SleepRegulator that keeps the combined insert rate of
+// all workers at the target rate of a RateProfile. The workers share a token
+// bucket that fills at the target rate: each worker takes the units (points
+// or metrics) of its last batch out of the bucket, and sleeps while the
+// bucket is in debt.
+type RateController struct {
+	profile RateProfile
+	nowFn   nowProviderFn
+
+	mu    sync.Mutex
+	start time.Time
+	// tokens may be negative, when the workers inserted more than the target rate allows
+	tokens float64
+	// filled is the time since start up to which the bucket is filled
+	filled time.Duration
+	taken  uint64
+}
+
+// NewRateController returns a RateController for the target rate profile,
+// which starts now
+func NewRateController(profile RateProfile) *RateController {
+	return &RateController{
+		profile: profile,
+		nowFn:   time.Now,
+		start:   time.Now(),
+	}
+}
+
+// Start restarts the profile now, with an empty bucket
+func (c *RateController) Start() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.start = c.nowFn()
+	c.tokens = 0
+	c.filled = 0
+	c.taken = 0
+}
+
+// fill adds the units the target rate allowed since the last fill. At most a
+// second worth of units accumulates while the workers are slower than the
+// target rate, so that they do not burst once they can keep up again.
+func (c *RateController) fill(now time.Time) {
+	elapsed := now.Sub(c.start)
+	if elapsed <= c.filled {
+		return
+	}
+	c.tokens += c.profile.Total(elapsed) - c.profile.Total(c.filled)
+	if max := c.profile.Rate(elapsed); c.tokens > max {
+		c.tokens = max
+	}
+	c.filled = elapsed
+}
+
+// Take takes n inserted units out of the bucket
+func (c *RateController) Take(n uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.fill(c.nowFn())
+	c.tokens -= float64(n)
+	c.taken += n
+}
+
+// Sleep makes the worker sleep while the bucket is in debt
+func (c *RateController) Sleep(_ int, _ time.Time) {
+	for {
+		c.mu.Lock()
+		now := c.nowFn()
+		c.fill(now)
+		debt := -c.tokens
+		rate := c.profile.Rate(now.Sub(c.start))
+		c.mu.Unlock()
+		if debt <= 0 {
+			return
+		}
+		wait := maxRateWait
+		if rate > 0 {
+			if d := time.Duration(debt / rate * float64(time.Second)); d < wait {
+				wait = d
+			}
+		}
+		time.Sleep(wait)
+	}
+}
+
+// Target returns the target rate per second now
+func (c *RateController) Target() float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.profile.Rate(c.nowFn().Sub(c.start))
+}
+
+// Taken returns the number of units taken out of the bucket since the start
+func (c *RateController) Taken() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.taken
+}
+
+// TakenSinceStart returns the number of units taken out of the bucket since
+// the start, and the time of the start, which changes when the profile is
+// restarted
+func (c *RateController) TakenSinceStart() (uint64, time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.taken, c.start
+}
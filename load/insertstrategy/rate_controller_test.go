@@ -0,0 +1,51 @@
+package insertstrategy
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateControllerBucket(t *testing.T) {
+	now := time.Unix(0, 0)
+	c := NewRateController(constantRate(1000))
+	c.nowFn = func() time.Time { return now }
+	c.Start()
+
+	c.Take(500)
+	if c.tokens != -500 {
+		t.Errorf("incorrect tokens after taking 500: got %f want -500", c.tokens)
+	}
+	now = now.Add(250 * time.Millisecond)
+	c.fill(now)
+	if c.tokens != -250 {
+		t.Errorf("incorrect tokens after 250ms: got %f want -250", c.tokens)
+	}
+	// while no units are taken at most a second worth of tokens accumulates
+	now = now.Add(10 * time.Second)
+	c.fill(now)
+	if c.tokens != 1000 {
+		t.Errorf("incorrect tokens after 10s: got %f want 1000", c.tokens)
+	}
+	if got := c.Taken(); got != 500 {
+		t.Errorf("incorrect units taken: got %d want 500", got)
+	}
+	if got := c.Target(); got != 1000 {
+		t.Errorf("incorrect target rate: got %f want 1000", got)
+	}
+}
+
+func TestRateControllerSleep(t *testing.T) {
+	c := NewRateController(constantRate(10000))
+	c.Start()
+	start := time.Now()
+	c.Sleep(0, start)
+	if took := time.Since(start); took > 50*time.Millisecond {
+		t.Errorf("slept without debt for %v", took)
+	}
+
+	c.Take(1000)
+	c.Sleep(0, start)
+	if took := time.Since(start); took < 90*time.Millisecond {
+		t.Errorf("slept too short to repay 1000 units at 10000/s: %v", took)
+	}
+}
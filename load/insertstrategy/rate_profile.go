@@ -0,0 +1,237 @@
+package insertstrategy
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	profileSeparator    = ":"
+	stepSeparator       = ","
+	durationSeparator   = "/"
+	rateProfileFmtError = "target rate could not be parsed. Required: 'r', 'ramp:r1-r2/d', 'step:r1/d1,r2/d2,...,rn' or 'burst:r1,r2/d1/d2' | r are rates per second, d are durations like '10s'"
+)
+
+// RateProfile is a target rate that changes over the time since the start of a load
+type RateProfile interface {
+	// Rate returns the target rate per second after elapsed time
+	Rate(elapsed time.Duration) float64
+	// Total returns the number of units the target rate allows from the start until elapsed
+	Total(elapsed time.Duration) float64
+}
+
+// ParseRateProfile parses a string representation of a rate profile. It goes like this:
+// '500000' => a constant rate of 500k per second
+// 'ramp:100000-500000/10m' => a rate rising linearly from 100k to 500k per second over 10 minutes, then constant
+// 'step:100000/5m,200000/5m,500000' => 100k per second for 5 minutes, then 200k for 5 minutes, then 500k
+// 'burst:100000,1000000/10s/1m' => 100k per second, except for bursts of 1M per second for the first 10 seconds of every minute
+func ParseRateProfile(s string) (RateProfile, error) {
+	parts := strings.SplitN(s, profileSeparator, 2)
+	if len(parts) == 1 {
+		rate, err := parseRate(s)
+		if err != nil {
+			return nil, err
+		}
+		return constantRate(rate), nil
+	}
+	switch parts[0] {
+	case "constant":
+		rate, err := parseRate(parts[1])
+		if err != nil {
+			return nil, err
+		}
+		return constantRate(rate), nil
+	case "ramp":
+		return parseRamp(parts[1])
+	case "step":
+		return parseSteps(parts[1])
+	case "burst":
+		return parseBurst(parts[1])
+	}
+	return nil, fmt.Errorf("unknown rate profile '%s'. %s", parts[0], rateProfileFmtError)
+}
+
+func parseRate(s string) (float64, error) {
+	rate, err := strconv.ParseFloat(s, 64)
+	if err != nil || rate < 0 {
+		return 0, errors.New(rateProfileFmtError)
+	}
+	return rate, nil
+}
+
+func parseDuration(s string) (time.Duration, error) {
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, errors.New(rateProfileFmtError)
+	}
+	return d, nil
+}
+
+// constantRate is the same rate all the time
+type constantRate float64
+
+func (r constantRate) Rate(time.Duration) float64 {
+	return float64(r)
+}
+
+func (r constantRate) Total(elapsed time.Duration) float64 {
+	return float64(r) * elapsed.Seconds()
+}
+
+// rampRate rises (or falls) linearly from one rate to another over a
+// duration, and stays at the second rate afterwards
+type rampRate struct {
+	from, to float64
+	duration time.Duration
+}
+
+// parseRamp parses 'r1-r2/d'
+func parseRamp(s string) (RateProfile, error) {
+	parts := strings.SplitN(s, durationSeparator, 2)
+	if len(parts) != 2 {
+		return nil, errors.New(rateProfileFmtError)
+	}
+	rates := strings.SplitN(parts[0], rangeSeparator, 2)
+	if len(rates) != 2 {
+		return nil, errors.New(rateProfileFmtError)
+	}
+	r := &rampRate{}
+	var err error
+	if r.from, err = parseRate(rates[0]); err != nil {
+		return nil, err
+	}
+	if r.to, err = parseRate(rates[1]); err != nil {
+		return nil, err
+	}
+	if r.duration, err = parseDuration(parts[1]); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *rampRate) Rate(elapsed time.Duration) float64 {
+	if elapsed >= r.duration {
+		return r.to
+	}
+	return r.from + (r.to-r.from)*elapsed.Seconds()/r.duration.Seconds()
+}
+
+func (r *rampRate) Total(elapsed time.Duration) float64 {
+	if elapsed >= r.duration {
+		return (r.from+r.to)/2*r.duration.Seconds() + r.to*(elapsed-r.duration).Seconds()
+	}
+	return (r.from + r.Rate(elapsed)) / 2 * elapsed.Seconds()
+}
+
+// stepRate is a schedule of rates that each last for a duration, the last
+// one until the end of the load
+type stepRate struct {
+	rates     []float64
+	durations []time.Duration
+}
+
+// parseSteps parses 'r1/d1,r2/d2,...,rn'
+func parseSteps(s string) (RateProfile, error) {
+	steps := strings.Split(s, stepSeparator)
+	r := &stepRate{}
+	for i, step := range steps {
+		parts := strings.SplitN(step, durationSeparator, 2)
+		rate, err := parseRate(parts[0])
+		if err != nil {
+			return nil, err
+		}
+		r.rates = append(r.rates, rate)
+		last := i == len(steps)-1
+		if last != (len(parts) == 1) {
+			// only the last step lasts forever
+			return nil, errors.New(rateProfileFmtError)
+		}
+		if !last {
+			d, err := parseDuration(parts[1])
+			if err != nil {
+				return nil, err
+			}
+			r.durations = append(r.durations, d)
+		}
+	}
+	return r, nil
+}
+
+func (r *stepRate) Rate(elapsed time.Duration) float64 {
+	for i, d := range r.durations {
+		if elapsed < d {
+			return r.rates[i]
+		}
+		elapsed -= d
+	}
+	return r.rates[len(r.rates)-1]
+}
+
+func (r *stepRate) Total(elapsed time.Duration) float64 {
+	total := 0.0
+	for i, d := range r.durations {
+		if elapsed < d {
+			return total + r.rates[i]*elapsed.Seconds()
+		}
+		total += r.rates[i] * d.Seconds()
+		elapsed -= d
+	}
+	return total + r.rates[len(r.rates)-1]*elapsed.Seconds()
+}
+
+// burstRate is a base rate with periodic bursts at a higher rate, at the
+// start of every period
+type burstRate struct {
+	base, peak    float64
+	width, period time.Duration
+}
+
+// parseBurst parses 'r1,r2/d1/d2'
+func parseBurst(s string) (RateProfile, error) {
+	parts := strings.SplitN(s, stepSeparator, 2)
+	if len(parts) != 2 {
+		return nil, errors.New(rateProfileFmtError)
+	}
+	burst := strings.Split(parts[1], durationSeparator)
+	if len(burst) != 3 {
+		return nil, errors.New(rateProfileFmtError)
+	}
+	r := &burstRate{}
+	var err error
+	if r.base, err = parseRate(parts[0]); err != nil {
+		return nil, err
+	}
+	if r.peak, err = parseRate(burst[0]); err != nil {
+		return nil, err
+	}
+	if r.width, err = parseDuration(burst[1]); err != nil {
+		return nil, err
+	}
+	if r.period, err = parseDuration(burst[2]); err != nil {
+		return nil, err
+	}
+	if r.width >= r.period {
+		return nil, errors.New(rateProfileFmtError)
+	}
+	return r, nil
+}
+
+func (r *burstRate) Rate(elapsed time.Duration) float64 {
+	if elapsed%r.period < r.width {
+		return r.peak
+	}
+	return r.base
+}
+
+func (r *burstRate) Total(elapsed time.Duration) float64 {
+	perPeriod := r.peak*r.width.Seconds() + r.base*(r.period-r.width).Seconds()
+	total := float64(elapsed/r.period) * perPeriod
+	phase := elapsed % r.period
+	if phase < r.width {
+		return total + r.peak*phase.Seconds()
+	}
+	return total + r.peak*r.width.Seconds() + r.base*(phase-r.width).Seconds()
+}
@@ -0,0 +1,96 @@
+package insertstrategy
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestParseRateProfile(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		profile   string
+		expectErr bool
+		// rates and totals at the elapsed times
+		elapsed []time.Duration
+		rates   []float64
+		totals  []float64
+	}{
+		{
+			desc:    "constant rate",
+			profile: "500",
+			elapsed: []time.Duration{0, time.Second, time.Hour},
+			rates:   []float64{500, 500, 500},
+			totals:  []float64{0, 500, 1800000},
+		}, {
+			desc:    "explicit constant rate",
+			profile: "constant:10",
+			elapsed: []time.Duration{2 * time.Second},
+			rates:   []float64{10},
+			totals:  []float64{20},
+		}, {
+			desc:    "linear ramp",
+			profile: "ramp:100-300/10s",
+			elapsed: []time.Duration{0, 5 * time.Second, 10 * time.Second, 12 * time.Second},
+			rates:   []float64{100, 200, 300, 300},
+			totals:  []float64{0, 750, 2000, 2600},
+		}, {
+			desc:    "step schedule",
+			profile: "step:100/2s,200/1s,50",
+			elapsed: []time.Duration{time.Second, 2 * time.Second, 2500 * time.Millisecond, 5 * time.Second},
+			rates:   []float64{100, 200, 200, 50},
+			totals:  []float64{100, 200, 300, 500},
+		}, {
+			desc:    "periodic burst",
+			profile: "burst:10,100/1s/5s",
+			elapsed: []time.Duration{500 * time.Millisecond, 3 * time.Second, 5 * time.Second, 5500 * time.Millisecond},
+			rates:   []float64{100, 10, 100, 100},
+			totals:  []float64{50, 120, 140, 190},
+		}, {
+			desc:      "not a rate",
+			profile:   "fast",
+			expectErr: true,
+		}, {
+			desc:      "negative rate",
+			profile:   "-1",
+			expectErr: true,
+		}, {
+			desc:      "unknown profile",
+			profile:   "sine:100",
+			expectErr: true,
+		}, {
+			desc:      "ramp without duration",
+			profile:   "ramp:100-200",
+			expectErr: true,
+		}, {
+			desc:      "step schedule ending with a duration",
+			profile:   "step:100/1s,200/1s",
+			expectErr: true,
+		}, {
+			desc:      "burst longer than its period",
+			profile:   "burst:10,100/1m/10s",
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			p, err := ParseRateProfile(tc.profile)
+			if err != nil && !tc.expectErr {
+				t.Fatalf("unexpected error: %v", err)
+			} else if err == nil && tc.expectErr {
+				t.Fatal("unexpected lack of error")
+			} else if tc.expectErr {
+				return
+			}
+			for i, elapsed := range tc.elapsed {
+				if got := p.Rate(elapsed); math.Abs(got-tc.rates[i]) > 1e-6 {
+					t.Errorf("incorrect rate after %v: got %f want %f", elapsed, got, tc.rates[i])
+				}
+				if got := p.Total(elapsed); math.Abs(got-tc.totals[i]) > 1e-6 {
+					t.Errorf("incorrect total after %v: got %f want %f", elapsed, got, tc.totals[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package load
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/HdrHistogram/hdrhistogram-go"
+)
+
+// latencyQuantiles are the quantiles of the batch latencies in the summary
+// and the results file
+var latencyQuantiles = []struct {
+	name  string
+	value float64
+}{{"q0", 0}, {"q50", 50}, {"q95", 95}, {"q99", 99}, {"q999", 99.9}, {"q100", 100}}
+
+// batchLatencies keeps a High Dynamic Range (HDR) histogram of the ProcessBatch
+// latencies of each worker. Each histogram is only written by its own worker,
+// and read once all the workers are done.
+type batchLatencies struct {
+	workers []*hdrhistogram.Histogram
+}
+
+func newBatchLatencies(workers uint) *batchLatencies {
+	l := &batchLatencies{workers: make([]*hdrhistogram.Histogram, workers)}
+	for i := range l.workers {
+		l.workers[i] = newLatencyHistogram()
+	}
+	return l
+}
+
+// newLatencyHistogram returns a histogram of latencies in microseconds,
+// from 1 microsecond up to an hour, with 3 significant digits
+func newLatencyHistogram() *hdrhistogram.Histogram {
+	return hdrhistogram.New(1, int64(time.Hour/time.Microsecond), 3)
+}
+
+// record records the latency of a batch processed by worker workerNum
+func (l *batchLatencies) record(workerNum uint, took time.Duration) {
+	// latencies above the highest trackable value are recorded as an hour
+	_ = l.workers[workerNum].RecordValue(took.Microseconds())
+}
+
+// overall returns the latencies of all the workers in one histogram
+func (l *batchLatencies) overall() *hdrhistogram.Histogram {
+	all := newLatencyHistogram()
+	for _, h := range l.workers {
+		all.Merge(h)
+	}
+	return all
+}
+
+// quantiles returns the latency quantiles of h in milliseconds
+func quantiles(h *hdrhistogram.Histogram) map[string]float64 {
+	m := make(map[string]float64, len(latencyQuantiles))
+	for _, q := range latencyQuantiles {
+		m[q.name] = float64(h.ValueAtQuantile(q.value)) / 1e3
+	}
+	return m
+}
+
+// totals returns the quantiles of the overall latencies, under "all", and of
+// the latencies of each worker, under "worker<n>", for the results file
+func (l *batchLatencies) totals() map[string]interface{} {
+	totals := map[string]interface{}{"all": quantiles(l.overall())}
+	for i, h := range l.workers {
+		totals[fmt.Sprintf("worker%d", i)] = quantiles(h)
+	}
+	return totals
+}
+
+// write writes the overall latencies followed by the latencies of each
+// worker, if there is more than one
+func (l *batchLatencies) write(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "batch latencies:\n%-10s: %s\n", "all", latencyString(l.overall())); err != nil {
+		return err
+	}
+	if len(l.workers) < 2 {
+		return nil
+	}
+	for i, h := range l.workers {
+		name := fmt.Sprintf("worker %d", i)
+		if _, err := fmt.Fprintf(w, "%-10s: %s\n", name, latencyString(h)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func latencyString(h *hdrhistogram.Histogram) string {
+	return fmt.Sprintf("min: %8.2fms, med: %8.2fms, p95: %8.2fms, p99: %8.2fms, max: %8.2fms, mean: %8.2fms, count: %d",
+		float64(h.Min())/1e3,
+		float64(h.ValueAtQuantile(50))/1e3,
+		float64(h.ValueAtQuantile(95))/1e3,
+		float64(h.ValueAtQuantile(99))/1e3,
+		float64(h.Max())/1e3,
+		h.Mean()/1e3,
+		h.TotalCount())
+}
+
+// writeHDRFile writes the percentile distribution of the overall latencies in
+// milliseconds to fileName, in the format of the HdrHistogram tools
+func (l *batchLatencies) writeHDRFile(fileName string) error {
+	f, err := os.Create(fileName)
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(f)
+	if _, err = l.overall().PercentilesPrint(bw, 10, 1000.0); err != nil {
+		f.Close()
+		return err
+	}
+	if err = bw.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,76 @@
+package load
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBatchLatencies(t *testing.T) {
+	l := newBatchLatencies(2)
+	for i := 1; i <= 100; i++ {
+		l.record(0, time.Duration(i)*time.Millisecond)
+	}
+	l.record(1, 2*time.Second)
+
+	all := l.overall()
+	if got := all.TotalCount(); got != 101 {
+		t.Errorf("incorrect overall count: got %d want 101", got)
+	}
+
+	totals := l.totals()
+	if len(totals) != 3 {
+		t.Errorf("incorrect number of totals: got %d want 3", len(totals))
+	}
+	worker0 := totals["worker0"].(map[string]float64)
+	if got := worker0["q50"]; got < 49.9 || got > 50.1 {
+		t.Errorf("incorrect median of worker 0: got %f want 50", got)
+	}
+	if got := totals["all"].(map[string]float64)["q100"]; got < 1999 || got > 2001 {
+		t.Errorf("incorrect max overall: got %f want 2000", got)
+	}
+
+	var b strings.Builder
+	if err := l.write(&b); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+	if len(lines) != 4 || !strings.HasPrefix(lines[1], "all") || !strings.HasPrefix(lines[3], "worker 1") {
+		t.Errorf("incorrect output:\n%s", b.String())
+	}
+
+	b.Reset()
+	one := newBatchLatencies(1)
+	one.record(0, time.Millisecond)
+	if err := one.write(&b); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(b.String(), "worker") {
+		t.Errorf("single worker latencies should not be printed separately:\n%s", b.String())
+	}
+}
+
+func TestBatchLatenciesWriteHDRFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "latencies")
+	if err != nil {
+		t.Fatalf("could not create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	l := newBatchLatencies(1)
+	l.record(0, 5*time.Millisecond)
+	fileName := filepath.Join(dir, "hdr.txt")
+	if err := l.writeHDRFile(fileName); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	b, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		t.Fatalf("could not read HDR file: %v", err)
+	}
+	if !strings.Contains(string(b), "Value") || !strings.Contains(string(b), "#[Max") {
+		t.Errorf("incorrect HDR file:\n%s", b)
+	}
+}
@@ -0,0 +1,72 @@
+package load
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/timescale/tsbs/internal/compression"
+)
+
+// LineOffsets keeps track of the byte offset of the next line a bufio.Scanner
+// reads from a file, so that loads from line based files can be checkpointed
+// and resumed at a byte offset
+type LineOffsets struct {
+	fileName string
+	offset   int64
+}
+
+// TrackLineOffsets makes scanner, which reads fileName from its start, split
+// lines while keeping track of their offsets. It returns nil if the data is
+// read from STDIN, which cannot be seeked.
+func TrackLineOffsets(scanner *bufio.Scanner, fileName string) *LineOffsets {
+	if len(fileName) == 0 {
+		return nil
+	}
+	o := &LineOffsets{fileName: fileName}
+	scanner.Split(o.scanLines)
+	return o
+}
+
+func (o *LineOffsets) scanLines(data []byte, atEOF bool) (int, []byte, error) {
+	advance, token, err := bufio.ScanLines(data, atEOF)
+	o.offset += int64(advance)
+	return advance, token, err
+}
+
+// Offset returns the byte offset of the next line
+func (o *LineOffsets) Offset() int64 {
+	return o.offset
+}
+
+// Reopen reopens the file and returns a scanner of its lines from offset on.
+// The offsets of compressed files are offsets in the decompressed data, which
+// is read up to offset since compressed files cannot be seeked.
+func (o *LineOffsets) Reopen(offset int64) (*bufio.Scanner, error) {
+	file, err := os.Open(o.fileName)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open file for read %s: %v", o.fileName, err)
+	}
+	br := bufio.NewReaderSize(file, defaultReadSize)
+	r, err := compression.NewReader(br, o.fileName)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("cannot read %s: %v", o.fileName, err)
+	}
+	if r == br {
+		if _, err = file.Seek(offset, io.SeekStart); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("cannot seek to offset %d of %s: %v", offset, o.fileName, err)
+		}
+		br.Reset(file)
+	} else if _, err = io.CopyN(ioutil.Discard, r, offset); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("cannot skip to offset %d of %s: %v", offset, o.fileName, err)
+	}
+	o.offset = offset
+	scanner := bufio.NewScanner(bufio.NewReaderSize(r, defaultReadSize))
+	scanner.Split(o.scanLines)
+	return scanner, nil
+}
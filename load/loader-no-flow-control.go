@@ -27,7 +27,9 @@ func (l *noFlowBenchmarkRunner) RunBenchmark(b targets.Benchmark) {
 		go l.work(b, wg, channels[i%numChannels], i)
 	}
 	// Start scan process - actual data read process
-	scanWithoutFlowControl(b.GetDataSource(), b.GetPointIndexer(numChannels), b.GetBatchFactory(), channels, l.BatchSize, l.Limit)
+	ds, bf, limit := l.checkpointed(l.dataSource(b), b.GetBatchFactory())
+	ds = l.flushing(ds)
+	scanWithoutFlowControl(ds, b.GetPointIndexer(numChannels), bf, channels, l.BatchSize, limit, l.pipeline)
 	for _, c := range channels {
 		close(c)
 	}
@@ -49,15 +51,19 @@ func (l *noFlowBenchmarkRunner) createChannels(numChannels, capacity uint) []cha
 func (l *noFlowBenchmarkRunner) work(b targets.Benchmark, wg *sync.WaitGroup, c <-chan targets.Batch, workerNum uint) {
 	// Prepare processor
 	proc := b.GetProcessor()
+	l.setRetrier(proc)
 	proc.Init(int(workerNum), l.DoLoad, l.HashWorkers)
 
 	// Process batches coming from the incoming queue (c)
+	waitStart := l.pipeline.now()
 	for batch := range c {
+		l.pipeline.since(stageWorkerWait, waitStart)
 		startedWorkAt := time.Now()
-		metricCnt, rowCnt := proc.ProcessBatch(batch, l.DoLoad)
+		metricCnt, rowCnt := l.processBatch(proc, batch, workerNum, startedWorkAt)
 		atomic.AddUint64(&l.metricCnt, metricCnt)
 		atomic.AddUint64(&l.rowCnt, rowCnt)
 		l.timeToSleep(workerNum, startedWorkAt)
+		waitStart = l.pipeline.now()
 	}
 
 	// Close proc if necessary
@@ -7,11 +7,14 @@ import (
 	"io/ioutil"
 	"log"
 	"math/rand"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/spf13/pflag"
+	"github.com/timescale/tsbs/internal/coordinator"
+	"github.com/timescale/tsbs/internal/metrics"
 	"github.com/timescale/tsbs/load/insertstrategy"
 )
 
@@ -21,6 +24,10 @@ const (
 	DefaultChannelCapacityFlagVal   = 0
 	defaultChannelCapacityPerWorker = 5
 	errDBExistsFmt                  = "database \"%s\" exists: aborting."
+
+	// RateUnitMetrics and RateUnitPoints are the units of the target insert rate
+	RateUnitMetrics = "metrics"
+	RateUnitPoints  = "points"
 )
 
 // change for more useful testing
@@ -44,6 +51,41 @@ type BenchmarkRunnerConfig struct {
 	ChannelCapacity uint          `yaml:"channel-capacity" mapstructure:"channel-capacity" json:"channel-capacity"`
 	InsertIntervals string        `yaml:"insert-intervals" mapstructure:"insert-intervals" json:"insert-intervals"`
 	ResultsFile     string        `yaml:"results-file" mapstructure:"results-file" json:"results-file"`
+	// HDRLatenciesFile is the file to write the HDR histogram of the batch latencies to
+	HDRLatenciesFile string `yaml:"hdr-latencies" mapstructure:"hdr-latencies" json:"hdr-latencies"`
+	// MetricsListen is the address to serve the progress of the load on as OpenMetrics
+	MetricsListen string `yaml:"metrics-listen" mapstructure:"metrics-listen" json:"metrics-listen"`
+	// Coordinator is the address of the tsbs_coordinator to run as an agent of
+	Coordinator string `yaml:"coordinator" mapstructure:"coordinator" json:"coordinator"`
+	// MaxRetries is the number of times a failed write of a batch is retried
+	MaxRetries uint `yaml:"max-retries" mapstructure:"max-retries" json:"max-retries"`
+	// RetryBackoff is the wait before the first retry, doubled for every following retry
+	RetryBackoff time.Duration `yaml:"retry-backoff" mapstructure:"retry-backoff" json:"retry-backoff"`
+	// MaxFailedBatches is the number of batches that may fail after all retries before the run is aborted
+	MaxFailedBatches uint64 `yaml:"max-failed-batches" mapstructure:"max-failed-batches" json:"max-failed-batches"`
+	// CheckpointFile is the file to periodically write the progress of the load to
+	CheckpointFile string `yaml:"checkpoint-file" mapstructure:"checkpoint-file" json:"checkpoint-file"`
+	// CheckpointInterval is the period of writing checkpoints
+	CheckpointInterval time.Duration `yaml:"checkpoint-interval" mapstructure:"checkpoint-interval" json:"checkpoint-interval"`
+	// ResumeFrom is the checkpoint file of an earlier, interrupted load to continue
+	ResumeFrom string `yaml:"resume-from" mapstructure:"resume-from" json:"resume-from"`
+	// TargetRate is the profile of the target insert rate of all workers together
+	TargetRate string `yaml:"target-rate" mapstructure:"target-rate" json:"target-rate"`
+	// TargetRateUnit is the unit of TargetRate, metrics or points
+	TargetRateUnit string `yaml:"target-rate-unit" mapstructure:"target-rate-unit" json:"target-rate-unit"`
+	// TuneBatchSize enables probing batch sizes before the measured load, instead of using BatchSize
+	TuneBatchSize bool `yaml:"tune-batch-size" mapstructure:"tune-batch-size" json:"tune-batch-size"`
+	// TuneMinBatchSize and TuneMaxBatchSize are the range of batch sizes to probe
+	TuneMinBatchSize uint `yaml:"tune-min-batch-size" mapstructure:"tune-min-batch-size" json:"tune-min-batch-size"`
+	TuneMaxBatchSize uint `yaml:"tune-max-batch-size" mapstructure:"tune-max-batch-size" json:"tune-max-batch-size"`
+	// TuneProbeItems is the least number of items to load with each probed batch size
+	TuneProbeItems uint64 `yaml:"tune-probe-items" mapstructure:"tune-probe-items" json:"tune-probe-items"`
+	// DecodeWorkers is the number of goroutines decoding the input in parallel (0 = decode in the scanner)
+	DecodeWorkers uint `yaml:"decode-workers" mapstructure:"decode-workers" json:"decode-workers"`
+	// FlushInterval is how long to wait for the next item before sending the partly filled batches, 0 = only send full batches
+	FlushInterval time.Duration `yaml:"flush-interval" mapstructure:"flush-interval" json:"flush-interval"`
+	// PipelineStats enables timing the stages of the pipeline from the DataSource to the database
+	PipelineStats bool `yaml:"pipeline-stats" mapstructure:"pipeline-stats" json:"pipeline-stats"`
 	// deprecated, should not be used in other places other than tsbs_load_xx commands
 	FileName string `yaml:"file" mapstructure:"file" json:"file"`
 	Seed     int64  `yaml:"seed" mapstructure:"seed" json:"seed"`
@@ -62,8 +104,55 @@ func (c BenchmarkRunnerConfig) AddToFlagSet(fs *pflag.FlagSet) {
 	fs.String("file", "", "File name to read data from")
 	fs.Int64("seed", 0, "PRNG seed (default: 0, which uses the current timestamp)")
 	fs.String("insert-intervals", "", "Time to wait between each insert, default '' => all workers insert ASAP. '1,2' = worker 1 waits 1s between inserts, worker 2 and others wait 2s")
+	AddTargetRateFlags(fs, "")
 	fs.Bool("hash-workers", false, "Whether to consistently hash insert data to the same workers (i.e., the data for a particular host always goes to the same worker)")
 	fs.String("results-file", "", "Write the test results summary json to this file")
+	AddReportingFlags(fs, "")
+	AddCoordinatorFlags(fs, "")
+	fs.Bool("pipeline-stats", false, "Time the decoding, batching, waiting and writing of the items, to find out whether the loader or the database is the bottleneck")
+	AddRetryFlags(fs, "")
+	AddCheckpointFlags(fs, "")
+	AddBatchSizeTuningFlags(fs, "")
+	AddParallelDecodingFlags(fs, "")
+	AddFlushFlags(fs, "")
+}
+
+// AddReportingFlags adds the flags of the HDR histogram and OpenMetrics
+// reporting of the load progress to the flag set, with the given prefix
+func AddReportingFlags(fs *pflag.FlagSet, flagPrefix string) {
+	fs.String(flagPrefix+"hdr-latencies", "", "Write the High Dynamic Range (HDR) Histogram of batch write latencies to this file.")
+	fs.String(flagPrefix+"metrics-listen", "", "Serve the progress of the load as OpenMetrics under /metrics on this address, e.g. ':9090'")
+}
+
+// AddCoordinatorFlags adds the flag of loading a shard of the data as an
+// agent of a tsbs_coordinator to the flag set, with the given prefix
+func AddCoordinatorFlags(fs *pflag.FlagSet, flagPrefix string) {
+	fs.String(flagPrefix+"coordinator", "", "Load a shard of the data as an agent of the tsbs_coordinator at this address")
+}
+
+// AddRetryFlags adds the flags of the retry policy for failed writes to the
+// flag set, with the given prefix
+func AddRetryFlags(fs *pflag.FlagSet, flagPrefix string) {
+	fs.Uint(flagPrefix+"max-retries", defaultMaxRetries, "Number of times to retry a failed write of a batch (for targets that support retries)")
+	fs.Duration(flagPrefix+"retry-backoff", defaultRetryBackoff, "Wait before the first retry of a failed write, doubled for every following retry")
+	fs.Uint64(flagPrefix+"max-failed-batches", 0, "Number of batches that may fail after all retries, and are dropped, before aborting the run")
+}
+
+// AddCheckpointFlags adds the flags of checkpointing and resuming loads to the
+// flag set, with the given prefix
+func AddCheckpointFlags(fs *pflag.FlagSet, flagPrefix string) {
+	fs.String(flagPrefix+"checkpoint-file", "", "Periodically write the number of items loaded in order to this file, to resume the load from if it is interrupted")
+	fs.Duration(flagPrefix+"checkpoint-interval", defaultCheckpointInterval, "Period of writing checkpoints")
+	fs.String(flagPrefix+"resume-from", "", "Continue an interrupted load from this checkpoint file, into the existing database")
+}
+
+// AddTargetRateFlags adds the flags of the target insert rate to the flag set,
+// with the given prefix
+func AddTargetRateFlags(fs *pflag.FlagSet, flagPrefix string) {
+	fs.String(flagPrefix+"target-rate", "", "Target insert rate per second of all workers together, instead of insert-intervals. "+
+		"'500000' = constant, 'ramp:100000-500000/10m' = linear ramp, 'step:100000/5m,200000/5m,500000' = step schedule, "+
+		"'burst:100000,1000000/10s/1m' = bursts of 1M/s for 10s every minute")
+	fs.String(flagPrefix+"target-rate-unit", RateUnitMetrics, "Unit of the target rate: 'metrics' or 'points'")
 }
 
 type BenchmarkRunner interface {
@@ -79,6 +168,25 @@ type CommonBenchmarkRunner struct {
 	rowCnt         uint64
 	initialRand    *rand.Rand
 	sleepRegulator insertstrategy.SleepRegulator
+	// rateController is also the sleepRegulator if a target rate is set
+	rateController *insertstrategy.RateController
+	retries        retryPolicy
+	latencies      *batchLatencies
+	// inFlight is the number of batches being processed by the workers
+	inFlight int64
+	// batchDurations is nil unless metrics are served
+	batchDurations *metrics.Histogram
+	// agent is nil unless the loader is an agent of a coordinator
+	agent *coordinator.Agent
+	// checkpoints is nil unless checkpoints are written or the load is resumed
+	checkpoints     *checkpointer
+	checkpointsDone chan struct{}
+	// batchSizeTuner is nil unless the batch size is tuned
+	batchSizeTuner *batchSizeTuner
+	// pipeline is nil unless the stages of the pipeline are timed
+	pipeline *pipelineTimes
+	// reportStop is closed to stop the periodic reports, nil until they start
+	reportStop chan struct{}
 }
 
 // GetBenchmarkRunnerWithBatchSize returns the singleton CommonBenchmarkRunner for use in a benchmark program
@@ -92,9 +200,19 @@ func GetBenchmarkRunner(c BenchmarkRunnerConfig) BenchmarkRunner {
 	}
 
 	loader.initialRand = rand.New(rand.NewSource(loader.Seed))
+	loader.retries = newRetryPolicy(&loader.BenchmarkRunnerConfig)
+	if c.PipelineStats {
+		loader.pipeline = &pipelineTimes{}
+	}
 
 	var err error
-	if c.InsertIntervals == "" {
+	if c.TargetRate != "" {
+		loader.rateController, err = newRateController(c)
+		if err != nil {
+			panic(fmt.Sprintf("could not initialize BenchmarkRunner: %v", err))
+		}
+		loader.sleepRegulator = loader.rateController
+	} else if c.InsertIntervals == "" {
 		loader.sleepRegulator = insertstrategy.NoWait()
 	} else {
 		loader.sleepRegulator, err = insertstrategy.NewSleepRegulator(c.InsertIntervals, int(loader.Workers), loader.initialRand)
@@ -102,6 +220,18 @@ func GetBenchmarkRunner(c BenchmarkRunnerConfig) BenchmarkRunner {
 			panic(fmt.Sprintf("could not initialize BenchmarkRunner: %v", err))
 		}
 	}
+	if c.FlushInterval > 0 && (c.CheckpointFile != "" || c.ResumeFrom != "") {
+		panic("could not initialize BenchmarkRunner: flush-interval can not be used with checkpoints")
+	}
+	if c.TuneBatchSize {
+		if c.NoFlowControl {
+			panic("could not initialize BenchmarkRunner: tune-batch-size requires flow control")
+		}
+		loader.batchSizeTuner, err = newBatchSizeTuner(c)
+		if err != nil {
+			panic(fmt.Sprintf("could not initialize BenchmarkRunner: %v", err))
+		}
+	}
 	if !c.NoFlowControl {
 		return &loader
 	}
@@ -117,21 +247,65 @@ func GetBenchmarkRunner(c BenchmarkRunnerConfig) BenchmarkRunner {
 	return &noFlowBenchmarkRunner{loader}
 }
 
+// newRateController returns the RateController of the target rate of the config
+func newRateController(c BenchmarkRunnerConfig) (*insertstrategy.RateController, error) {
+	if c.InsertIntervals != "" {
+		return nil, fmt.Errorf("insert-intervals and target-rate cannot be used together")
+	}
+	if c.TargetRateUnit != "" && c.TargetRateUnit != RateUnitMetrics && c.TargetRateUnit != RateUnitPoints {
+		return nil, fmt.Errorf("invalid target rate unit '%s': must be '%s' or '%s'", c.TargetRateUnit, RateUnitMetrics, RateUnitPoints)
+	}
+	profile, err := insertstrategy.ParseRateProfile(c.TargetRate)
+	if err != nil {
+		return nil, err
+	}
+	return insertstrategy.NewRateController(profile), nil
+}
+
 // DatabaseName returns the value of the --db-name flag (name of the database to store data)
 func (l *CommonBenchmarkRunner) DatabaseName() string {
 	return l.DBName
 }
 
 func (l *CommonBenchmarkRunner) preRun(b targets.Benchmark) (*sync.WaitGroup, *time.Time) {
+	if l.ResumeFrom != "" {
+		// the items loaded before are in the database already
+		l.DoCreateDB = false
+		l.DoAbortOnExist = false
+	}
+	if l.Coordinator != "" {
+		l.joinCoordinator()
+		// the other agents set up their DBCreator once the database is created
+		if !l.agent.CreateDB {
+			l.waitForAgents(coordinator.BarrierCreated)
+		}
+	}
+
 	// Create required DB
 	if b.GetDBCreator() != nil {
 		cleanupFn := l.useDBCreator(b.GetDBCreator())
 		defer cleanupFn()
 	}
 
+	if l.agent != nil {
+		if l.agent.CreateDB {
+			l.waitForAgents(coordinator.BarrierCreated)
+		}
+		l.waitForAgents(coordinator.BarrierStart)
+	}
+
 	if l.ReportingPeriod.Nanoseconds() > 0 {
+		l.reportStop = make(chan struct{})
 		go l.report(l.ReportingPeriod)
 	}
+	l.latencies = newBatchLatencies(l.Workers)
+	if l.MetricsListen != "" {
+		l.serveMetrics()
+	}
+	l.useCheckpoints()
+	if l.rateController != nil {
+		l.rateController.Start()
+	}
 	wg := &sync.WaitGroup{}
 	wg.Add(int(l.Workers))
 	start := time.Now()
@@ -143,20 +317,44 @@ func (l *CommonBenchmarkRunner) postRun(wg *sync.WaitGroup, start *time.Time) {
 	wg.Wait()
 	end := time.Now()
 	took := end.Sub(*start)
+	if l.reportStop != nil {
+		close(l.reportStop)
+	}
+	l.finishCheckpoints()
 	l.summary(took)
-	if l.BenchmarkRunnerConfig.ResultsFile != "" {
-		metricRate := float64(l.metricCnt) / took.Seconds()
-		rowRate := float64(l.rowCnt) / took.Seconds()
+	if l.HDRLatenciesFile != "" {
+		printFn("Saving High Dynamic Range (HDR) Histogram of batch write latencies to %s\n", l.HDRLatenciesFile)
+		if err := l.latencies.writeHDRFile(l.HDRLatenciesFile); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if l.ResultsFile != "" {
+		metricCnt, rowCnt, took := l.withPreviousSegments(took)
+		metricRate := float64(metricCnt) / took.Seconds()
+		rowRate := float64(rowCnt) / took.Seconds()
 		l.saveTestResult(took, *start, end, metricRate, rowRate)
 	}
+	if l.agent != nil {
+		l.sendResult(*start, end)
+	}
 }
 
 func (l *CommonBenchmarkRunner) saveTestResult(took time.Duration, start time.Time, end time.Time, metricRate, rowRate float64) {
 	totals := make(map[string]interface{})
 	totals["metricRate"] = metricRate
-	if l.rowCnt > 0 {
+	if rowRate > 0 {
 		totals["rowRate"] = rowRate
 	}
+	totals["retriedBatches"], totals["failedBatches"] = l.retries.counts()
+	if l.latencies != nil {
+		totals["batchLatencyQuantiles"] = l.latencies.totals()
+	}
+	if l.pipeline != nil {
+		totals["pipeline"] = l.pipeline.totals(l.Workers, end.Sub(start))
+	}
+	if l.batchSizeTuner != nil {
+		totals["batchSizeTuning"] = l.batchSizeTuner.totals()
+	}
 
 	testResult := LoaderTestResult{
 		ResultFormatVersion: LoaderTestResultVersion,
@@ -199,7 +397,19 @@ func (l *CommonBenchmarkRunner) RunBenchmark(b targets.Benchmark) {
 	}
 
 	// Start scan process - actual data read process
-	scanWithFlowControl(channels, l.BatchSize, l.Limit, b.GetDataSource(), b.GetBatchFactory(), b.GetPointIndexer(uint(len(channels))))
+	ds, bf, limit := l.checkpointed(l.dataSource(b), b.GetBatchFactory())
+	ds = l.flushing(ds)
+	indexer := b.GetPointIndexer(uint(len(channels)))
+	exhausted := false
+	if l.batchSizeTuner != nil {
+		// every probe is a scan of its own, which waits for all of its batches
+		limit, exhausted = l.tuneBatchSize(start, limit, func(size uint, items uint64) uint64 {
+			return scanWithFlowControl(channels, size, items, ds, bf, indexer, l.pipeline)
+		})
+	}
+	if !exhausted {
+		scanWithFlowControl(channels, l.BatchSize, limit, ds, bf, indexer, l.pipeline)
+	}
 	// After scan process completed (no more data to come) - begin shutdown process
 
 	// Close all communication channels to/from workers
@@ -276,17 +486,21 @@ func (l *CommonBenchmarkRunner) work(b targets.Benchmark, wg *sync.WaitGroup, c
 
 	// Prepare processor
 	proc := b.GetProcessor()
+	l.setRetrier(proc)
 	proc.Init(int(workerNum), l.DoLoad, l.HashWorkers)
 
 	// Process batches coming from duplexChannel.toWorker queue
 	// and send ACKs into duplexChannel.toScanner queue
+	waitStart := l.pipeline.now()
 	for batch := range c.toWorker {
+		l.pipeline.since(stageWorkerWait, waitStart)
 		startedWorkAt := time.Now()
-		metricCnt, rowCnt := proc.ProcessBatch(batch, l.DoLoad)
+		metricCnt, rowCnt := l.processBatch(proc, batch, workerNum, startedWorkAt)
 		atomic.AddUint64(&l.metricCnt, metricCnt)
 		atomic.AddUint64(&l.rowCnt, rowCnt)
 		c.sendToScanner()
 		l.timeToSleep(workerNum, startedWorkAt)
+		waitStart = l.pipeline.now()
 	}
 
 	// Close proc if necessary
@@ -298,32 +512,131 @@ func (l *CommonBenchmarkRunner) work(b targets.Benchmark, wg *sync.WaitGroup, c
 	wg.Done()
 }
 
+// setRetrier hands the retry policy to the processor, if it supports retries
+func (l *CommonBenchmarkRunner) setRetrier(proc targets.Processor) {
+	if rp, ok := proc.(targets.RetryingProcessor); ok {
+		rp.SetRetrier(&l.retries)
+	}
+}
+
+// processBatch processes a batch started at startedWorkAt with proc, and
+// records the time the worker took for it
+func (l *CommonBenchmarkRunner) processBatch(proc targets.Processor, batch targets.Batch, workerNum uint, startedWorkAt time.Time) (uint64, uint64) {
+	batch, cb := unwrapBatch(batch)
+	// a processor may empty the batch, so its points are counted before
+	var points uint64
+	if l.rateController != nil && l.TargetRateUnit == RateUnitPoints {
+		points = uint64(batch.Len())
+	}
+	atomic.AddInt64(&l.inFlight, 1)
+	metricCnt, rowCnt := proc.ProcessBatch(batch, l.DoLoad)
+	atomic.AddInt64(&l.inFlight, -1)
+	if cb != nil {
+		cb.cp.ack(cb, metricCnt, rowCnt)
+	}
+	if l.rateController != nil {
+		if l.TargetRateUnit == RateUnitPoints {
+			l.rateController.Take(points)
+		} else {
+			l.rateController.Take(metricCnt)
+		}
+	}
+
+	took := time.Since(startedWorkAt)
+	l.pipeline.add(stageProcess, took)
+	if l.batchSizeTuner != nil && l.batchSizeTuner.active {
+		l.batchSizeTuner.record(metricCnt, took)
+	}
+	if l.latencies != nil {
+		l.latencies.record(workerNum, took)
+	}
+	if l.batchDurations != nil {
+		l.batchDurations.Observe(took.Seconds())
+	}
+	return metricCnt, rowCnt
+}
+
 func (l *CommonBenchmarkRunner) timeToSleep(workerNum uint, startedWorkAt time.Time) {
 	if l.sleepRegulator != nil {
 		l.sleepRegulator.Sleep(int(workerNum), startedWorkAt)
 	}
 }
 
+// withPreviousSegments returns the counts and the duration of the load,
+// including the segments loaded before it was resumed
+func (l *CommonBenchmarkRunner) withPreviousSegments(took time.Duration) (uint64, uint64, time.Duration) {
+	metricCnt, rowCnt := l.measuredCounts()
+	if l.checkpoints == nil {
+		return metricCnt, rowCnt, took
+	}
+	base := l.checkpoints.base
+	return base.Metrics + metricCnt, base.Rows + rowCnt, time.Duration(base.DurationMillis)*time.Millisecond + took
+}
+
 // summary prints the summary of statistics from loading
 func (l *CommonBenchmarkRunner) summary(took time.Duration) {
-	metricRate := float64(l.metricCnt) / took.Seconds()
 	printFn("\nSummary:\n")
-	printFn("loaded %d metrics in %0.3fsec with %d workers (mean rate %0.2f metrics/sec)\n", l.metricCnt, took.Seconds(), l.Workers, metricRate)
-	if l.rowCnt > 0 {
-		rowRate := float64(l.rowCnt) / float64(took.Seconds())
-		printFn("loaded %d rows in %0.3fsec with %d workers (mean rate %0.2f rows/sec)\n", l.rowCnt, took.Seconds(), l.Workers, rowRate)
+	if t := l.batchSizeTuner; t != nil {
+		printFn("tuned the batch size to %d with %d items in %0.3fsec, not included below\n", t.chosen, t.items, t.took.Seconds())
+	}
+	if l.checkpoints != nil && l.checkpoints.base.Items > 0 {
+		base := l.checkpoints.base
+		printFn("resumed after %d items: %d metrics and %d rows were loaded before in %0.3fsec\n", base.Items, base.Metrics, base.Rows, float64(base.DurationMillis)/1e3)
+	}
+	metricCnt, rowCnt, took := l.withPreviousSegments(took)
+	metricRate := float64(metricCnt) / took.Seconds()
+	printFn("loaded %d metrics in %0.3fsec with %d workers (mean rate %0.2f metrics/sec)\n", metricCnt, took.Seconds(), l.Workers, metricRate)
+	if rowCnt > 0 {
+		rowRate := float64(rowCnt) / float64(took.Seconds())
+		printFn("loaded %d rows in %0.3fsec with %d workers (mean rate %0.2f rows/sec)\n", rowCnt, took.Seconds(), l.Workers, rowRate)
+	}
+	if retried, failed := l.retries.counts(); retried > 0 || failed > 0 {
+		printFn("%d batches were retried, %d batches failed and were dropped\n", retried, failed)
+	}
+	if l.latencies != nil {
+		var b strings.Builder
+		_ = l.latencies.write(&b)
+		printFn("%s", b.String())
+	}
+	if l.pipeline != nil {
+		var b strings.Builder
+		_ = l.pipeline.write(&b, l.Workers, took)
+		printFn("%s", b.String())
 	}
 }
 
-// report handles periodic reporting of loading stats
+// report handles periodic reporting of loading stats, until reportStop is closed
 func (l *CommonBenchmarkRunner) report(period time.Duration) {
 	start := time.Now()
 	prevTime := start
 	prevColCount := uint64(0)
 	prevRowCount := uint64(0)
-
-	printFn("time,per. metric/s,metric total,overall metric/s,per. row/s,row total,overall row/s\n")
-	for now := range time.NewTicker(period).C {
+	prevTaken := uint64(0)
+	prevRateStart := time.Time{}
+
+	// the retry counts can only change if failed batches can be retried or dropped
+	withRetries := l.retries.enabled()
+	header := "time,per. metric/s,metric total,overall metric/s,per. row/s,row total,overall row/s"
+	if withRetries {
+		header += ",retried batches,failed batches"
+	}
+	if l.rateController != nil {
+		unit := l.TargetRateUnit
+		if unit == "" {
+			unit = RateUnitMetrics
+		}
+		header += fmt.Sprintf(",target %s/s,per. %s/s", unit, unit)
+	}
+	printFn(header + "\n")
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+	for {
+		var now time.Time
+		select {
+		case <-l.reportStop:
+			return
+		case now = <-ticker.C:
+		}
 		cCount := atomic.LoadUint64(&l.metricCnt)
 		rCount := atomic.LoadUint64(&l.rowCnt)
 
@@ -331,12 +644,33 @@ func (l *CommonBenchmarkRunner) report(period time.Duration) {
 		took := now.Sub(prevTime)
 		colrate := float64(cCount-prevColCount) / float64(took.Seconds())
 		overallColRate := float64(cCount) / float64(sinceStart.Seconds())
+		extraCols := ""
+		if withRetries {
+			retried, failed := l.retries.counts()
+			extraCols = fmt.Sprintf(",%d,%d", retried, failed)
+		}
+		if l.rateController != nil {
+			// the achieved rate in the unit of the target rate. The count
+			// starts anew when the controller is restarted, e.g. after
+			// tuning the batch size, so it is a rate since the restart
+			taken, rateStart := l.rateController.TakenSinceStart()
+			rateTook := took
+			if !rateStart.Equal(prevRateStart) {
+				prevTaken = 0
+				prevRateStart = rateStart
+				if rateStart.After(prevTime) {
+					rateTook = now.Sub(rateStart)
+				}
+			}
+			extraCols += fmt.Sprintf(",%0.2f,%0.2f", l.rateController.Target(), float64(taken-prevTaken)/rateTook.Seconds())
+			prevTaken = taken
+		}
 		if rCount > 0 {
 			rowrate := float64(rCount-prevRowCount) / float64(took.Seconds())
 			overallRowRate := float64(rCount) / float64(sinceStart.Seconds())
-			printFn("%d,%0.2f,%E,%0.2f,%0.2f,%E,%0.2f\n", now.Unix(), colrate, float64(cCount), overallColRate, rowrate, float64(rCount), overallRowRate)
+			printFn("%d,%0.2f,%E,%0.2f,%0.2f,%E,%0.2f%s\n", now.Unix(), colrate, float64(cCount), overallColRate, rowrate, float64(rCount), overallRowRate, extraCols)
 		} else {
-			printFn("%d,%0.2f,%E,%0.2f,-,-,-\n", now.Unix(), colrate, float64(cCount), overallColRate)
+			printFn("%d,%0.2f,%E,%0.2f,-,-,-%s\n", now.Unix(), colrate, float64(cCount), overallColRate, extraCols)
 		}
 
 		prevColCount = cCount
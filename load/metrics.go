@@ -0,0 +1,39 @@
+package load
+
+import (
+	"sync/atomic"
+
+	"github.com/timescale/tsbs/internal/metrics"
+)
+
+// serveMetrics serves the progress of the load on MetricsListen as OpenMetrics
+func (l *CommonBenchmarkRunner) serveMetrics() {
+	if err := metrics.Serve(l.MetricsListen, l.newMetricsRegistry()); err != nil {
+		fatal("%v", err)
+	}
+}
+
+// newMetricsRegistry returns the metrics of the progress of the load, and
+// starts recording the batch durations
+func (l *CommonBenchmarkRunner) newMetricsRegistry() *metrics.Registry {
+	r := metrics.NewRegistry()
+	r.CounterFunc("tsbs_load_metrics", "Number of metrics loaded.", func() float64 {
+		return float64(atomic.LoadUint64(&l.metricCnt))
+	})
+	r.CounterFunc("tsbs_load_rows", "Number of rows loaded.", func() float64 {
+		return float64(atomic.LoadUint64(&l.rowCnt))
+	})
+	r.GaugeFunc("tsbs_load_batches_in_flight", "Number of batches being written by the workers.", func() float64 {
+		return float64(atomic.LoadInt64(&l.inFlight))
+	})
+	r.CounterFunc("tsbs_load_retried_batches", "Number of batches whose write was retried.", func() float64 {
+		retried, _ := l.retries.counts()
+		return float64(retried)
+	})
+	r.CounterFunc("tsbs_load_failed_batches", "Number of batches dropped after all retries failed.", func() float64 {
+		_, failed := l.retries.counts()
+		return float64(failed)
+	})
+	l.batchDurations = r.NewHistogram("tsbs_load_batch_duration_seconds", "Time taken to write a batch.", metrics.DefaultBuckets)
+	return r
+}
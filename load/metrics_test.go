@@ -0,0 +1,54 @@
+package load
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+// inFlightProcessor records the batches in flight while it processes a batch
+type inFlightProcessor struct {
+	testProcessor
+	l        *CommonBenchmarkRunner
+	inFlight int64
+}
+
+func (p *inFlightProcessor) ProcessBatch(targets.Batch, bool) (uint64, uint64) {
+	p.inFlight = p.l.inFlight
+	return 1, 1
+}
+
+func TestMetricsRegistry(t *testing.T) {
+	l := &CommonBenchmarkRunner{}
+	r := l.newMetricsRegistry()
+	if l.batchDurations == nil {
+		t.Fatalf("batch durations are not recorded when metrics are served")
+	}
+
+	p := &inFlightProcessor{l: l}
+	metricCnt, rowCnt := l.processBatch(p, nil, 0, time.Now())
+	if metricCnt != 1 || rowCnt != 1 {
+		t.Errorf("incorrect counts: got %d metrics %d rows want 1 and 1", metricCnt, rowCnt)
+	}
+	if p.inFlight != 1 || l.inFlight != 0 {
+		t.Errorf("incorrect batches in flight: got %d during and %d after the batch", p.inFlight, l.inFlight)
+	}
+	l.metricCnt = 42
+
+	var b strings.Builder
+	if err := r.Write(&b); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"tsbs_load_metrics_total 42\n",
+		"tsbs_load_batches_in_flight 0\n",
+		"tsbs_load_failed_batches_total 0\n",
+		"tsbs_load_batch_duration_seconds_count 1\n",
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("metrics do not contain %q:\n%s", want, b.String())
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package load
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/spf13/pflag"
+	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+// decodeChunkItems is the number of items in each chunk of lines that is
+// decoded at once
+const decodeChunkItems = 1000
+
+// AddParallelDecodingFlags adds the flags of decoding the input in parallel
+// to the flag set, with the given prefix
+func AddParallelDecodingFlags(fs *pflag.FlagSet, flagPrefix string) {
+	fs.Uint(flagPrefix+"decode-workers", 0, "Number of goroutines decoding chunks of the input file in parallel (0 = decode in the scanner)")
+}
+
+// decodeChunk is a chunk of lines of the input, and the items decoded from it
+type decodeChunk struct {
+	lines [][]byte
+	items []data.LoadedPoint
+	// ends are the byte offsets after each item, if the input has offsets
+	ends []int64
+	// decoded is closed once the items are decoded
+	decoded chan struct{}
+}
+
+// parallelDataSource decodes the items of a LineDataSource in parallel. A
+// splitter goroutine reads the lines of the input into chunks of whole items,
+// which a number of decoder goroutines decode. The chunks are handed over in
+// the order of the input if ordered, and as soon as they are decoded
+// otherwise.
+type parallelDataSource struct {
+	targets.LineDataSource
+	decoders int
+	ordered  bool
+
+	started bool
+	chunks  chan *decodeChunk
+	chunk   *decodeChunk
+	next    int
+	// offset is the byte offset after the last item returned, if the input
+	// has offsets
+	offset int64
+}
+
+func newParallelDataSource(ds targets.LineDataSource, decoders int, ordered bool) *parallelDataSource {
+	return &parallelDataSource{LineDataSource: ds, decoders: decoders, ordered: ordered, offset: -1}
+}
+
+func (ds *parallelDataSource) NextItem() data.LoadedPoint {
+	if !ds.started {
+		ds.start()
+	}
+	for ds.chunk == nil || ds.next == len(ds.chunk.items) {
+		c, ok := <-ds.chunks
+		if !ok {
+			return data.LoadedPoint{}
+		}
+		<-c.decoded
+		ds.chunk, ds.next = c, 0
+	}
+	item := ds.chunk.items[ds.next]
+	if ds.chunk.ends != nil {
+		ds.offset = ds.chunk.ends[ds.next]
+	}
+	ds.next++
+	return item
+}
+
+// Offset returns the byte offset of the next item, or -1 if it is unknown
+func (ds *parallelDataSource) Offset() int64 {
+	if !ds.started {
+		if ods, ok := ds.LineDataSource.(targets.OffsetDataSource); ok {
+			return ods.Offset()
+		}
+	}
+	return ds.offset
+}
+
+// ResumeAt continues reading items at byte offset offset, before the first
+// item is read
+func (ds *parallelDataSource) ResumeAt(offset int64) error {
+	ods, ok := ds.LineDataSource.(targets.OffsetDataSource)
+	if !ok || ds.started {
+		return errors.New("cannot resume: the data source cannot seek")
+	}
+	return ods.ResumeAt(offset)
+}
+
+// start starts the splitter and the decoders
+func (ds *parallelDataSource) start() {
+	ds.started = true
+	ods, _ := ds.LineDataSource.(targets.OffsetDataSource)
+	if ods != nil {
+		ds.offset = ods.Offset()
+		if ds.offset < 0 {
+			ods = nil
+		}
+	}
+	ds.chunks = make(chan *decodeChunk, 2*ds.decoders)
+	toDecode := make(chan *decodeChunk, ds.decoders)
+	go func() {
+		for c := ds.split(ods); c != nil; c = ds.split(ods) {
+			if ds.ordered {
+				ds.chunks <- c
+			}
+			toDecode <- c
+		}
+		close(toDecode)
+		if ds.ordered {
+			close(ds.chunks)
+		}
+	}()
+
+	var wg sync.WaitGroup
+	wg.Add(ds.decoders)
+	for i := 0; i < ds.decoders; i++ {
+		go func() {
+			defer wg.Done()
+			for c := range toDecode {
+				ds.decode(c)
+			}
+		}()
+	}
+	if !ds.ordered {
+		go func() {
+			wg.Wait()
+			close(ds.chunks)
+		}()
+	}
+}
+
+// split reads the lines of the next decodeChunkItems items, or returns nil
+// at the end of the input
+func (ds *parallelDataSource) split(ods targets.OffsetDataSource) *decodeChunk {
+	scanner := ds.Lines()
+	perItem := ds.LinesPerItem()
+	var buf []byte
+	var bounds []int
+	var ends []int64
+	for items := 0; items < decodeChunkItems; items++ {
+		for i := 0; i < perItem; i++ {
+			if !scanner.Scan() {
+				if err := scanner.Err(); err != nil {
+					fatal("scan error: %v", err)
+				} else if i > 0 {
+					fatal("input ended within an item")
+				}
+				return ds.newChunk(buf, bounds, ends)
+			}
+			buf = append(buf, scanner.Bytes()...)
+			bounds = append(bounds, len(buf))
+		}
+		if ods != nil {
+			ends = append(ends, ods.Offset())
+		}
+	}
+	return ds.newChunk(buf, bounds, ends)
+}
+
+// newChunk returns the chunk of the lines in buf, each of which ends at its
+// bound, or nil if there are none
+func (ds *parallelDataSource) newChunk(buf []byte, bounds []int, ends []int64) *decodeChunk {
+	if len(bounds) == 0 {
+		return nil
+	}
+	c := &decodeChunk{lines: make([][]byte, len(bounds)), ends: ends, decoded: make(chan struct{})}
+	start := 0
+	for i, end := range bounds {
+		// the lines cannot grow into each other
+		c.lines[i] = buf[start:end:end]
+		start = end
+	}
+	return c
+}
+
+// decode decodes the items of c and hands it over
+func (ds *parallelDataSource) decode(c *decodeChunk) {
+	perItem := ds.LinesPerItem()
+	c.items = make([]data.LoadedPoint, 0, len(c.lines)/perItem)
+	for i := 0; i+perItem <= len(c.lines); i += perItem {
+		c.items = append(c.items, ds.DecodeLines(c.lines[i:i+perItem]))
+	}
+	c.lines = nil
+	close(c.decoded)
+	if !ds.ordered {
+		ds.chunks <- c
+	}
+}
+
+// parallelDecoding returns ds decoding its items with DecodeWorkers
+// goroutines, if it is set and ds can be decoded in parallel. The order of
+// the items is kept if the workers are hashed or checkpoints are used.
+func (l *CommonBenchmarkRunner) parallelDecoding(ds targets.DataSource) targets.DataSource {
+	if l.DecodeWorkers == 0 {
+		return ds
+	}
+	lds, ok := ds.(targets.LineDataSource)
+	if !ok {
+		printFn("decode-workers is ignored: the data source cannot be decoded in parallel\n")
+		return ds
+	}
+	return newParallelDataSource(lds, int(l.DecodeWorkers), l.HashWorkers || l.checkpoints != nil)
+}
@@ -0,0 +1,106 @@
+package load
+
+import (
+	"bufio"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/data/usecases/common"
+)
+
+// testLineDataSource decodes items of a line of tags and a line of fields
+type testLineDataSource struct {
+	scanner *bufio.Scanner
+	offsets *LineOffsets
+}
+
+func newTestLineDataSource(items int) *testLineDataSource {
+	var b strings.Builder
+	for i := 0; i < items; i++ {
+		fmt.Fprintf(&b, "tags,host_%d\ncpu,%d\n", i, i)
+	}
+	ds := &testLineDataSource{scanner: bufio.NewScanner(strings.NewReader(b.String()))}
+	ds.offsets = &LineOffsets{}
+	ds.scanner.Split(ds.offsets.scanLines)
+	return ds
+}
+
+func (ds *testLineDataSource) NextItem() data.LoadedPoint {
+	if !ds.scanner.Scan() {
+		return data.LoadedPoint{}
+	}
+	tags := append([]byte(nil), ds.scanner.Bytes()...)
+	ds.scanner.Scan()
+	return ds.DecodeLines([][]byte{tags, ds.scanner.Bytes()})
+}
+
+func (ds *testLineDataSource) Headers() *common.GeneratedDataHeaders { return nil }
+func (ds *testLineDataSource) Lines() *bufio.Scanner                 { return ds.scanner }
+func (ds *testLineDataSource) LinesPerItem() int                     { return 2 }
+func (ds *testLineDataSource) Offset() int64                         { return ds.offsets.Offset() }
+func (ds *testLineDataSource) ResumeAt(int64) error                  { return nil }
+
+func (ds *testLineDataSource) DecodeLines(lines [][]byte) data.LoadedPoint {
+	return data.NewLoadedPoint(string(lines[0]) + "|" + string(lines[1]))
+}
+
+func TestParallelDataSourceOrdered(t *testing.T) {
+	const items = 2*decodeChunkItems + 10
+	want := newTestLineDataSource(items)
+	ds := newParallelDataSource(newTestLineDataSource(items), 4, true)
+	if got := ds.Offset(); got != 0 {
+		t.Errorf("incorrect offset before the first item: got %d want 0", got)
+	}
+	for i := 0; i < items; i++ {
+		wantItem := want.NextItem()
+		got := ds.NextItem()
+		if got.Data != wantItem.Data {
+			t.Fatalf("incorrect item %d: got %v want %v", i, got.Data, wantItem.Data)
+		}
+		if ds.Offset() != want.Offset() {
+			t.Fatalf("incorrect offset after item %d: got %d want %d", i, ds.Offset(), want.Offset())
+		}
+	}
+	if item := ds.NextItem(); item.Data != nil {
+		t.Errorf("unexpected item after the end: %v", item.Data)
+	}
+}
+
+func TestParallelDataSourceUnordered(t *testing.T) {
+	const items = 3*decodeChunkItems + 1
+	ds := newParallelDataSource(newTestLineDataSource(items), 3, false)
+	var got []string
+	for item := ds.NextItem(); item.Data != nil; item = ds.NextItem() {
+		got = append(got, item.Data.(string))
+	}
+	if len(got) != items {
+		t.Fatalf("incorrect number of items: got %d want %d", len(got), items)
+	}
+	sort.Strings(got)
+	want := make([]string, items)
+	for i := range want {
+		want[i] = fmt.Sprintf("tags,host_%d|cpu,%d", i, i)
+	}
+	sort.Strings(want)
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("incorrect items: got %s want %s", got[i], want[i])
+		}
+	}
+}
+
+func TestParallelDecoding(t *testing.T) {
+	l := &CommonBenchmarkRunner{}
+	ds := newTestLineDataSource(1)
+	if l.parallelDecoding(ds) != ds {
+		t.Errorf("data source decoded in parallel without decode workers")
+	}
+	l.DecodeWorkers, l.HashWorkers = 2, true
+	pds, ok := l.parallelDecoding(ds).(*parallelDataSource)
+	if !ok || !pds.ordered || pds.decoders != 2 {
+		t.Errorf("incorrect parallel data source: %v", pds)
+	}
+}
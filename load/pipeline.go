@@ -0,0 +1,125 @@
+package load
+
+import (
+	"fmt"
+	"io"
+	"sync/atomic"
+	"time"
+)
+
+// pipelineIdleWarnFraction is the fraction of their time the workers may wait
+// for batches before the scanner is reported as the bottleneck
+const pipelineIdleWarnFraction = 0.2
+
+// pipelineStage is a stage of the pipeline from the DataSource to the database
+type pipelineStage int
+
+const (
+	// stageDecode is the scanner reading and decoding items from the DataSource
+	stageDecode pipelineStage = iota
+	// stageAppend is the scanner appending items to batches
+	stageAppend
+	// stageScannerWait is the scanner waiting for the workers to take batches
+	stageScannerWait
+	// stageWorkerWait is the workers waiting for batches from the scanner
+	stageWorkerWait
+	// stageProcess is the workers serializing and writing batches in ProcessBatch
+	stageProcess
+	numPipelineStages
+)
+
+var pipelineStageNames = [numPipelineStages]string{"decode", "append", "scannerWait", "workerWait", "processBatch"}
+
+// pipelineTimes is the time spent in each stage of the pipeline, summed over
+// the workers for the stages of the workers. All methods do nothing on a nil
+// pipelineTimes, so that the stages are only timed if asked for.
+type pipelineTimes struct {
+	nanos [numPipelineStages]int64
+}
+
+// now returns the start of a stage to time
+func (t *pipelineTimes) now() time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return time.Now()
+}
+
+// since adds the time since start to stage
+func (t *pipelineTimes) since(stage pipelineStage, start time.Time) {
+	if t == nil {
+		return
+	}
+	atomic.AddInt64(&t.nanos[stage], int64(time.Since(start)))
+}
+
+// add adds d to stage
+func (t *pipelineTimes) add(stage pipelineStage, d time.Duration) {
+	if t == nil {
+		return
+	}
+	atomic.AddInt64(&t.nanos[stage], int64(d))
+}
+
+// get returns the time spent in stage
+func (t *pipelineTimes) get(stage pipelineStage) time.Duration {
+	return time.Duration(atomic.LoadInt64(&t.nanos[stage]))
+}
+
+// reset clears the times of all stages
+func (t *pipelineTimes) reset() {
+	if t == nil {
+		return
+	}
+	for i := range t.nanos {
+		atomic.StoreInt64(&t.nanos[i], 0)
+	}
+}
+
+// workersIdle returns the fraction of the time of workers workers over took
+// that they waited for batches
+func (t *pipelineTimes) workersIdle(workers uint, took time.Duration) float64 {
+	if workers == 0 || took <= 0 {
+		return 0
+	}
+	return t.get(stageWorkerWait).Seconds() / (float64(workers) * took.Seconds())
+}
+
+// totals returns the seconds spent in each stage for the results file
+func (t *pipelineTimes) totals(workers uint, took time.Duration) map[string]interface{} {
+	totals := make(map[string]interface{}, numPipelineStages+1)
+	for stage, name := range pipelineStageNames {
+		totals[name] = t.get(pipelineStage(stage)).Seconds()
+	}
+	totals["scannerBottleneck"] = t.workersIdle(workers, took) > pipelineIdleWarnFraction
+	return totals
+}
+
+// write writes the time the scanner spent in its stages as a share of took,
+// and the time the workers spent in theirs as a share of their combined
+// time, followed by a warning if the workers were kept waiting by the scanner
+func (t *pipelineTimes) write(w io.Writer, workers uint, took time.Duration) error {
+	share := func(d time.Duration, of float64) float64 {
+		if of <= 0 {
+			return 0
+		}
+		return 100 * d.Seconds() / of
+	}
+	scan := took.Seconds()
+	decode, appends, scannerWait := t.get(stageDecode), t.get(stageAppend), t.get(stageScannerWait)
+	if _, err := fmt.Fprintf(w, "pipeline:\nscanner   : decode: %0.3fsec (%0.1f%%), append: %0.3fsec (%0.1f%%), waiting for workers: %0.3fsec (%0.1f%%)\n",
+		decode.Seconds(), share(decode, scan), appends.Seconds(), share(appends, scan), scannerWait.Seconds(), share(scannerWait, scan)); err != nil {
+		return err
+	}
+	work := float64(workers) * took.Seconds()
+	process, workerWait := t.get(stageProcess), t.get(stageWorkerWait)
+	if _, err := fmt.Fprintf(w, "workers   : process batch: %0.3fsec (%0.1f%%), waiting for batches: %0.3fsec (%0.1f%%)\n",
+		process.Seconds(), share(process, work), workerWait.Seconds(), share(workerWait, work)); err != nil {
+		return err
+	}
+	if idle := t.workersIdle(workers, took); idle > pipelineIdleWarnFraction {
+		_, err := fmt.Fprintf(w, "warning: the workers waited for batches %0.1f%% of the time, the scanner is the bottleneck rather than the database\n", 100*idle)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package load
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+func TestPipelineTimesWrite(t *testing.T) {
+	times := &pipelineTimes{}
+	times.add(stageDecode, 2*time.Second)
+	times.add(stageAppend, time.Second)
+	times.add(stageScannerWait, 500*time.Millisecond)
+	times.add(stageProcess, 12*time.Second)
+	times.add(stageWorkerWait, 8*time.Second)
+
+	var b bytes.Buffer
+	if err := times.write(&b, 2, 10*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	want := "pipeline:\n" +
+		"scanner   : decode: 2.000sec (20.0%), append: 1.000sec (10.0%), waiting for workers: 0.500sec (5.0%)\n" +
+		"workers   : process batch: 12.000sec (60.0%), waiting for batches: 8.000sec (40.0%)\n" +
+		"warning: the workers waited for batches 40.0% of the time, the scanner is the bottleneck rather than the database\n"
+	if got := b.String(); got != want {
+		t.Errorf("incorrect pipeline times\ngot %s\nwant %s", got, want)
+	}
+	if totals := times.totals(2, 10*time.Second); totals["scannerBottleneck"] != true || totals["processBatch"] != 12.0 {
+		t.Errorf("incorrect totals: %v", totals)
+	}
+
+	// the workers are busy: no warning
+	times.reset()
+	times.add(stageProcess, 19*time.Second)
+	times.add(stageWorkerWait, time.Second)
+	b.Reset()
+	if err := times.write(&b, 2, 10*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(b.String(), "warning") {
+		t.Errorf("unexpected warning for busy workers:\n%s", b.String())
+	}
+}
+
+func TestScanWithPipelineTimes(t *testing.T) {
+	channels := []*duplexChannel{newDuplexChannel(1)}
+	ds := &testDataSource{br: bufio.NewReader(bytes.NewReader([]byte{0x00, 0x01, 0x02}))}
+	go _boringWorker(channels[0])
+	times := &pipelineTimes{}
+	read := scanWithFlowControl(channels, 1, 0, ds, &testFactory{}, &targets.ConstantIndexer{}, times)
+	_checkScan(t, "pipeline times", ds.called, read, 3)
+	if times.get(stageDecode) <= 0 || times.get(stageAppend) <= 0 {
+		t.Errorf("scanner stages not timed: decode %v, append %v", times.get(stageDecode), times.get(stageAppend))
+	}
+
+	// the stages are not timed without pipeline times
+	var none *pipelineTimes
+	none.since(stageDecode, none.now())
+	none.add(stageProcess, time.Second)
+}
@@ -0,0 +1,74 @@
+package load
+
+import (
+	"log"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultMaxRetries   = 3
+	defaultRetryBackoff = 100 * time.Millisecond
+	// maxRetryBackoff caps the wait between two attempts of a write
+	maxRetryBackoff = 30 * time.Second
+)
+
+// retryPolicy is the targets.BatchRetrier of the loader. It retries a failed
+// write with an exponential backoff, and counts the batches that needed
+// retries and the batches that failed after all retries. The run is aborted
+// once more than maxFailed batches failed.
+type retryPolicy struct {
+	maxRetries uint
+	backoff    time.Duration
+	maxFailed  uint64
+
+	retried uint64
+	failed  uint64
+}
+
+func newRetryPolicy(c *BenchmarkRunnerConfig) retryPolicy {
+	return retryPolicy{
+		maxRetries: c.MaxRetries,
+		backoff:    c.RetryBackoff,
+		maxFailed:  c.MaxFailedBatches,
+	}
+}
+
+// Do runs write until it succeeds or all its retries failed, returning the
+// last error in that case.
+func (p *retryPolicy) Do(write func() error) error {
+	backoff := p.backoff
+	for attempt := uint(0); ; attempt++ {
+		err := write()
+		if err == nil {
+			return nil
+		}
+		if attempt == p.maxRetries {
+			failed := atomic.AddUint64(&p.failed, 1)
+			if failed > p.maxFailed {
+				fatal("%d batch(es) failed, last error: %v", failed, err)
+			}
+			log.Printf("batch failed after %d attempt(s), dropping it: %v", attempt+1, err)
+			return err
+		}
+		if attempt == 0 {
+			atomic.AddUint64(&p.retried, 1)
+		}
+		log.Printf("batch write failed, retrying in %v: %v", backoff, err)
+		time.Sleep(backoff)
+		backoff *= 2
+		if backoff > maxRetryBackoff {
+			backoff = maxRetryBackoff
+		}
+	}
+}
+
+// enabled returns whether batches can be retried or dropped at all, otherwise
+// the first failed batch aborts the run
+func (p *retryPolicy) enabled() bool {
+	return p.maxRetries > 0 || p.maxFailed > 0
+}
+
+func (p *retryPolicy) counts() (retried, failed uint64) {
+	return atomic.LoadUint64(&p.retried), atomic.LoadUint64(&p.failed)
+}
@@ -0,0 +1,68 @@
+package load
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// failingWrite returns a write that fails the first failures times it is called
+func failingWrite(failures int, calls *int) func() error {
+	return func() error {
+		*calls++
+		if *calls <= failures {
+			return errors.New("write failed")
+		}
+		return nil
+	}
+}
+
+func TestRetryPolicyDo(t *testing.T) {
+	p := &retryPolicy{maxRetries: 2, backoff: time.Millisecond, maxFailed: 1}
+
+	calls := 0
+	if err := p.Do(failingWrite(0, &calls)); err != nil || calls != 1 {
+		t.Errorf("incorrect first attempt: got error %v after %d calls", err, calls)
+	}
+	calls = 0
+	if err := p.Do(failingWrite(2, &calls)); err != nil || calls != 3 {
+		t.Errorf("incorrect retries: got error %v after %d calls", err, calls)
+	}
+	calls = 0
+	if err := p.Do(failingWrite(3, &calls)); err == nil || calls != 3 {
+		t.Errorf("expected an error after all retries failed, got %v after %d calls", err, calls)
+	}
+	if retried, failed := p.counts(); retried != 2 || failed != 1 {
+		t.Errorf("incorrect counts: got %d retried and %d failed want 2 and 1", retried, failed)
+	}
+
+	oldFatal := fatal
+	defer func() { fatal = oldFatal }()
+	fatalCalled := false
+	fatal = func(format string, args ...interface{}) {
+		fatalCalled = true
+	}
+	calls = 0
+	p.Do(failingWrite(3, &calls))
+	if !fatalCalled {
+		t.Errorf("fatal not called when more than max failed batches failed")
+	}
+}
+
+func TestRetryPolicyEnabled(t *testing.T) {
+	testCases := []struct {
+		maxRetries uint
+		maxFailed  uint64
+		want       bool
+	}{
+		{0, 0, false},
+		{1, 0, true},
+		{0, 1, true},
+	}
+	for _, tc := range testCases {
+		p := &retryPolicy{maxRetries: tc.maxRetries, maxFailed: tc.maxFailed}
+		if got := p.enabled(); got != tc.want {
+			t.Errorf("incorrect enabled for %d retries and %d failed: got %v want %v", tc.maxRetries, tc.maxFailed, got, tc.want)
+		}
+	}
+}
@@ -10,9 +10,10 @@ import (
 // readDs does no flow control, if the capacity of a channel is reached, scanning stops for all
 // workers. (should only happen if channel-capacity is low and one worker is unreasonable slower than the rest)
 // in that case just set hash-workers to false and use 1 channel for all workers.
+// The stages of the scanner are timed in times, unless it is nil.
 func scanWithoutFlowControl(
 	ds targets.DataSource, indexer targets.PointIndexer, factory targets.BatchFactory, channels []chan targets.Batch,
-	batchSize uint, limit uint64,
+	batchSize uint, limit uint64, times *pipelineTimes,
 ) uint64 {
 	if batchSize == 0 {
 		panic("batch size can't be 0")
@@ -27,7 +28,21 @@ func scanWithoutFlowControl(
 		if limit > 0 && itemsRead >= limit {
 			break
 		}
-		item := ds.NextItem()
+		decodeStart := times.now()
+		item, flush := nextItem(ds)
+		times.since(stageDecode, decodeStart)
+		if flush {
+			// No item arrived for the flush interval - send the partly filled batches
+			for idx, b := range batches {
+				if b.Len() > 0 {
+					waitStart := times.now()
+					channels[idx] <- b
+					times.since(stageScannerWait, waitStart)
+					batches[idx] = factory.New()
+				}
+			}
+			continue
+		}
 		if item.Data == nil {
 			// Nothing to scan any more - input is empty or failed
 			// Time to exit
@@ -35,18 +50,24 @@ func scanWithoutFlowControl(
 		}
 		itemsRead++
 
+		appendStart := times.now()
 		idx := indexer.GetIndex(item)
 		batches[idx].Append(item)
+		times.since(stageAppend, appendStart)
 
 		if batches[idx].Len() >= batchSize {
+			waitStart := times.now()
 			channels[idx] <- batches[idx]
+			times.since(stageScannerWait, waitStart)
 			batches[idx] = factory.New()
 		}
 	}
 
 	for idx, unfilledBatch := range batches {
 		if unfilledBatch.Len() > 0 {
+			waitStart := times.now()
 			channels[idx] <- unfilledBatch
+			times.since(stageScannerWait, waitStart)
 		}
 	}
 	return itemsRead
@@ -2,6 +2,7 @@ package load
 
 import (
 	"reflect"
+	"time"
 
 	"github.com/timescale/tsbs/pkg/targets"
 )
@@ -42,9 +43,10 @@ func sendOrQueueBatch(ch *duplexChannel, count *int, batch targets.Batch, unsent
 // which are then dispatched to workers (duplexChannel chosen by PointIndexer).
 // Scan does flow control to make sure workers are not left idle for too long
 // and also that the scanning process does not starve them of CPU.
+// The stages of the scanner are timed in times, unless it is nil.
 func scanWithFlowControl(
 	channels []*duplexChannel, batchSize uint, limit uint64,
-	ds targets.DataSource, factory targets.BatchFactory, indexer targets.PointIndexer,
+	ds targets.DataSource, factory targets.BatchFactory, indexer targets.PointIndexer, times *pipelineTimes,
 ) uint64 {
 	var itemsRead uint64
 	numChannels := len(channels)
@@ -101,19 +103,36 @@ func scanWithFlowControl(
 		}
 
 		caseLimit := len(cases)
+		var waitStart time.Time
 		if ocnt >= olimit {
 			// We have too many outstanding batches, wait until one finishes (i.e. no default)
 			caseLimit--
+			waitStart = times.now()
 		}
 
 		// Only receive an 'ok' when it's from a channel, default does not return 'ok'
 		chosen, _, ok := reflect.Select(cases[:caseLimit])
+		if caseLimit < len(cases) {
+			times.since(stageScannerWait, waitStart)
+		}
 		if ok {
 			unsentBatches[chosen] = ackAndMaybeSend(channels[chosen], &ocnt, unsentBatches[chosen])
 		}
 
 		// Prepare new batch - decode new item and append it to batch
-		item := ds.NextItem()
+		decodeStart := times.now()
+		item, flush := nextItem(ds)
+		times.since(stageDecode, decodeStart)
+		if flush {
+			// No item arrived for the flush interval - send the partly filled batches
+			for idx, b := range fillingBatches {
+				if b.Len() > 0 {
+					unsentBatches[idx] = sendOrQueueBatch(channels[idx], &ocnt, b, unsentBatches[idx])
+					fillingBatches[idx] = factory.New()
+				}
+			}
+			continue
+		}
 		if item.Data == nil {
 			// Nothing to scan any more - input is empty or failed
 			// Time to exit
@@ -122,8 +141,10 @@ func scanWithFlowControl(
 		itemsRead++
 
 		// Append new item to batch
+		appendStart := times.now()
 		idx := indexer.GetIndex(item)
 		fillingBatches[idx].Append(item)
+		times.since(stageAppend, appendStart)
 
 		if fillingBatches[idx].Len() >= batchSize {
 			// Batch is full (contains at least batchSize items) - ready to be sent to worker,
@@ -145,6 +166,7 @@ func scanWithFlowControl(
 
 	// Wait until all the outstanding batches get acknowledged,
 	// so we don't prematurely close the acknowledge channels
+	defer times.since(stageScannerWait, times.now())
 	for {
 		if ocnt == 0 {
 			// No outstanding batches any more
@@ -23,7 +23,10 @@ const (
 	UseCaseCPUSingle     = "cpu-single"
 	UseCaseDevops        = "devops"
 	UseCaseIoT           = "iot"
+	UseCaseFinance       = "finance"
 	UseCaseDevopsGeneric = "devops-generic"
+	UseCaseK8s           = "k8s"
+	UseCaseCustom        = "custom"
 )
 
 var UseCaseChoices = []string{
@@ -31,5 +34,8 @@ var UseCaseChoices = []string{
 	UseCaseCPUSingle,
 	UseCaseDevops,
 	UseCaseIoT,
+	UseCaseFinance,
 	UseCaseDevopsGeneric,
+	UseCaseK8s,
+	UseCaseCustom,
 }
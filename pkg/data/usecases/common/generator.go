@@ -3,6 +3,7 @@ package common
 import (
 	"fmt"
 	"github.com/spf13/pflag"
+	"github.com/timescale/tsbs/internal/compression"
 	"github.com/timescale/tsbs/internal/utils"
 	"github.com/timescale/tsbs/pkg/targets/constants"
 	"strings"
@@ -25,7 +26,12 @@ type Tag struct {
 const (
 	errMaxMetricCountValue = "max metric count per host has to be greater than 0"
 	errLogIntervalZero     = "cannot have log interval of 0"
+	errUseCaseFileEmpty    = "the custom use case needs a use case file"
+	errChurnRateValue      = "churn rate has to be between 0 and 1"
+	errWorkersFormatFmt    = "the %s format can not be serialized by more than 1 worker"
 	defaultLogInterval     = 10 * time.Second
+	defaultChurnRate       = 0.001
+	defaultScaleSteps      = 4
 )
 
 // DataGeneratorConfig is the GeneratorConfig that should be used with a
@@ -40,6 +46,21 @@ type DataGeneratorConfig struct {
 	InterleavedGroupID    uint          `yaml:"interleaved-generation-group-id" mapstructure:"interleaved-generation-group-id"`
 	InterleavedNumGroups  uint          `yaml:"interleaved-generation-groups" mapstructure:"interleaved-generation-groups"`
 	MaxMetricCountPerHost uint64        `yaml:"max-metric-count" mapstructure:"max-metric-count"`
+	// UseCaseFile is the YAML schema of the custom use case
+	UseCaseFile string `yaml:"use-case-file" mapstructure:"use-case-file"`
+	// ChurnRate is the fraction of the pods replaced every log interval in the k8s use case
+	ChurnRate float64 `yaml:"churn-rate" mapstructure:"churn-rate"`
+	// ScaleStrategy is how the number of reporting entities changes from the
+	// initial scale to the scale, one of ScaleStrategyChoices
+	ScaleStrategy string `yaml:"scale-strategy" mapstructure:"scale-strategy"`
+	// ScaleSteps is the number of steps of the step scale strategy
+	ScaleSteps uint64 `yaml:"scale-steps" mapstructure:"scale-steps"`
+	// RealTime releases the simulated points at the pace of the log interval,
+	// timestamped with the current time. Only used by simulator data sources.
+	RealTime bool `yaml:"real-time" mapstructure:"real-time"`
+	// Workers is the number of goroutines serializing the generated points,
+	// the points are simulated by one goroutine regardless
+	Workers uint `yaml:"workers" mapstructure:"workers"`
 }
 
 // Validate checks that the values of the DataGeneratorConfig are reasonable.
@@ -63,6 +84,25 @@ func (c *DataGeneratorConfig) Validate() error {
 		return fmt.Errorf(errMaxMetricCountValue)
 	}
 
+	if c.Use == UseCaseCustom && c.UseCaseFile == "" {
+		return fmt.Errorf(errUseCaseFileEmpty)
+	}
+
+	if c.Use == UseCaseK8s && (c.ChurnRate < 0 || c.ChurnRate > 1) {
+		return fmt.Errorf(errChurnRateValue)
+	}
+
+	if _, scaleErr := NewScaleStrategy(c.ScaleStrategy, c.ScaleSteps); scaleErr != nil {
+		return scaleErr
+	}
+
+	if c.Workers == 0 {
+		c.Workers = 1
+	}
+	if c.Workers > 1 && !serializesPointsIndependently(c.Format) {
+		return fmt.Errorf(errWorkersFormatFmt, c.Format)
+	}
+
 	return err
 }
 
@@ -77,6 +117,23 @@ func (c *DataGeneratorConfig) AddToFlagSet(fs *pflag.FlagSet) {
 	fs.Uint("interleaved-generation-groups", 1,
 		"The number of round-robin serialization groups. Use this to scale up data generation to multiple processes.")
 	fs.Uint64("max-metric-count", 100, "Max number of metric fields to generate per host. Used only in devops-generic use-case")
+	fs.String("use-case-file", "", "YAML schema of the measurements to generate. Used only in custom use-case")
+	fs.Float64("churn-rate", defaultChurnRate, "Fraction of the pods replaced by new pods every log interval. Used only in k8s use-case")
+	fs.String("scale-strategy", ScaleStrategyLinear,
+		fmt.Sprintf("How the number of reporting entities changes between -initial-scale and -scale over time. (choices: %s)", strings.Join(ScaleStrategyChoices, ", ")))
+	fs.Uint64("scale-steps", defaultScaleSteps, "Number of steps to grow from -initial-scale to -scale in. Used only with the step scale strategy")
+	fs.Uint("workers", 1, "Number of goroutines serializing the generated points. Only the serialization is parallel, the points are still simulated by one goroutine. The output is the same for any number of workers")
+}
+
+// serializesPointsIndependently returns whether the serializer of format
+// writes each point regardless of the points before it, so the points can be
+// serialized by several workers
+func serializesPointsIndependently(format string) bool {
+	switch format {
+	case constants.FormatAkumuli, constants.FormatPrometheus:
+		return false
+	}
+	return true
 }
 
 const defaultTimeStart = "2016-01-01T00:00:00Z"
@@ -87,6 +144,8 @@ const errBadFormatFmt = "invalid format specified: '%v'"
 
 const errBadUseFmt = "invalid use case specified: '%v'"
 
+const errBadCompressFmt = "invalid compression specified: '%v'"
+
 // GeneratorConfig is an interface that defines a configuration that is used
 // by Generators to govern their behavior. The interface methods provide a way
 // to use the GeneratorConfig with the command-line via flag.FlagSet and
@@ -113,6 +172,8 @@ type BaseConfig struct {
 	Seed  int64
 	Debug int    `yaml:"debug,omitempty" mapstructure:"debug,omitempty"`
 	File  string `yaml:"file,omitempty" mapstructure:"file,omitempty"`
+	// Compress is the compression format of the output, by default the one of the extension of File
+	Compress string `yaml:"compress,omitempty" mapstructure:"compress,omitempty"`
 }
 
 func (c *BaseConfig) AddToFlagSet(fs *pflag.FlagSet) {
@@ -127,6 +188,7 @@ func (c *BaseConfig) AddToFlagSet(fs *pflag.FlagSet) {
 	fs.Int64("seed", 0, "PRNG seed (default: 0, which uses the current timestamp)")
 	fs.Int("debug", 0, "Control level of debug output")
 	fs.String("file", "", "Write the output to this path")
+	fs.String("compress", "", fmt.Sprintf("Compress the output. (choices: %s; default: by the extension of -file, or none)", strings.Join(compression.Formats, ", ")))
 }
 
 func (c *BaseConfig) Validate() error {
@@ -146,6 +208,12 @@ func (c *BaseConfig) Validate() error {
 		return fmt.Errorf(errBadUseFmt, c.Use)
 	}
 
+	if c.Compress == "" {
+		c.Compress = compression.FromFileName(c.File)
+	} else if !utils.IsIn(c.Compress, compression.Formats) {
+		return fmt.Errorf(errBadCompressFmt, c.Compress)
+	}
+
 	return nil
 }
 
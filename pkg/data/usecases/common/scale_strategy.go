@@ -0,0 +1,141 @@
+package common
+
+import (
+	"fmt"
+	"math"
+)
+
+// Scale strategy choices
+const (
+	ScaleStrategyLinear       = "linear"
+	ScaleStrategyExponential  = "exponential"
+	ScaleStrategyStep         = "step"
+	ScaleStrategyDecommission = "decommission"
+)
+
+// ScaleStrategyChoices are the names of the ScaleStrategies of NewScaleStrategy.
+var ScaleStrategyChoices = []string{
+	ScaleStrategyLinear,
+	ScaleStrategyExponential,
+	ScaleStrategyStep,
+	ScaleStrategyDecommission,
+}
+
+const (
+	errBadScaleStrategyFmt = "invalid scale strategy specified: '%v'"
+	errScaleStepsZero      = "the step scale strategy needs at least 1 step"
+)
+
+// ScaleStrategy computes how many of the generators of a simulator report at
+// each epoch. The generators are reported in order, so the generators past
+// the returned count do not report at that epoch, either because they have
+// not started yet or because they were decommissioned.
+type ScaleStrategy interface {
+	// Scale returns the number of generators reporting at epoch, out of
+	// epochs, between initial and total generators
+	Scale(epoch, epochs, initial, total uint64) uint64
+}
+
+// NewScaleStrategy returns the ScaleStrategy of the given name. steps is
+// only used by the step strategy. An empty name is the linear strategy.
+func NewScaleStrategy(name string, steps uint64) (ScaleStrategy, error) {
+	switch name {
+	case "", ScaleStrategyLinear:
+		return LinearScale{}, nil
+	case ScaleStrategyExponential:
+		return ExponentialScale{}, nil
+	case ScaleStrategyStep:
+		if steps == 0 {
+			return nil, fmt.Errorf(errScaleStepsZero)
+		}
+		return StepScale{Steps: steps}, nil
+	case ScaleStrategyDecommission:
+		return DecommissionScale{}, nil
+	default:
+		return nil, fmt.Errorf(errBadScaleStrategyFmt, name)
+	}
+}
+
+// ScaleStrategyOrDefault returns s, or LinearScale if s is nil.
+func ScaleStrategyOrDefault(s ScaleStrategy) ScaleStrategy {
+	if s == nil {
+		return LinearScale{}
+	}
+	return s
+}
+
+// progress returns the fraction of the epochs passed at epoch, from 0 at the
+// first epoch to 1 at the last one and any epoch past it
+func progress(epoch, epochs uint64) float64 {
+	if epoch == 0 {
+		return 0
+	}
+	if epochs <= 1 || epoch >= epochs-1 {
+		return 1
+	}
+	return float64(epoch) / float64(epochs-1)
+}
+
+// LinearScale adds the generators missing from the initial count in
+// proportion to the epochs that passed.
+type LinearScale struct{}
+
+// Scale returns the number of generators reporting at epoch.
+func (LinearScale) Scale(epoch, epochs, initial, total uint64) uint64 {
+	if epoch == 0 {
+		return initial
+	}
+	if epochs <= 1 || epoch >= epochs-1 {
+		return total
+	}
+	missingScale := float64(total - initial)
+	return initial + uint64(missingScale*float64(epoch)/float64(epochs-1))
+}
+
+// ExponentialScale multiplies the generators by the same factor at each
+// epoch, so few are added at first and most towards the end.
+type ExponentialScale struct{}
+
+// Scale returns the number of generators reporting at epoch.
+func (ExponentialScale) Scale(epoch, epochs, initial, total uint64) uint64 {
+	start := math.Max(float64(initial), 1)
+	n := uint64(math.Round(start * math.Pow(float64(total)/start, progress(epoch, epochs))))
+	if n > total {
+		return total
+	}
+	if n < initial {
+		return initial
+	}
+	return n
+}
+
+// StepScale adds the generators missing from the initial count in Steps
+// equal steps, so the count stays the same for equal periods between them.
+type StepScale struct {
+	Steps uint64
+}
+
+// Scale returns the number of generators reporting at epoch.
+func (s StepScale) Scale(epoch, epochs, initial, total uint64) uint64 {
+	if epochs == 0 {
+		return initial
+	}
+	// the epochs are split in Steps+1 periods, the first one at the initial
+	// count and the last one at the total count
+	step := epoch * (s.Steps + 1) / epochs
+	if step > s.Steps {
+		step = s.Steps
+	}
+	return initial + (total-initial)*step/s.Steps
+}
+
+// DecommissionScale starts with all the generators and removes them in
+// proportion to the epochs that passed, down to the initial count. The
+// removed generators stop reporting.
+type DecommissionScale struct{}
+
+// Scale returns the number of generators reporting at epoch.
+func (DecommissionScale) Scale(epoch, epochs, initial, total uint64) uint64 {
+	removedScale := float64(total - initial)
+	return total - uint64(removedScale*progress(epoch, epochs))
+}
@@ -0,0 +1,132 @@
+package common
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/data"
+)
+
+func TestNewScaleStrategy(t *testing.T) {
+	cases := []struct {
+		name   string
+		steps  uint64
+		want   ScaleStrategy
+		errMsg string
+	}{
+		{name: "", want: LinearScale{}},
+		{name: ScaleStrategyLinear, want: LinearScale{}},
+		{name: ScaleStrategyExponential, want: ExponentialScale{}},
+		{name: ScaleStrategyStep, steps: 3, want: StepScale{Steps: 3}},
+		{name: ScaleStrategyStep, errMsg: errScaleStepsZero},
+		{name: ScaleStrategyDecommission, want: DecommissionScale{}},
+		{name: "bogus", errMsg: "invalid scale strategy specified: 'bogus'"},
+	}
+	for _, c := range cases {
+		got, err := NewScaleStrategy(c.name, c.steps)
+		if c.errMsg != "" {
+			if err == nil || err.Error() != c.errMsg {
+				t.Errorf("%s: incorrect error: got %v want %s", c.name, err, c.errMsg)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", c.name, err)
+		} else if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s: incorrect strategy: got %#v want %#v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestScaleStrategies(t *testing.T) {
+	const epochs, initial, total = 11, 10, 110
+	cases := []struct {
+		desc     string
+		strategy ScaleStrategy
+		want     []uint64
+	}{
+		{
+			desc:     "linear",
+			strategy: LinearScale{},
+			want:     []uint64{10, 20, 30, 40, 50, 60, 70, 80, 90, 100, 110},
+		},
+		{
+			desc:     "exponential",
+			strategy: ExponentialScale{},
+			want:     []uint64{10, 13, 16, 21, 26, 33, 42, 54, 68, 87, 110},
+		},
+		{
+			desc:     "step",
+			strategy: StepScale{Steps: 4},
+			want:     []uint64{10, 10, 10, 35, 35, 60, 60, 85, 85, 110, 110},
+		},
+		{
+			desc:     "decommission",
+			strategy: DecommissionScale{},
+			want:     []uint64{110, 100, 90, 80, 70, 60, 50, 40, 30, 20, 10},
+		},
+	}
+	for _, c := range cases {
+		for epoch, want := range c.want {
+			if got := c.strategy.Scale(uint64(epoch), epochs, initial, total); got != want {
+				t.Errorf("%s: incorrect scale at epoch %d: got %d want %d", c.desc, epoch, got, want)
+			}
+		}
+	}
+}
+
+func TestScaleStrategiesSingleEpoch(t *testing.T) {
+	for _, s := range []ScaleStrategy{LinearScale{}, ExponentialScale{}, StepScale{Steps: 2}, DecommissionScale{}} {
+		first := s.Scale(0, 1, 1, 5)
+		if first != 1 && first != 5 {
+			t.Errorf("%T: incorrect scale at first epoch: %d", s, first)
+		}
+		// the epoch after the last one, when the simulator finishes
+		if got := s.Scale(1, 1, 1, 5); got != 1 && got != 5 {
+			t.Errorf("%T: incorrect scale after last epoch: %d", s, got)
+		}
+	}
+}
+
+func TestScaleStrategiesPastLastEpoch(t *testing.T) {
+	const epochs, initial, total = 11, 10, 110
+	for _, s := range []ScaleStrategy{LinearScale{}, ExponentialScale{}, StepScale{Steps: 2}, DecommissionScale{}} {
+		last := s.Scale(epochs-1, epochs, initial, total)
+		for _, epoch := range []uint64{epochs, epochs + 1, 10 * epochs} {
+			if got := s.Scale(epoch, epochs, initial, total); got != last {
+				t.Errorf("%T: incorrect scale at epoch %d of %d: got %d want %d", s, epoch, epochs, got, last)
+			}
+		}
+	}
+}
+
+func TestBaseSimulatorDecommission(t *testing.T) {
+	conf := &BaseSimulatorConfig{
+		Start:                testTime,
+		End:                  testTime.Add(3 * time.Second),
+		InitGeneratorScale:   10,
+		GeneratorScale:       testGeneratorScale,
+		GeneratorConstructor: dummyGeneratorConstructor,
+		ScaleStrategy:        DecommissionScale{},
+	}
+	s := conf.NewSimulator(time.Second, 0).(*BaseSimulator)
+	if s.epochGenerators != testGeneratorScale {
+		t.Fatalf("decommission does not start with all generators: %d", s.epochGenerators)
+	}
+
+	// all generators write at the first epoch, and only the initial ones at the last
+	perEpoch := testGeneratorScale * dummyGeneratorMeasurementCount
+	written := make([]int, 3)
+	p := data.NewPoint()
+	for i := 0; !s.Finished(); i++ {
+		if s.Next(p) {
+			written[i/perEpoch]++
+		}
+		p.Reset()
+	}
+	want := []int{100 * 9, 55 * 9, 10 * 9}
+	if !reflect.DeepEqual(written, want) {
+		t.Errorf("incorrect written points per epoch: got %v want %v", written, want)
+	}
+}
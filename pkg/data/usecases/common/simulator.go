@@ -23,6 +23,8 @@ type BaseSimulatorConfig struct {
 	GeneratorScale uint64
 	// GeneratorConstructor is the function used to create a new Generator given an id number and start time
 	GeneratorConstructor func(i int, start time.Time) Generator
+	// ScaleStrategy is how the number of reporting Generators changes over the epochs, linear if nil
+	ScaleStrategy ScaleStrategy
 }
 
 func calculateEpochs(duration time.Duration, interval time.Duration) uint64 {
@@ -42,6 +44,7 @@ func (sc *BaseSimulatorConfig) NewSimulator(interval time.Duration, limit uint64
 		// Set specified points number limit
 		maxPoints = limit
 	}
+	scaleStrategy := ScaleStrategyOrDefault(sc.ScaleStrategy)
 	sim := &BaseSimulator{
 		madePoints: 0,
 		maxPoints:  maxPoints,
@@ -51,8 +54,9 @@ func (sc *BaseSimulatorConfig) NewSimulator(interval time.Duration, limit uint64
 
 		epoch:           0,
 		epochs:          epochs,
-		epochGenerators: sc.InitGeneratorScale,
+		epochGenerators: scaleStrategy.Scale(0, epochs, sc.InitGeneratorScale, sc.GeneratorScale),
 		initGenerators:  sc.InitGeneratorScale,
+		scaleStrategy:   scaleStrategy,
 		timestampStart:  sc.Start,
 		timestampEnd:    sc.End,
 		interval:        interval,
@@ -79,6 +83,13 @@ type Simulator interface {
 	Headers() *GeneratedDataHeaders
 }
 
+// SeriesCounter is a Simulator that counts the unique series it generated.
+type SeriesCounter interface {
+	// SeriesCount returns the number of unique tag sets and of unique
+	// series, one per field of each tag set, generated so far
+	SeriesCount() (tagSets, series uint64)
+}
+
 // BaseSimulator generates data similar to truck readings.
 type BaseSimulator struct {
 	madePoints uint64
@@ -91,6 +102,7 @@ type BaseSimulator struct {
 	epochs          uint64
 	epochGenerators uint64
 	initGenerators  uint64
+	scaleStrategy   ScaleStrategy
 
 	timestampStart time.Time
 	timestampEnd   time.Time
@@ -196,19 +208,14 @@ func (s *BaseSimulator) Headers() *GeneratedDataHeaders {
 	}
 }
 
-// TODO(rrk) - Can probably turn this logic into a separate interface and implement other
-// types of scale up, e.g., exponential
-//
-// To "scale up" the number of reporting items, we need to know when
-// which epoch we are currently in. Once we know that, we can take the "missing"
-// amount of scale -- i.e., the max amount of scale less the initial amount
-// -- and add it in proportion to the percentage of epochs that have passed. This
-// way we simulate all items at each epoch, but at the end of the function
-// we check whether the point should be recorded by the calling process.
+// To "scale up" (or down) the number of reporting items, we need to know
+// which epoch we are currently in. Once we know that, the ScaleStrategy tells
+// how many items report at that epoch. This way we simulate all items at
+// each epoch, but at the end of the function we check whether the point
+// should be recorded by the calling process.
 func (s *BaseSimulator) adjustNumHostsForEpoch() {
 	s.epoch++
-	missingScale := float64(uint64(len(s.generators)) - s.initGenerators)
-	s.epochGenerators = s.initGenerators + uint64(missingScale*float64(s.epoch)/float64(s.epochs-1))
+	s.epochGenerators = ScaleStrategyOrDefault(s.scaleStrategy).Scale(s.epoch, s.epochs, s.initGenerators, uint64(len(s.generators)))
 }
 
 // SimulatedMeasurement simulates one measurement (e.g. Redis for DevOps).
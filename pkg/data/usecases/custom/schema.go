@@ -0,0 +1,259 @@
+package custom
+
+import (
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/data/usecases/common"
+	"gopkg.in/yaml.v2"
+)
+
+// Distribution types of the fields of a schema
+const (
+	DistributionND       = "nd"
+	DistributionUD       = "ud"
+	DistributionWD       = "wd"
+	DistributionCWD      = "cwd"
+	DistributionMWD      = "mwd"
+	DistributionConstant = "constant"
+)
+
+// Value types of the fields of a schema
+const (
+	FieldTypeFloat = "float"
+	FieldTypeInt   = "int"
+)
+
+// Schema describes the data of a custom use case: the tags of every
+// simulated entity, and the measurements each entity reports
+type Schema struct {
+	Tags         []TagSpec         `yaml:"tags"`
+	Measurements []MeasurementSpec `yaml:"measurements"`
+}
+
+// TagSpec is a tag of every entity. Its value is chosen at random from
+// Values, or from Cardinality values made with Format, or is unique to each
+// entity when neither is set.
+type TagSpec struct {
+	Name        string   `yaml:"name"`
+	Values      []string `yaml:"values"`
+	Cardinality int      `yaml:"cardinality"`
+	// Format makes a value from a number, like 'host_%d', '<name>_%d' by default
+	Format string `yaml:"format"`
+}
+
+// MeasurementSpec is a measurement every entity reports every Interval,
+// which has to be a multiple of the log interval (by default the log interval)
+type MeasurementSpec struct {
+	Name     string      `yaml:"name"`
+	Interval string      `yaml:"interval"`
+	Fields   []FieldSpec `yaml:"fields"`
+
+	interval time.Duration
+}
+
+// FieldSpec is a field of a measurement, of which the values follow Distribution
+type FieldSpec struct {
+	Name string `yaml:"name"`
+	// Type is float (default) or int
+	Type string `yaml:"type"`
+	// Precision is the number of decimals of float values, if set
+	Precision    *int             `yaml:"precision"`
+	Distribution DistributionSpec `yaml:"distribution"`
+}
+
+// DistributionSpec is a common.Distribution:
+//
+//	nd: normal distribution of Mean and StdDev
+//	ud: uniform distribution between Low and High
+//	wd: random walk from Start with steps of Step
+//	cwd: random walk clamped between Min and Max from Start (random by default) with steps of Step
+//	mwd: monotonically increasing random walk from Start with steps of Step
+//	constant: always Value
+type DistributionSpec struct {
+	Type   string            `yaml:"type"`
+	Mean   float64           `yaml:"mean"`
+	StdDev float64           `yaml:"stddev"`
+	Low    float64           `yaml:"low"`
+	High   float64           `yaml:"high"`
+	Min    float64           `yaml:"min"`
+	Max    float64           `yaml:"max"`
+	Start  *float64          `yaml:"start"`
+	Value  float64           `yaml:"value"`
+	Step   *DistributionSpec `yaml:"step"`
+}
+
+// LoadSchema reads and validates the schema in fileName
+func LoadSchema(fileName string) (*Schema, error) {
+	if fileName == "" {
+		return nil, fmt.Errorf("no schema file given for the custom use case")
+	}
+	b, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read schema %s: %v", fileName, err)
+	}
+	s, err := ParseSchema(b)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema %s: %v", fileName, err)
+	}
+	return s, nil
+}
+
+// ParseSchema parses and validates a schema in YAML
+func ParseSchema(b []byte) (*Schema, error) {
+	s := &Schema{}
+	if err := yaml.UnmarshalStrict(b, s); err != nil {
+		return nil, err
+	}
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Schema) validate() error {
+	if len(s.Measurements) == 0 {
+		return fmt.Errorf("no measurements")
+	}
+	tags := make(map[string]bool)
+	for _, t := range s.Tags {
+		if t.Name == "" {
+			return fmt.Errorf("tag without a name")
+		}
+		if tags[t.Name] {
+			return fmt.Errorf("duplicate tag '%s'", t.Name)
+		}
+		tags[t.Name] = true
+		if t.Cardinality < 0 {
+			return fmt.Errorf("tag '%s': negative cardinality", t.Name)
+		}
+	}
+	measurements := make(map[string]bool)
+	for i := range s.Measurements {
+		m := &s.Measurements[i]
+		if m.Name == "" {
+			return fmt.Errorf("measurement without a name")
+		}
+		if measurements[m.Name] {
+			return fmt.Errorf("duplicate measurement '%s'", m.Name)
+		}
+		measurements[m.Name] = true
+		if m.Interval != "" {
+			d, err := time.ParseDuration(m.Interval)
+			if err != nil || d <= 0 {
+				return fmt.Errorf("measurement '%s': invalid interval '%s'", m.Name, m.Interval)
+			}
+			m.interval = d
+		}
+		if len(m.Fields) == 0 {
+			return fmt.Errorf("measurement '%s': no fields", m.Name)
+		}
+		fields := make(map[string]bool)
+		for _, f := range m.Fields {
+			if f.Name == "" {
+				return fmt.Errorf("measurement '%s': field without a name", m.Name)
+			}
+			if fields[f.Name] {
+				return fmt.Errorf("measurement '%s': duplicate field '%s'", m.Name, f.Name)
+			}
+			fields[f.Name] = true
+			if f.Type != "" && f.Type != FieldTypeFloat && f.Type != FieldTypeInt {
+				return fmt.Errorf("measurement '%s', field '%s': unknown type '%s'", m.Name, f.Name, f.Type)
+			}
+			if err := f.Distribution.validate(); err != nil {
+				return fmt.Errorf("measurement '%s', field '%s': %v", m.Name, f.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (d *DistributionSpec) validate() error {
+	switch d.Type {
+	case DistributionND, DistributionConstant:
+	case DistributionUD:
+		if d.High < d.Low {
+			return fmt.Errorf("uniform distribution with high %v below low %v", d.High, d.Low)
+		}
+	case DistributionCWD:
+		if d.Max < d.Min {
+			return fmt.Errorf("clamped random walk with max %v below min %v", d.Max, d.Min)
+		}
+		fallthrough
+	case DistributionWD, DistributionMWD:
+		if d.Step == nil {
+			return fmt.Errorf("random walk '%s' without a step distribution", d.Type)
+		}
+		return d.Step.validate()
+	default:
+		return fmt.Errorf("unknown distribution '%s'", d.Type)
+	}
+	return nil
+}
+
+// checkIntervals sets the intervals of the measurements without one to
+// logInterval, and checks that the others are multiples of it
+func (s *Schema) checkIntervals(logInterval time.Duration) error {
+	for i := range s.Measurements {
+		m := &s.Measurements[i]
+		if m.interval == 0 {
+			m.interval = logInterval
+		}
+		if m.interval%logInterval != 0 {
+			return fmt.Errorf("measurement '%s': interval %v is not a multiple of the log interval %v", m.Name, m.interval, logInterval)
+		}
+	}
+	return nil
+}
+
+// newDistribution returns a new distribution of the spec
+func (d *DistributionSpec) newDistribution() common.Distribution {
+	start := 0.0
+	if d.Start != nil {
+		start = *d.Start
+	}
+	switch d.Type {
+	case DistributionND:
+		return common.ND(d.Mean, d.StdDev)
+	case DistributionUD:
+		return common.UD(d.Low, d.High)
+	case DistributionWD:
+		return common.WD(d.Step.newDistribution(), start)
+	case DistributionCWD:
+		if d.Start == nil {
+			start = d.Min + rand.Float64()*(d.Max-d.Min)
+		}
+		return common.CWD(d.Step.newDistribution(), d.Min, d.Max, start)
+	case DistributionMWD:
+		return common.MWD(d.Step.newDistribution(), start)
+	default:
+		return &common.ConstantDistribution{State: d.Value}
+	}
+}
+
+// newDistribution returns a new distribution of the values of the field
+func (f *FieldSpec) newDistribution() common.Distribution {
+	d := f.Distribution.newDistribution()
+	if f.Precision != nil && f.Type != FieldTypeInt {
+		return common.FP(d, *f.Precision)
+	}
+	return d
+}
+
+// tagValue returns the value of the tag of entity i
+func (t *TagSpec) tagValue(i int) string {
+	format := t.Format
+	if format == "" {
+		format = t.Name + "_%d"
+	}
+	switch {
+	case len(t.Values) > 0:
+		return common.RandomStringSliceChoice(t.Values)
+	case t.Cardinality > 0:
+		return fmt.Sprintf(format, rand.Intn(t.Cardinality))
+	default:
+		return fmt.Sprintf(format, i)
+	}
+}
@@ -0,0 +1,175 @@
+package custom
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/data/usecases/common"
+)
+
+const testSchema = `
+tags:
+  - name: sensor
+  - name: region
+    values: [eu, us]
+  - name: model
+    cardinality: 3
+    format: m-%d
+measurements:
+  - name: env
+    fields:
+      - name: temperature
+        precision: 1
+        distribution: {type: cwd, min: -10, max: 40, start: 20, step: {type: nd, mean: 0, stddev: 1}}
+      - name: battery
+        type: int
+        distribution: {type: constant, value: 100}
+  - name: counters
+    interval: 30s
+    fields:
+      - name: packets
+        type: int
+        distribution: {type: mwd, step: {type: ud, low: 0, high: 10}}
+`
+
+func TestParseSchema(t *testing.T) {
+	s, err := ParseSchema([]byte(testSchema))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(s.Tags) != 3 || len(s.Measurements) != 2 {
+		t.Fatalf("incorrect schema: %+v", s)
+	}
+	if got := s.Measurements[1].interval; got != 30*time.Second {
+		t.Errorf("incorrect interval: got %v want 30s", got)
+	}
+	if got := s.Measurements[0].Fields[0].Distribution.Step.Type; got != DistributionND {
+		t.Errorf("incorrect step distribution: got %s want %s", got, DistributionND)
+	}
+}
+
+func TestParseSchemaErrors(t *testing.T) {
+	cases := []struct {
+		desc   string
+		schema string
+		errMsg string
+	}{
+		{
+			desc:   "no measurements",
+			schema: "tags: [{name: a}]",
+			errMsg: "no measurements",
+		},
+		{
+			desc:   "unknown key",
+			schema: "measurements: [{name: m, colour: red}]",
+			errMsg: "field colour not found",
+		},
+		{
+			desc:   "duplicate tag",
+			schema: "tags: [{name: a}, {name: a}]\nmeasurements: [{name: m, fields: [{name: f, distribution: {type: nd}}]}]",
+			errMsg: "duplicate tag 'a'",
+		},
+		{
+			desc:   "no fields",
+			schema: "measurements: [{name: m}]",
+			errMsg: "measurement 'm': no fields",
+		},
+		{
+			desc:   "bad interval",
+			schema: "measurements: [{name: m, interval: often, fields: [{name: f, distribution: {type: nd}}]}]",
+			errMsg: "invalid interval 'often'",
+		},
+		{
+			desc:   "bad type",
+			schema: "measurements: [{name: m, fields: [{name: f, type: string, distribution: {type: nd}}]}]",
+			errMsg: "unknown type 'string'",
+		},
+		{
+			desc:   "unknown distribution",
+			schema: "measurements: [{name: m, fields: [{name: f, distribution: {type: zipf}}]}]",
+			errMsg: "unknown distribution 'zipf'",
+		},
+		{
+			desc:   "walk without step",
+			schema: "measurements: [{name: m, fields: [{name: f, distribution: {type: wd}}]}]",
+			errMsg: "random walk 'wd' without a step distribution",
+		},
+		{
+			desc:   "clamped walk bounds",
+			schema: "measurements: [{name: m, fields: [{name: f, distribution: {type: cwd, min: 1, max: 0, step: {type: nd}}}]}]",
+			errMsg: "max 0 below min 1",
+		},
+	}
+	for _, c := range cases {
+		_, err := ParseSchema([]byte(c.schema))
+		if err == nil {
+			t.Errorf("%s: unexpected lack of error", c.desc)
+		} else if !strings.Contains(err.Error(), c.errMsg) {
+			t.Errorf("%s: incorrect error: got %v want %s", c.desc, err, c.errMsg)
+		}
+	}
+}
+
+func TestCheckIntervals(t *testing.T) {
+	s, err := ParseSchema([]byte(testSchema))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := s.checkIntervals(10 * time.Second); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := s.Measurements[0].interval; got != 10*time.Second {
+		t.Errorf("incorrect default interval: got %v want 10s", got)
+	}
+	if err := s.checkIntervals(20 * time.Second); err == nil {
+		t.Errorf("unexpected lack of error for an interval that is not a multiple of the log interval")
+	}
+}
+
+func TestNewDistribution(t *testing.T) {
+	start := 5.0
+	cases := []struct {
+		spec DistributionSpec
+		want common.Distribution
+	}{
+		{DistributionSpec{Type: DistributionND, StdDev: 1}, &common.NormalDistribution{}},
+		{DistributionSpec{Type: DistributionUD, High: 1}, &common.UniformDistribution{}},
+		{DistributionSpec{Type: DistributionWD, Step: &DistributionSpec{Type: DistributionND}}, &common.RandomWalkDistribution{}},
+		{DistributionSpec{Type: DistributionCWD, Max: 10, Start: &start, Step: &DistributionSpec{Type: DistributionND}}, &common.ClampedRandomWalkDistribution{}},
+		{DistributionSpec{Type: DistributionMWD, Step: &DistributionSpec{Type: DistributionUD}}, &common.MonotonicRandomWalkDistribution{}},
+		{DistributionSpec{Type: DistributionConstant, Value: 3}, &common.ConstantDistribution{}},
+	}
+	for _, c := range cases {
+		d := c.spec.newDistribution()
+		if gotType, wantType := reflect.TypeOf(d), reflect.TypeOf(c.want); gotType != wantType {
+			t.Errorf("%s: incorrect distribution: got %v want %v", c.spec.Type, gotType, wantType)
+		}
+	}
+
+	d := (&DistributionSpec{Type: DistributionConstant, Value: 3}).newDistribution()
+	if got := d.Get(); got != 3 {
+		t.Errorf("incorrect constant: got %v want 3", got)
+	}
+	d = (&DistributionSpec{Type: DistributionCWD, Min: 1, Max: 2, Step: &DistributionSpec{Type: DistributionConstant}}).newDistribution()
+	if got := d.Get(); got < 1 || got > 2 {
+		t.Errorf("clamped random walk starting out of bounds: %v", got)
+	}
+}
+
+func TestTagValue(t *testing.T) {
+	s, err := ParseSchema([]byte(testSchema))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := s.Tags[0].tagValue(7); got != "sensor_7" {
+		t.Errorf("incorrect unique tag value: got %s want sensor_7", got)
+	}
+	if got := s.Tags[1].tagValue(7); got != "eu" && got != "us" {
+		t.Errorf("incorrect tag value from values: got %s", got)
+	}
+	if got := s.Tags[2].tagValue(7); got != "m-0" && got != "m-1" && got != "m-2" {
+		t.Errorf("incorrect tag value from cardinality: got %s", got)
+	}
+}
@@ -0,0 +1,251 @@
+package custom
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/data/usecases/common"
+)
+
+// SimulatorConfig is used to create a Simulator of the entities of a schema
+type SimulatorConfig struct {
+	// Start is the beginning time for the Simulator
+	Start time.Time
+	// End is the ending time for the Simulator
+	End time.Time
+	// InitEntityCount is the number of entities to start with in the first reporting period
+	InitEntityCount uint64
+	// EntityCount is the total number of entities to have in the last reporting period
+	EntityCount uint64
+	// Schema describes the tags and the measurements of the entities
+	Schema *Schema
+	// ScaleStrategy is how the number of reporting entities changes over the ticks, linear if nil
+	ScaleStrategy common.ScaleStrategy
+}
+
+// NewSimulatorConfig returns the SimulatorConfig of the schema in fileName,
+// of which the measurements are reported at multiples of logInterval
+func NewSimulatorConfig(fileName string, logInterval time.Duration) (*SimulatorConfig, error) {
+	schema, err := LoadSchema(fileName)
+	if err != nil {
+		return nil, err
+	}
+	if err = schema.checkIntervals(logInterval); err != nil {
+		return nil, err
+	}
+	return &SimulatorConfig{Schema: schema}, nil
+}
+
+// entity is a simulated entity, with its tags and a SubsystemMeasurement for
+// each measurement of the schema
+type entity struct {
+	tags         []common.Tag
+	measurements []*common.SubsystemMeasurement
+}
+
+// measurement is a measurement of the schema, reported every every ticks
+type measurement struct {
+	name   []byte
+	every  uint64
+	fields []common.LabeledDistributionMaker
+	ints   []bool
+}
+
+// Simulator generates the data of the entities of a schema. At every tick
+// of the log interval, the measurements that are due are reported by all
+// entities, one measurement after the other.
+type Simulator struct {
+	madePoints uint64
+	maxPoints  uint64
+
+	entities     []*entity
+	measurements []*measurement
+
+	tick          uint64
+	ticks         uint64
+	tickEntities  uint64
+	initEntities  uint64
+	scaleStrategy common.ScaleStrategy
+	interval      time.Duration
+	timestampTick time.Time
+
+	// due are the indexes of the measurements due at the current tick
+	due         []int
+	dueIndex    int
+	entityIndex int
+}
+
+// NewSimulator produces a Simulator that conforms to the given config over the specified interval
+func (c *SimulatorConfig) NewSimulator(interval time.Duration, limit uint64) common.Simulator {
+	if err := c.Schema.checkIntervals(interval); err != nil {
+		panic(err)
+	}
+	measurements := make([]*measurement, len(c.Schema.Measurements))
+	for i := range c.Schema.Measurements {
+		ms := &c.Schema.Measurements[i]
+		m := &measurement{
+			name:   []byte(ms.Name),
+			every:  uint64(ms.interval / interval),
+			fields: make([]common.LabeledDistributionMaker, len(ms.Fields)),
+			ints:   make([]bool, len(ms.Fields)),
+		}
+		for j := range ms.Fields {
+			f := &ms.Fields[j]
+			m.fields[j] = common.LabeledDistributionMaker{Label: []byte(f.Name), DistributionMaker: f.newDistribution}
+			m.ints[j] = f.Type == FieldTypeInt
+		}
+		measurements[i] = m
+	}
+
+	entities := make([]*entity, c.EntityCount)
+	for i := range entities {
+		e := &entity{
+			tags:         make([]common.Tag, len(c.Schema.Tags)),
+			measurements: make([]*common.SubsystemMeasurement, len(measurements)),
+		}
+		for j := range c.Schema.Tags {
+			t := &c.Schema.Tags[j]
+			e.tags[j] = common.Tag{Key: []byte(t.Name), Value: t.tagValue(i)}
+		}
+		for j, m := range measurements {
+			e.measurements[j] = common.NewSubsystemMeasurementWithDistributionMakers(c.Start, m.fields)
+		}
+		entities[i] = e
+	}
+
+	ticks := uint64(c.End.Sub(c.Start) / interval)
+	maxPoints := uint64(0)
+	for _, m := range measurements {
+		// the measurement is reported at the ticks that are multiples of every
+		maxPoints += (ticks + m.every - 1) / m.every * c.EntityCount
+	}
+	if limit > 0 && limit < maxPoints {
+		// Set specified points number limit
+		maxPoints = limit
+	}
+	scaleStrategy := common.ScaleStrategyOrDefault(c.ScaleStrategy)
+	s := &Simulator{
+		maxPoints:     maxPoints,
+		entities:      entities,
+		measurements:  measurements,
+		ticks:         ticks,
+		tickEntities:  scaleStrategy.Scale(0, ticks, c.InitEntityCount, c.EntityCount),
+		initEntities:  c.InitEntityCount,
+		scaleStrategy: scaleStrategy,
+		interval:      interval,
+		timestampTick: c.Start,
+	}
+	// all measurements are due at the first tick
+	for i := range measurements {
+		s.due = append(s.due, i)
+	}
+	return s
+}
+
+// Finished tells whether we have simulated all the necessary points.
+func (s *Simulator) Finished() bool {
+	return s.madePoints >= s.maxPoints
+}
+
+// Next advances a Point to the next state in the generator.
+func (s *Simulator) Next(p *data.Point) bool {
+	if s.entityIndex == len(s.entities) {
+		s.entityIndex = 0
+		s.dueIndex++
+	}
+	for s.dueIndex == len(s.due) {
+		s.nextTick()
+	}
+
+	e := s.entities[s.entityIndex]
+	for _, tag := range e.tags {
+		p.AppendTag(tag.Key, tag.Value)
+	}
+	mi := s.due[s.dueIndex]
+	m := s.measurements[mi]
+	sm := e.measurements[mi]
+	p.SetMeasurementName(m.name)
+	p.SetTimestamp(&sm.Timestamp)
+	for i, d := range sm.Distributions {
+		if m.ints[i] {
+			p.AppendField(m.fields[i].Label, int64(d.Get()))
+		} else {
+			p.AppendField(m.fields[i].Label, d.Get())
+		}
+	}
+
+	ret := uint64(s.entityIndex) < s.tickEntities
+	s.madePoints++
+	s.entityIndex++
+	return ret
+}
+
+// nextTick advances to the next tick, and the measurements due at it
+func (s *Simulator) nextTick() {
+	s.tick++
+	s.timestampTick = s.timestampTick.Add(s.interval)
+	s.due = s.due[:0]
+	s.dueIndex = 0
+	for i, m := range s.measurements {
+		if s.tick%m.every != 0 {
+			continue
+		}
+		s.due = append(s.due, i)
+		for _, e := range s.entities {
+			e.measurements[i].Tick(time.Duration(m.every) * s.interval)
+		}
+	}
+	s.adjustNumEntitiesForTick()
+}
+
+// adjustNumEntitiesForTick computes the number of entities reporting at the
+// tick with the ScaleStrategy
+func (s *Simulator) adjustNumEntitiesForTick() {
+	s.tickEntities = s.scaleStrategy.Scale(s.tick, s.ticks, s.initEntities, uint64(len(s.entities)))
+}
+
+// Fields returns the fields of each measurement.
+func (s *Simulator) Fields() map[string][]string {
+	fields := make(map[string][]string, len(s.measurements))
+	for _, m := range s.measurements {
+		keys := make([]string, len(m.fields))
+		for i, f := range m.fields {
+			keys[i] = string(f.Label)
+		}
+		fields[string(m.name)] = keys
+	}
+	return fields
+}
+
+// TagKeys returns the tag keys of the entities.
+func (s *Simulator) TagKeys() []string {
+	if len(s.entities) == 0 {
+		panic("cannot get tag keys because no entities added")
+	}
+	keys := make([]string, len(s.entities[0].tags))
+	for i, tag := range s.entities[0].tags {
+		keys[i] = string(tag.Key)
+	}
+	return keys
+}
+
+// TagTypes returns the type for each tag, extracted from the generated values.
+func (s *Simulator) TagTypes() []string {
+	if len(s.entities) == 0 {
+		panic("cannot get tag types because no entities added")
+	}
+	types := make([]string, len(s.entities[0].tags))
+	for i, tag := range s.entities[0].tags {
+		types[i] = reflect.TypeOf(tag.Value).String()
+	}
+	return types
+}
+
+func (s *Simulator) Headers() *common.GeneratedDataHeaders {
+	return &common.GeneratedDataHeaders{
+		TagTypes:  s.TagTypes(),
+		TagKeys:   s.TagKeys(),
+		FieldKeys: s.Fields(),
+	}
+}
@@ -0,0 +1,121 @@
+package custom
+
+import (
+	"testing"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/data"
+)
+
+func newTestSimulator(t *testing.T, initEntities, entities uint64, limit uint64) *Simulator {
+	s, err := ParseSchema([]byte(testSchema))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := &SimulatorConfig{
+		Start:           start,
+		End:             start.Add(time.Minute),
+		InitEntityCount: initEntities,
+		EntityCount:     entities,
+		Schema:          s,
+	}
+	return c.NewSimulator(10*time.Second, limit).(*Simulator)
+}
+
+func TestSimulatorNext(t *testing.T) {
+	sim := newTestSimulator(t, 2, 2, 0)
+	// 6 ticks of env for 2 entities, and 2 of counters, at 0s and 30s
+	if sim.maxPoints != 16 {
+		t.Fatalf("incorrect max points: got %d want 16", sim.maxPoints)
+	}
+	want := []struct {
+		measurement string
+		offset      time.Duration
+	}{
+		{"env", 0}, {"env", 0}, {"counters", 0}, {"counters", 0},
+		{"env", 10 * time.Second}, {"env", 10 * time.Second},
+		{"env", 20 * time.Second}, {"env", 20 * time.Second},
+		{"env", 30 * time.Second}, {"env", 30 * time.Second},
+		{"counters", 30 * time.Second}, {"counters", 30 * time.Second},
+		{"env", 40 * time.Second}, {"env", 40 * time.Second},
+		{"env", 50 * time.Second}, {"env", 50 * time.Second},
+	}
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, w := range want {
+		if sim.Finished() {
+			t.Fatalf("simulator finished early at point %d", i)
+		}
+		p := data.NewPoint()
+		if !sim.Next(p) {
+			t.Errorf("point %d not written", i)
+		}
+		if got := string(p.MeasurementName()); got != w.measurement {
+			t.Errorf("point %d: incorrect measurement: got %s want %s", i, got, w.measurement)
+		}
+		if got := p.Timestamp(); !got.Equal(start.Add(w.offset)) {
+			t.Errorf("point %d: incorrect timestamp: got %v want %v", i, got, start.Add(w.offset))
+		}
+		if got, want := p.GetTagValue([]byte("sensor")).(string), []string{"sensor_0", "sensor_1"}[i%2]; got != want {
+			t.Errorf("point %d: incorrect sensor: got %s want %s", i, got, want)
+		}
+		if w.measurement == "env" {
+			if _, ok := p.GetFieldValue([]byte("battery")).(int64); !ok {
+				t.Errorf("point %d: int field is not an int64: %T", i, p.GetFieldValue([]byte("battery")))
+			}
+		}
+	}
+	if !sim.Finished() {
+		t.Errorf("simulator not finished after all points")
+	}
+}
+
+func TestSimulatorInitialEntities(t *testing.T) {
+	sim := newTestSimulator(t, 1, 6, 0)
+	written := 0
+	p := data.NewPoint()
+	for !sim.Finished() {
+		if sim.Next(p) {
+			written++
+		}
+		p.Reset()
+	}
+	// the entities written at the ticks 0 to 5 grow from 1 to 6, with
+	// counters written at the ticks 0 and 3
+	want := 1 + 2 + 3 + 4 + 5 + 6 + 1 + 4
+	if written != want {
+		t.Errorf("incorrect number of written points: got %d want %d", written, want)
+	}
+}
+
+func TestSimulatorLimit(t *testing.T) {
+	sim := newTestSimulator(t, 2, 2, 5)
+	p := data.NewPoint()
+	points := 0
+	for !sim.Finished() {
+		sim.Next(p)
+		p.Reset()
+		points++
+	}
+	if points != 5 {
+		t.Errorf("incorrect number of points: got %d want 5", points)
+	}
+}
+
+func TestSimulatorHeaders(t *testing.T) {
+	h := newTestSimulator(t, 1, 1, 0).Headers()
+	if len(h.TagKeys) != 3 || h.TagKeys[0] != "sensor" || h.TagKeys[2] != "model" {
+		t.Errorf("incorrect tag keys: %v", h.TagKeys)
+	}
+	for _, typ := range h.TagTypes {
+		if typ != "string" {
+			t.Errorf("incorrect tag type: %s", typ)
+		}
+	}
+	if got := h.FieldKeys["env"]; len(got) != 2 || got[0] != "temperature" || got[1] != "battery" {
+		t.Errorf("incorrect env fields: %v", got)
+	}
+	if got := h.FieldKeys["counters"]; len(got) != 1 || got[0] != "packets" {
+		t.Errorf("incorrect counters fields: %v", got)
+	}
+}
@@ -28,6 +28,8 @@ type commonDevopsSimulatorConfig struct {
 	HostConstructor func(ctx *HostContext) Host
 	// MaxMetricCount is the max number of metrics per host to create when using generic-devops use-case
 	MaxMetricCount uint64
+	// ScaleStrategy is how the number of reporting hosts changes over the epochs, linear if nil
+	ScaleStrategy common.ScaleStrategy
 }
 
 func NewHostCtx(id int, start time.Time) *HostContext {
@@ -49,10 +51,11 @@ type commonDevopsSimulator struct {
 	hostIndex uint64
 	hosts     []Host
 
-	epoch      uint64
-	epochs     uint64
-	epochHosts uint64
-	initHosts  uint64
+	epoch         uint64
+	epochs        uint64
+	epochHosts    uint64
+	initHosts     uint64
+	scaleStrategy common.ScaleStrategy
 
 	timestampStart time.Time
 	timestampEnd   time.Time
@@ -134,17 +137,12 @@ func (s *commonDevopsSimulator) populatePoint(p *data.Point, measureIdx int) boo
 	return ret
 }
 
-// TODO(rrk) - Can probably turn this logic into a separate interface and implement other
-// types of scale up, e.g., exponential
-//
-// To "scale up" the number of reporting items, we need to know when
-// which epoch we are currently in. Once we know that, we can take the "missing"
-// amount of scale -- i.e., the max amount of scale less the initial amount
-// -- and add it in proportion to the percentage of epochs that have passed. This
-// way we simulate all items at each epoch, but at the end of the function
-// we check whether the point should be recorded by the calling process.
+// To "scale up" (or down) the number of reporting items, we need to know
+// which epoch we are currently in. Once we know that, the ScaleStrategy tells
+// how many items report at that epoch. This way we simulate all items at
+// each epoch, but at the end of the function we check whether the point
+// should be recorded by the calling process.
 func (s *commonDevopsSimulator) adjustNumHostsForEpoch() {
 	s.epoch++
-	missingScale := float64(uint64(len(s.hosts)) - s.initHosts)
-	s.epochHosts = s.initHosts + uint64(missingScale*float64(s.epoch)/float64(s.epochs-1))
+	s.epochHosts = common.ScaleStrategyOrDefault(s.scaleStrategy).Scale(s.epoch, s.epochs, s.initHosts, uint64(len(s.hosts)))
 }
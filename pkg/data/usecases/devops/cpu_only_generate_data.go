@@ -57,6 +57,7 @@ func (c *CPUOnlySimulatorConfig) NewSimulator(interval time.Duration, limit uint
 		// Set specified points number limit
 		maxPoints = limit
 	}
+	scaleStrategy := common.ScaleStrategyOrDefault(c.ScaleStrategy)
 	sim := &CPUOnlySimulator{&commonDevopsSimulator{
 		madePoints: 0,
 		maxPoints:  maxPoints,
@@ -66,8 +67,9 @@ func (c *CPUOnlySimulatorConfig) NewSimulator(interval time.Duration, limit uint
 
 		epoch:          0,
 		epochs:         epochs,
-		epochHosts:     c.InitHostCount,
+		epochHosts:     scaleStrategy.Scale(0, epochs, c.InitHostCount, c.HostCount),
 		initHosts:      c.InitHostCount,
+		scaleStrategy:  scaleStrategy,
 		timestampStart: c.Start,
 		timestampEnd:   c.End,
 		interval:       interval,
@@ -74,6 +74,7 @@ func (d *DevopsSimulatorConfig) NewSimulator(interval time.Duration, limit uint6
 		// Set specified points number limit
 		maxPoints = limit
 	}
+	scaleStrategy := common.ScaleStrategyOrDefault(d.ScaleStrategy)
 	dg := &DevopsSimulator{
 		commonDevopsSimulator: &commonDevopsSimulator{
 			madePoints: 0,
@@ -84,8 +85,9 @@ func (d *DevopsSimulatorConfig) NewSimulator(interval time.Duration, limit uint6
 
 			epoch:          0,
 			epochs:         epochs,
-			epochHosts:     d.InitHostCount,
+			epochHosts:     scaleStrategy.Scale(0, epochs, d.InitHostCount, d.HostCount),
 			initHosts:      d.InitHostCount,
+			scaleStrategy:  scaleStrategy,
 			timestampStart: d.Start,
 			timestampEnd:   d.End,
 			interval:       interval,
@@ -36,6 +36,7 @@ func (c *GenericMetricsSimulatorConfig) NewSimulator(interval time.Duration, lim
 	if limit > 0 && limit < maxPoints {
 		maxPoints = limit
 	}
+	scaleStrategy := common.ScaleStrategyOrDefault(c.ScaleStrategy)
 	dg := &GenericMetricsSimulator{
 		commonDevopsSimulator: &commonDevopsSimulator{
 			madePoints: 0,
@@ -46,8 +47,9 @@ func (c *GenericMetricsSimulatorConfig) NewSimulator(interval time.Duration, lim
 
 			epoch:          0,
 			epochs:         epochs,
-			epochHosts:     c.InitHostCount,
+			epochHosts:     scaleStrategy.Scale(0, epochs, c.InitHostCount, c.HostCount),
 			initHosts:      c.InitHostCount,
+			scaleStrategy:  scaleStrategy,
 			timestampStart: c.Start,
 			timestampEnd:   c.End,
 			interval:       interval,
@@ -0,0 +1,173 @@
+package finance
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+const (
+	// pathSteps is the number of steps the price takes in an interval
+	pathSteps = 60
+	// stepVolatility is the standard deviation of the relative price change of a step
+	stepVolatility = 0.0005
+	// tickSize is the smallest price increment, of which there are ticksPerUnit in a unit
+	tickSize     = 0.01
+	ticksPerUnit = 100
+	// lotSize is the number of shares in a round lot
+	lotSize = 100
+
+	minInitialPrice = 5.0
+	maxInitialPrice = 500.0
+	minSpread       = 0.0002
+	maxSpread       = 0.002
+	maxTradeLots    = 10
+	maxQuoteLots    = 50
+
+	// tradesPerInterval is the number of trades of a symbol in an interval
+	tradesPerInterval = 3
+	// quotesPerInterval is the number of quote updates of a symbol in an interval
+	quotesPerInterval = 3
+)
+
+// trade is a trade of a symbol at a point in time
+type trade struct {
+	timestamp time.Time
+	price     float64
+	size      int64
+}
+
+// quote is the best bid and ask of a symbol at a point in time
+type quote struct {
+	timestamp time.Time
+	bidPrice  float64
+	bidSize   int64
+	askPrice  float64
+	askSize   int64
+}
+
+// bar is the open, high, low, close and volume of a symbol over an interval
+type bar struct {
+	timestamp time.Time
+	open      float64
+	high      float64
+	low       float64
+	close     float64
+	volume    int64
+}
+
+// market simulates the price of a symbol as a random walk. For every
+// interval it walks the price path of the interval, from which it takes the
+// trades and quotes at irregular times within the interval, and the bar of
+// the interval.
+type market struct {
+	price    float64
+	start    time.Time
+	interval time.Duration
+
+	path   [pathSteps + 1]float64
+	trades [tradesPerInterval]trade
+	quotes [quotesPerInterval]quote
+	bar    bar
+}
+
+func newMarket(start time.Time, interval time.Duration) *market {
+	m := &market{
+		price:    minInitialPrice + rand.Float64()*(maxInitialPrice-minInitialPrice),
+		start:    start,
+		interval: interval,
+	}
+	m.simulate()
+	return m
+}
+
+// advance moves the market to the next interval
+func (m *market) advance(d time.Duration) {
+	m.start = m.start.Add(d)
+	m.simulate()
+}
+
+// simulate walks the price path of the current interval, and takes its
+// trades, quotes and bar
+func (m *market) simulate() {
+	m.path[0] = m.price
+	for i := 1; i <= pathSteps; i++ {
+		m.price *= math.Exp(rand.NormFloat64() * stepVolatility)
+		if m.price < tickSize {
+			m.price = tickSize
+		}
+		m.path[i] = m.price
+	}
+
+	m.bar = bar{
+		timestamp: m.start,
+		open:      roundToTick(m.path[0]),
+		high:      roundToTick(m.path[0]),
+		low:       roundToTick(m.path[0]),
+		close:     roundToTick(m.path[pathSteps]),
+	}
+	for _, p := range m.path[1:] {
+		m.bar.high = math.Max(m.bar.high, roundToTick(p))
+		m.bar.low = math.Min(m.bar.low, roundToTick(p))
+	}
+
+	for i, step := range m.eventSteps(tradesPerInterval) {
+		mid, halfSpread := m.path[step], m.halfSpread(step)
+		// the trade is at the bid or at the ask
+		price := mid - halfSpread
+		if rand.Intn(2) == 1 {
+			price = mid + halfSpread
+		}
+		m.trades[i] = trade{
+			timestamp: m.eventTime(step),
+			price:     roundToTick(price),
+			size:      lotSize * (1 + rand.Int63n(maxTradeLots)),
+		}
+		m.bar.high = math.Max(m.bar.high, m.trades[i].price)
+		m.bar.low = math.Min(m.bar.low, m.trades[i].price)
+		m.bar.volume += m.trades[i].size
+	}
+
+	for i, step := range m.eventSteps(quotesPerInterval) {
+		mid, halfSpread := m.path[step], m.halfSpread(step)
+		bid := math.Floor((mid-halfSpread)*ticksPerUnit) / ticksPerUnit
+		ask := math.Ceil((mid+halfSpread)*ticksPerUnit) / ticksPerUnit
+		if ask <= bid {
+			ask = roundToTick(bid + tickSize)
+		}
+		m.quotes[i] = quote{
+			timestamp: m.eventTime(step),
+			bidPrice:  bid,
+			bidSize:   lotSize * (1 + rand.Int63n(maxQuoteLots)),
+			askPrice:  ask,
+			askSize:   lotSize * (1 + rand.Int63n(maxQuoteLots)),
+		}
+	}
+}
+
+// eventSteps returns n distinct random steps of the path in increasing order
+func (m *market) eventSteps(n int) []int {
+	steps := rand.Perm(pathSteps)[:n]
+	sort.Ints(steps)
+	return steps
+}
+
+// eventTime returns a random time within step of the current interval
+func (m *market) eventTime(step int) time.Time {
+	stepDuration := m.interval / pathSteps
+	offset := time.Duration(step) * stepDuration
+	if stepDuration > 0 {
+		offset += time.Duration(rand.Int63n(int64(stepDuration)))
+	}
+	return m.start.Add(offset)
+}
+
+// halfSpread returns half a random spread around the price at step
+func (m *market) halfSpread(step int) float64 {
+	return m.path[step] * (minSpread + rand.Float64()*(maxSpread-minSpread)) / 2
+}
+
+func roundToTick(price float64) float64 {
+	return math.Round(price*ticksPerUnit) / ticksPerUnit
+}
@@ -0,0 +1,79 @@
+package finance
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestMarketSimulate(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	interval := time.Minute
+	m := newMarket(start, interval)
+
+	for i := 0; i < 100; i++ {
+		end := m.start.Add(interval)
+		b := m.bar
+		if !b.timestamp.Equal(m.start) {
+			t.Fatalf("interval %d: incorrect bar timestamp: got %v want %v", i, b.timestamp, m.start)
+		}
+		if b.low > b.open || b.low > b.close || b.high < b.open || b.high < b.close {
+			t.Fatalf("interval %d: inconsistent bar %+v", i, b)
+		}
+
+		volume := int64(0)
+		for j, tr := range m.trades {
+			if tr.timestamp.Before(m.start) || !tr.timestamp.Before(end) {
+				t.Fatalf("interval %d: trade %d at %v outside of the interval", i, j, tr.timestamp)
+			}
+			if j > 0 && !tr.timestamp.After(m.trades[j-1].timestamp) {
+				t.Fatalf("interval %d: trades out of order", i)
+			}
+			if tr.price < b.low || tr.price > b.high {
+				t.Fatalf("interval %d: trade price %v outside of the bar %+v", i, tr.price, b)
+			}
+			if tr.size <= 0 || tr.size%lotSize != 0 {
+				t.Fatalf("interval %d: incorrect trade size %d", i, tr.size)
+			}
+			volume += tr.size
+		}
+		if b.volume != volume {
+			t.Fatalf("interval %d: incorrect bar volume: got %d want %d", i, b.volume, volume)
+		}
+
+		for j, q := range m.quotes {
+			if q.timestamp.Before(m.start) || !q.timestamp.Before(end) {
+				t.Fatalf("interval %d: quote %d at %v outside of the interval", i, j, q.timestamp)
+			}
+			if q.askPrice <= q.bidPrice {
+				t.Fatalf("interval %d: crossed quote %+v", i, q)
+			}
+			if q.bidPrice != roundToTick(q.bidPrice) || q.askPrice != roundToTick(q.askPrice) {
+				t.Fatalf("interval %d: quote not on ticks %+v", i, q)
+			}
+		}
+
+		prevClose := m.price
+		m.advance(interval)
+		if got := m.path[0]; got != prevClose {
+			t.Fatalf("interval %d: path does not continue from the close: got %v want %v", i, got, prevClose)
+		}
+	}
+}
+
+func TestRoundToTick(t *testing.T) {
+	cases := []struct {
+		in   float64
+		want float64
+	}{
+		{12.344, 12.34},
+		{12.345001, 12.35},
+		{0.004, 0},
+		{99.999, 100},
+	}
+	for _, c := range cases {
+		if got := roundToTick(c.in); math.Abs(got-c.want) > 1e-12 {
+			t.Errorf("incorrect rounding of %v: got %v want %v", c.in, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package finance
+
+import (
+	"time"
+
+	"github.com/timescale/tsbs/pkg/data"
+)
+
+var (
+	labelTrades   = []byte("trades")
+	labelPrice    = []byte("price")
+	labelSize     = []byte("size")
+	labelQuotes   = []byte("quotes")
+	labelBidPrice = []byte("bid_price")
+	labelBidSize  = []byte("bid_size")
+	labelAskPrice = []byte("ask_price")
+	labelAskSize  = []byte("ask_size")
+	labelBars     = []byte("bars")
+	labelOpen     = []byte("open")
+	labelHigh     = []byte("high")
+	labelLow      = []byte("low")
+	labelClose    = []byte("close")
+	labelVolume   = []byte("volume")
+)
+
+// TradesMeasurement is one of the trades of a symbol in an interval.
+type TradesMeasurement struct {
+	market *market
+	index  int
+}
+
+// Tick does nothing: the trades move with the market of the symbol.
+func (m *TradesMeasurement) Tick(time.Duration) {}
+
+// ToPoint serializes the trade to data.Point.
+func (m *TradesMeasurement) ToPoint(p *data.Point) {
+	t := m.market.trades[m.index]
+	p.SetMeasurementName(labelTrades)
+	p.SetTimestamp(&t.timestamp)
+	p.AppendField(labelPrice, t.price)
+	p.AppendField(labelSize, t.size)
+}
+
+// QuotesMeasurement is one of the quote updates of a symbol in an interval.
+type QuotesMeasurement struct {
+	market *market
+	index  int
+}
+
+// Tick does nothing: the quotes move with the market of the symbol.
+func (m *QuotesMeasurement) Tick(time.Duration) {}
+
+// ToPoint serializes the quote to data.Point.
+func (m *QuotesMeasurement) ToPoint(p *data.Point) {
+	q := m.market.quotes[m.index]
+	p.SetMeasurementName(labelQuotes)
+	p.SetTimestamp(&q.timestamp)
+	p.AppendField(labelBidPrice, q.bidPrice)
+	p.AppendField(labelBidSize, q.bidSize)
+	p.AppendField(labelAskPrice, q.askPrice)
+	p.AppendField(labelAskSize, q.askSize)
+}
+
+// BarsMeasurement is the OHLCV bar of a symbol over an interval.
+type BarsMeasurement struct {
+	market *market
+}
+
+// Tick does nothing: the bars move with the market of the symbol.
+func (m *BarsMeasurement) Tick(time.Duration) {}
+
+// ToPoint serializes the bar to data.Point.
+func (m *BarsMeasurement) ToPoint(p *data.Point) {
+	b := m.market.bar
+	p.SetMeasurementName(labelBars)
+	p.SetTimestamp(&b.timestamp)
+	p.AppendField(labelOpen, b.open)
+	p.AppendField(labelHigh, b.high)
+	p.AppendField(labelLow, b.low)
+	p.AppendField(labelClose, b.close)
+	p.AppendField(labelVolume, b.volume)
+}
@@ -0,0 +1,40 @@
+package finance
+
+import (
+	"time"
+
+	"github.com/timescale/tsbs/pkg/data/usecases/common"
+)
+
+// SimulatorConfig is used to create a finance Simulator.
+// It fulfills the common.SimulatorConfig interface.
+type SimulatorConfig struct {
+	// Start is the beginning time for the Simulator
+	Start time.Time
+	// End is the ending time for the Simulator
+	End time.Time
+	// InitSymbolCount is the number of symbols to start with in the first reporting period
+	InitSymbolCount uint64
+	// SymbolCount is the total number of symbols to have in the last reporting period
+	SymbolCount uint64
+	// ScaleStrategy is how the number of reporting symbols changes over the epochs, linear if nil
+	ScaleStrategy common.ScaleStrategy
+}
+
+// NewSimulator produces a finance Simulator with the given config over the
+// specified interval and points limit. Every interval, each symbol reports
+// its trades and quote updates at irregular times within the interval, and
+// the bar of the interval.
+func (sc *SimulatorConfig) NewSimulator(interval time.Duration, limit uint64) common.Simulator {
+	base := &common.BaseSimulatorConfig{
+		Start:              sc.Start,
+		End:                sc.End,
+		InitGeneratorScale: sc.InitSymbolCount,
+		GeneratorScale:     sc.SymbolCount,
+		GeneratorConstructor: func(i int, start time.Time) common.Generator {
+			return NewSymbol(i, start, interval)
+		},
+		ScaleStrategy: sc.ScaleStrategy,
+	}
+	return base.NewSimulator(interval, limit)
+}
@@ -0,0 +1,49 @@
+package finance
+
+import (
+	"testing"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/data"
+)
+
+func TestSimulator(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	sc := &SimulatorConfig{
+		Start:           start,
+		End:             start.Add(time.Hour),
+		InitSymbolCount: 5,
+		SymbolCount:     5,
+	}
+	s := sc.NewSimulator(time.Minute, 0)
+
+	fields := s.Fields()
+	if len(fields) != 3 || len(fields["trades"]) != 2 || len(fields["quotes"]) != 4 || len(fields["bars"]) != 5 {
+		t.Errorf("incorrect fields: %v", fields)
+	}
+	if got := s.TagKeys(); len(got) != 4 || got[0] != "symbol" {
+		t.Errorf("incorrect tag keys: %v", got)
+	}
+
+	points := 0
+	counts := make(map[string]int)
+	for !s.Finished() {
+		p := data.NewPoint()
+		if !s.Next(p) {
+			t.Fatalf("point %d not written", points)
+		}
+		ts := *p.Timestamp()
+		if ts.Before(start) || !ts.Before(start.Add(time.Hour)) {
+			t.Fatalf("point %d at %v outside of the simulated time", points, ts)
+		}
+		counts[string(p.MeasurementName())]++
+		points++
+	}
+	// 60 intervals of 5 symbols
+	want := map[string]int{"trades": 60 * 5 * tradesPerInterval, "quotes": 60 * 5 * quotesPerInterval, "bars": 60 * 5}
+	for name, w := range want {
+		if counts[name] != w {
+			t.Errorf("incorrect number of %s: got %d want %d", name, counts[name], w)
+		}
+	}
+}
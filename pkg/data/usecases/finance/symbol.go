@@ -0,0 +1,96 @@
+package finance
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/data/usecases/common"
+)
+
+// SymbolNameFmt is the format of the names of the symbols, numbered from 0
+const SymbolNameFmt = "sym_%d"
+
+type exchange struct {
+	Name     string
+	Currency string
+}
+
+var (
+	exchangeChoices = []exchange{
+		{Name: "NYSE", Currency: "USD"},
+		{Name: "NASDAQ", Currency: "USD"},
+		{Name: "LSE", Currency: "GBP"},
+		{Name: "XETRA", Currency: "EUR"},
+		{Name: "TSE", Currency: "JPY"},
+	}
+
+	// ExchangeChoices contains all the exchange name values for the finance use case
+	ExchangeChoices = func() []string {
+		names := make([]string, len(exchangeChoices))
+		for i, e := range exchangeChoices {
+			names[i] = e.Name
+		}
+		return names
+	}()
+
+	sectorChoices = []string{
+		"Energy",
+		"Materials",
+		"Industrials",
+		"Consumer",
+		"Healthcare",
+		"Financials",
+		"Technology",
+		"Utilities",
+	}
+)
+
+// Symbol models a traded instrument, which reports its trades, quote
+// updates and bars.
+type Symbol struct {
+	market                *market
+	simulatedMeasurements []common.SimulatedMeasurement
+	tags                  []common.Tag
+}
+
+// TickAll advances the market of the Symbol to the next interval.
+func (s *Symbol) TickAll(d time.Duration) {
+	s.market.advance(d)
+}
+
+// Measurements returns the measurements of the symbol.
+func (s Symbol) Measurements() []common.SimulatedMeasurement {
+	return s.simulatedMeasurements
+}
+
+// Tags returns the symbol tags.
+func (s Symbol) Tags() []common.Tag {
+	return s.tags
+}
+
+// NewSymbol creates a new symbol of which the market moves every interval
+func NewSymbol(i int, start time.Time, interval time.Duration) common.Generator {
+	e := exchangeChoices[rand.Intn(len(exchangeChoices))]
+	m := newMarket(start, interval)
+
+	var sm []common.SimulatedMeasurement
+	for j := 0; j < tradesPerInterval; j++ {
+		sm = append(sm, &TradesMeasurement{market: m, index: j})
+	}
+	for j := 0; j < quotesPerInterval; j++ {
+		sm = append(sm, &QuotesMeasurement{market: m, index: j})
+	}
+	sm = append(sm, &BarsMeasurement{market: m})
+
+	return &Symbol{
+		market: m,
+		tags: []common.Tag{
+			{Key: []byte("symbol"), Value: fmt.Sprintf(SymbolNameFmt, i)},
+			{Key: []byte("exchange"), Value: e.Name},
+			{Key: []byte("currency"), Value: e.Currency},
+			{Key: []byte("sector"), Value: common.RandomStringSliceChoice(sectorChoices)},
+		},
+		simulatedMeasurements: sm,
+	}
+}
@@ -0,0 +1,76 @@
+package finance
+
+import (
+	"testing"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/data"
+)
+
+func TestNewSymbol(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := NewSymbol(3, start, time.Minute).(*Symbol)
+
+	if got := len(s.Measurements()); got != tradesPerInterval+quotesPerInterval+1 {
+		t.Errorf("incorrect number of measurements: got %d", got)
+	}
+	tags := s.Tags()
+	if got := string(tags[0].Key); got != "symbol" {
+		t.Errorf("incorrect first tag: got %s want symbol", got)
+	}
+	if got := tags[0].Value; got != "sym_3" {
+		t.Errorf("incorrect symbol: got %v want sym_3", got)
+	}
+	found := false
+	for _, e := range exchangeChoices {
+		if e.Name == tags[1].Value && e.Currency == tags[2].Value {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("incorrect exchange and currency: %v %v", tags[1].Value, tags[2].Value)
+	}
+
+	s.TickAll(time.Minute)
+	if got := s.market.start; !got.Equal(start.Add(time.Minute)) {
+		t.Errorf("market not advanced: got %v want %v", got, start.Add(time.Minute))
+	}
+}
+
+func TestMeasurementsToPoint(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := NewSymbol(0, start, time.Minute).(*Symbol)
+	cases := []struct {
+		m      interface{ ToPoint(*data.Point) }
+		name   string
+		fields [][]byte
+	}{
+		{&TradesMeasurement{market: s.market, index: 1}, "trades", [][]byte{labelPrice, labelSize}},
+		{&QuotesMeasurement{market: s.market, index: 2}, "quotes", [][]byte{labelBidPrice, labelBidSize, labelAskPrice, labelAskSize}},
+		{&BarsMeasurement{market: s.market}, "bars", [][]byte{labelOpen, labelHigh, labelLow, labelClose, labelVolume}},
+	}
+	for _, c := range cases {
+		p := data.NewPoint()
+		c.m.ToPoint(p)
+		if got := string(p.MeasurementName()); got != c.name {
+			t.Errorf("incorrect measurement name: got %s want %s", got, c.name)
+		}
+		if got := len(p.FieldKeys()); got != len(c.fields) {
+			t.Errorf("%s: incorrect number of fields: got %d want %d", c.name, got, len(c.fields))
+		}
+		for _, f := range c.fields {
+			if p.GetFieldValue(f) == nil {
+				t.Errorf("%s: field %s returned a nil value unexpectedly", c.name, f)
+			}
+		}
+	}
+
+	p := data.NewPoint()
+	(&TradesMeasurement{market: s.market, index: 1}).ToPoint(p)
+	if got := *p.Timestamp(); !got.Equal(s.market.trades[1].timestamp) {
+		t.Errorf("incorrect trade timestamp: got %v want %v", got, s.market.trades[1].timestamp)
+	}
+	if _, ok := p.GetFieldValue(labelSize).(int64); !ok {
+		t.Errorf("trade size is not an int64")
+	}
+}
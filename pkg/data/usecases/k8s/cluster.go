@@ -0,0 +1,213 @@
+package k8s
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/data/usecases/common"
+)
+
+const (
+	// podsPerNamespace is the average number of pods in a namespace
+	podsPerNamespace = 100
+	// podsPerNode is the average number of pods scheduled on a node
+	podsPerNode = 30
+	// maxReplicas is the max number of replicas of a deployment
+	maxReplicas = 50
+
+	// zipfNamespaces and zipfReplicas skew the namespaces of the deployments
+	// and their number of replicas, so a few are much larger than the others
+	zipfNamespaces = 1.1
+	zipfReplicas   = 1.5
+
+	// NodeNameFmt is the format of the name of the nodes
+	NodeNameFmt = "node-%d"
+
+	// nameAlphabet is the alphabet of the random parts of the names of the
+	// pods, without vowels, like the one of Kubernetes
+	nameAlphabet = "bcdfghjklmnpqrstvwxz2456789"
+)
+
+var (
+	// TagKeys are the keys of the tags of every container
+	TagKeys = [][]byte{
+		[]byte("namespace"),
+		[]byte("deployment"),
+		[]byte("pod"),
+		[]byte("pod_uid"),
+		[]byte("container"),
+		[]byte("node"),
+	}
+
+	namespaceChoices = []string{
+		"default",
+		"kube-system",
+		"monitoring",
+		"ingress",
+		"payments",
+		"checkout",
+		"catalog",
+		"search",
+		"auth",
+		"analytics",
+	}
+
+	appChoices = []string{
+		"api",
+		"web",
+		"worker",
+		"cache",
+		"gateway",
+		"scheduler",
+		"indexer",
+		"notifier",
+		"billing",
+		"frontend",
+	}
+
+	// appContainer is the container every pod runs, sidecars are added to
+	// the pods of a deployment with their probability
+	appContainer = "app"
+	sidecars     = []struct {
+		name        string
+		probability float64
+	}{
+		{"istio-proxy", 0.4},
+		{"log-shipper", 0.2},
+	}
+)
+
+// deployment is a set of identical pods in a namespace
+type deployment struct {
+	namespace string
+	name      string
+	// podTemplateHash is the hash in the names of the pods of the deployment
+	podTemplateHash string
+	containers      []string
+}
+
+// container is a container of a pod, which reports its measurement with the
+// tags of the pod
+type container struct {
+	tags        []common.Tag
+	measurement *ContainerMeasurement
+	// reported tells whether the series of the container was reported yet
+	reported bool
+}
+
+// pod is a replica of a deployment, replaced with a new one with a new name
+// and uid whenever it churns
+type pod struct {
+	deployment *deployment
+	containers []*container
+}
+
+// cluster is the set of deployments and the pods running them
+type cluster struct {
+	deployments []*deployment
+	pods        []*pod
+	nodeCount   int
+}
+
+// newCluster creates the deployments of podCount pods, spread over
+// namespaces and nodes, and their first pods
+func newCluster(podCount uint64, start time.Time) *cluster {
+	namespaces := namespaceNames(int(podCount/podsPerNamespace) + 1)
+	r := rand.New(rand.NewSource(rand.Int63()))
+	namespaceZipf := rand.NewZipf(r, zipfNamespaces, 1, uint64(len(namespaces)-1))
+	replicasZipf := rand.NewZipf(r, zipfReplicas, 1, maxReplicas-1)
+
+	c := &cluster{
+		pods:      make([]*pod, 0, podCount),
+		nodeCount: int(podCount/podsPerNode) + 1,
+	}
+	for remaining := podCount; remaining > 0; {
+		replicas := replicasZipf.Uint64() + 1
+		if replicas > remaining {
+			replicas = remaining
+		}
+		d := newDeployment(namespaces[namespaceZipf.Uint64()], len(c.deployments))
+		c.deployments = append(c.deployments, d)
+		for i := uint64(0); i < replicas; i++ {
+			c.pods = append(c.pods, c.newPod(d, start))
+		}
+		remaining -= replicas
+	}
+	return c
+}
+
+// namespaceNames returns the names of n namespaces
+func namespaceNames(n int) []string {
+	names := make([]string, n)
+	for i := range names {
+		if i < len(namespaceChoices) {
+			names[i] = namespaceChoices[i]
+		} else {
+			names[i] = fmt.Sprintf("namespace-%d", i)
+		}
+	}
+	return names
+}
+
+// newDeployment creates the i-th deployment of the cluster in namespace
+func newDeployment(namespace string, i int) *deployment {
+	d := &deployment{
+		namespace:       namespace,
+		name:            fmt.Sprintf("%s-%d", common.RandomStringSliceChoice(appChoices), i),
+		podTemplateHash: randomName(10),
+		containers:      []string{appContainer},
+	}
+	for _, s := range sidecars {
+		if rand.Float64() < s.probability {
+			d.containers = append(d.containers, s.name)
+		}
+	}
+	return d
+}
+
+// newPod creates a new pod of d, of which the measurements start at start
+func (c *cluster) newPod(d *deployment, start time.Time) *pod {
+	podName := d.name + "-" + d.podTemplateHash + "-" + randomName(5)
+	uid := newUID()
+	node := fmt.Sprintf(NodeNameFmt, rand.Intn(c.nodeCount))
+
+	p := &pod{
+		deployment: d,
+		containers: make([]*container, len(d.containers)),
+	}
+	for i, name := range d.containers {
+		p.containers[i] = &container{
+			tags: []common.Tag{
+				{Key: TagKeys[0], Value: d.namespace},
+				{Key: TagKeys[1], Value: d.name},
+				{Key: TagKeys[2], Value: podName},
+				{Key: TagKeys[3], Value: uid},
+				{Key: TagKeys[4], Value: name},
+				{Key: TagKeys[5], Value: node},
+			},
+			measurement: NewContainerMeasurement(start),
+		}
+	}
+	return p
+}
+
+// randomName returns n random characters of the names of the pods
+func randomName(n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = nameAlphabet[rand.Intn(len(nameAlphabet))]
+	}
+	return string(b)
+}
+
+// newUID returns a random version 4 UUID
+func newUID() string {
+	var b [16]byte
+	binary.BigEndian.PutUint64(b[:8], rand.Uint64())
+	binary.BigEndian.PutUint64(b[8:], rand.Uint64())
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
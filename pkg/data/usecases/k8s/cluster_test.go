@@ -0,0 +1,96 @@
+package k8s
+
+import (
+	"math/rand"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewCluster(t *testing.T) {
+	rand.Seed(123)
+	c := newCluster(1000, time.Now())
+	if got := len(c.pods); got != 1000 {
+		t.Fatalf("incorrect number of pods: got %d want 1000", got)
+	}
+	if c.nodeCount != 34 {
+		t.Errorf("incorrect number of nodes: got %d want 34", c.nodeCount)
+	}
+
+	replicas := map[*deployment]int{}
+	for _, p := range c.pods {
+		replicas[p.deployment]++
+	}
+	if got := len(replicas); got != len(c.deployments) {
+		t.Errorf("incorrect number of deployments with pods: got %d want %d", got, len(c.deployments))
+	}
+	namespaces := map[string]bool{}
+	for d, n := range replicas {
+		if n > maxReplicas {
+			t.Errorf("deployment %s has too many replicas: %d", d.name, n)
+		}
+		if d.containers[0] != appContainer {
+			t.Errorf("deployment %s does not run the app container first: %v", d.name, d.containers)
+		}
+		namespaces[d.namespace] = true
+	}
+	if len(namespaces) < 2 || len(namespaces) > 11 {
+		t.Errorf("incorrect number of namespaces: got %d", len(namespaces))
+	}
+}
+
+func TestClusterNewPod(t *testing.T) {
+	rand.Seed(123)
+	start := time.Now()
+	c := newCluster(10, start)
+	d := c.pods[0].deployment
+	p := c.newPod(d, start.Add(time.Hour))
+
+	if got := len(p.containers); got != len(d.containers) {
+		t.Fatalf("incorrect number of containers: got %d want %d", got, len(d.containers))
+	}
+	uidRe := regexp.MustCompile("^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$")
+	for i, ctr := range p.containers {
+		if got := len(ctr.tags); got != len(TagKeys) {
+			t.Fatalf("incorrect number of tags: got %d want %d", got, len(TagKeys))
+		}
+		for j, tag := range ctr.tags {
+			if string(tag.Key) != string(TagKeys[j]) {
+				t.Errorf("incorrect tag key %d: got %s want %s", j, tag.Key, TagKeys[j])
+			}
+		}
+		if got := ctr.tags[0].Value; got != d.namespace {
+			t.Errorf("incorrect namespace: got %s want %s", got, d.namespace)
+		}
+		pod := ctr.tags[2].Value.(string)
+		if !strings.HasPrefix(pod, d.name+"-"+d.podTemplateHash+"-") || len(pod) != len(d.name)+len(d.podTemplateHash)+7 {
+			t.Errorf("incorrect pod name: %s", pod)
+		}
+		if uid := ctr.tags[3].Value.(string); !uidRe.MatchString(uid) {
+			t.Errorf("incorrect pod uid: %s", uid)
+		}
+		if got := ctr.tags[4].Value; got != d.containers[i] {
+			t.Errorf("incorrect container: got %s want %s", got, d.containers[i])
+		}
+		if got := ctr.measurement.Timestamp; !got.Equal(start.Add(time.Hour)) {
+			t.Errorf("incorrect start of the measurement: got %v", got)
+		}
+	}
+
+	// a new pod of the same deployment is a new series
+	other := c.newPod(d, start)
+	if p.containers[0].tags[2].Value == other.containers[0].tags[2].Value {
+		t.Errorf("pods of the same deployment have the same name")
+	}
+	if p.containers[0].tags[3].Value == other.containers[0].tags[3].Value {
+		t.Errorf("pods of the same deployment have the same uid")
+	}
+}
+
+func TestNamespaceNames(t *testing.T) {
+	names := namespaceNames(12)
+	if names[0] != "default" || names[9] != "analytics" || names[10] != "namespace-10" || names[11] != "namespace-11" {
+		t.Errorf("incorrect namespace names: %v", names)
+	}
+}
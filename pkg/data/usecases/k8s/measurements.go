@@ -0,0 +1,51 @@
+package k8s
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/data/usecases/common"
+)
+
+const (
+	mib = 1 << 20
+	gib = 1 << 30
+)
+
+var (
+	labelContainerUsage = []byte("container_usage") // heap optimization
+
+	// Reuse NormalDistributions as arguments to other distributions. This is
+	// safe to do because the higher-level distribution advances the ND and
+	// immediately uses its value and saves the state
+	cpuND     = common.ND(0, 25)
+	memoryND  = common.ND(0, 4*mib)
+	networkND = common.ND(64*1024, 16*1024)
+
+	containerFields = []common.LabeledDistributionMaker{
+		{Label: []byte("cpu_usage_millicores"), DistributionMaker: func() common.Distribution { return common.CWD(cpuND, 0, 2000, rand.Float64()*500) }},
+		{Label: []byte("memory_working_set_bytes"), DistributionMaker: func() common.Distribution {
+			return common.CWD(memoryND, 16*mib, 2*gib, 16*mib+rand.Float64()*512*mib)
+		}},
+		{Label: []byte("network_receive_bytes"), DistributionMaker: func() common.Distribution { return common.MWD(networkND, 0) }},
+		{Label: []byte("network_transmit_bytes"), DistributionMaker: func() common.Distribution { return common.MWD(networkND, 0) }},
+	}
+)
+
+// ContainerMeasurement is the resource usage of a container, as reported by
+// the kubelet.
+type ContainerMeasurement struct {
+	*common.SubsystemMeasurement
+}
+
+// NewContainerMeasurement creates a new ContainerMeasurement starting at start.
+func NewContainerMeasurement(start time.Time) *ContainerMeasurement {
+	sub := common.NewSubsystemMeasurementWithDistributionMakers(start, containerFields)
+	return &ContainerMeasurement{sub}
+}
+
+// ToPoint serializes the ContainerMeasurement into a data.Point.
+func (m *ContainerMeasurement) ToPoint(p *data.Point) {
+	m.ToPointAllInt64(p, labelContainerUsage, containerFields)
+}
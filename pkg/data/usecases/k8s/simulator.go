@@ -0,0 +1,200 @@
+package k8s
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/data/usecases/common"
+)
+
+// SimulatorConfig is used to create a Simulator of a Kubernetes cluster
+type SimulatorConfig struct {
+	// Start is the beginning time for the Simulator
+	Start time.Time
+	// End is the ending time for the Simulator
+	End time.Time
+	// InitPodCount is the number of pods to start with in the first reporting period
+	InitPodCount uint64
+	// PodCount is the total number of pods to have in the last reporting period
+	PodCount uint64
+	// ChurnRate is the fraction of the running pods replaced by new pods,
+	// with new names and uids, every reporting period
+	ChurnRate float64
+	// ScaleStrategy is how the number of running pods changes over the ticks, linear if nil
+	ScaleStrategy common.ScaleStrategy
+}
+
+// Simulator generates the resource usage of the containers of a Kubernetes
+// cluster, of which pods are continuously replaced, so that every replaced
+// pod starts new series.
+type Simulator struct {
+	madePoints uint64
+	maxPoints  uint64
+
+	cluster *cluster
+
+	tick          uint64
+	ticks         uint64
+	tickPods      uint64
+	initPods      uint64
+	scaleStrategy common.ScaleStrategy
+	interval      time.Duration
+	timestampTick time.Time
+
+	churnRate float64
+	// churnDebt is the fraction of a pod left to replace by the churn rate
+	churnDebt   float64
+	churnedPods uint64
+	// tagSets is the number of unique tag sets reported
+	tagSets uint64
+
+	podIndex       int
+	containerIndex int
+}
+
+// NewSimulator produces a Simulator that conforms to the given config over the specified interval
+func (c *SimulatorConfig) NewSimulator(interval time.Duration, limit uint64) common.Simulator {
+	cluster := newCluster(c.PodCount, c.Start)
+
+	// pods are replaced by pods of the same deployment, so the number of
+	// containers, and of points per tick, never changes
+	containerCount := uint64(0)
+	for _, p := range cluster.pods {
+		containerCount += uint64(len(p.containers))
+	}
+	ticks := uint64(c.End.Sub(c.Start) / interval)
+	maxPoints := ticks * containerCount
+	if limit > 0 && limit < maxPoints {
+		// Set specified points number limit
+		maxPoints = limit
+	}
+	scaleStrategy := common.ScaleStrategyOrDefault(c.ScaleStrategy)
+	return &Simulator{
+		maxPoints:     maxPoints,
+		cluster:       cluster,
+		ticks:         ticks,
+		tickPods:      scaleStrategy.Scale(0, ticks, c.InitPodCount, c.PodCount),
+		initPods:      c.InitPodCount,
+		scaleStrategy: scaleStrategy,
+		interval:      interval,
+		timestampTick: c.Start,
+		churnRate:     c.ChurnRate,
+	}
+}
+
+// Finished tells whether we have simulated all the necessary points.
+func (s *Simulator) Finished() bool {
+	return s.madePoints >= s.maxPoints
+}
+
+// Next advances a Point to the next state in the generator.
+func (s *Simulator) Next(p *data.Point) bool {
+	if s.podIndex == len(s.cluster.pods) {
+		s.podIndex = 0
+		s.nextTick()
+	}
+
+	pod := s.cluster.pods[s.podIndex]
+	c := pod.containers[s.containerIndex]
+	for _, tag := range c.tags {
+		p.AppendTag(tag.Key, tag.Value)
+	}
+	c.measurement.ToPoint(p)
+
+	ret := uint64(s.podIndex) < s.tickPods
+	if ret && !c.reported {
+		c.reported = true
+		s.tagSets++
+	}
+	s.madePoints++
+	s.containerIndex++
+	if s.containerIndex == len(pod.containers) {
+		s.containerIndex = 0
+		s.podIndex++
+	}
+	return ret
+}
+
+// nextTick advances all the containers to the next tick, and replaces the
+// pods that churned
+func (s *Simulator) nextTick() {
+	s.tick++
+	s.timestampTick = s.timestampTick.Add(s.interval)
+	for _, p := range s.cluster.pods {
+		for _, c := range p.containers {
+			c.measurement.Tick(s.interval)
+		}
+	}
+	s.churn()
+	s.adjustNumPodsForTick()
+}
+
+// churn replaces the running pods by the churn rate. Each churned pod is
+// chosen at random and replaced by a new pod of the same deployment.
+func (s *Simulator) churn() {
+	if s.tickPods == 0 {
+		return
+	}
+	s.churnDebt += s.churnRate * float64(s.tickPods)
+	n := uint64(s.churnDebt)
+	s.churnDebt -= float64(n)
+	for i := uint64(0); i < n; i++ {
+		idx := rand.Intn(int(s.tickPods))
+		s.cluster.pods[idx] = s.cluster.newPod(s.cluster.pods[idx].deployment, s.timestampTick)
+	}
+	s.churnedPods += n
+}
+
+// adjustNumPodsForTick computes the number of pods running at the tick with
+// the ScaleStrategy
+func (s *Simulator) adjustNumPodsForTick() {
+	s.tickPods = s.scaleStrategy.Scale(s.tick, s.ticks, s.initPods, uint64(len(s.cluster.pods)))
+}
+
+// SeriesCount returns the number of unique tag sets reported so far, one per
+// container of every pod that ever ran, and the number of unique series,
+// one per field of each tag set.
+func (s *Simulator) SeriesCount() (tagSets, series uint64) {
+	return s.tagSets, s.tagSets * uint64(len(containerFields))
+}
+
+// ChurnedPods returns the number of pods replaced so far.
+func (s *Simulator) ChurnedPods() uint64 {
+	return s.churnedPods
+}
+
+// Fields returns the fields of the container measurement.
+func (s *Simulator) Fields() map[string][]string {
+	keys := make([]string, len(containerFields))
+	for i, f := range containerFields {
+		keys[i] = string(f.Label)
+	}
+	return map[string][]string{string(labelContainerUsage): keys}
+}
+
+// TagKeys returns the tag keys of the containers.
+func (s *Simulator) TagKeys() []string {
+	keys := make([]string, len(TagKeys))
+	for i, k := range TagKeys {
+		keys[i] = string(k)
+	}
+	return keys
+}
+
+// TagTypes returns the type for each tag, which are all strings.
+func (s *Simulator) TagTypes() []string {
+	types := make([]string, len(TagKeys))
+	for i := range types {
+		types[i] = "string"
+	}
+	return types
+}
+
+func (s *Simulator) Headers() *common.GeneratedDataHeaders {
+	return &common.GeneratedDataHeaders{
+		TagTypes:  s.TagTypes(),
+		TagKeys:   s.TagKeys(),
+		FieldKeys: s.Fields(),
+	}
+}
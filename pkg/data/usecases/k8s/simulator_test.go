@@ -0,0 +1,133 @@
+package k8s
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/data/usecases/common"
+)
+
+func newTestSimulator(initPods, pods uint64, churnRate float64) *Simulator {
+	rand.Seed(123)
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := &SimulatorConfig{
+		Start:        start,
+		End:          start.Add(time.Hour),
+		InitPodCount: initPods,
+		PodCount:     pods,
+		ChurnRate:    churnRate,
+	}
+	return c.NewSimulator(10*time.Second, 0).(*Simulator)
+}
+
+// runSimulator runs sim to the end and returns the written points and the
+// unique tag sets of the written points
+func runSimulator(sim *Simulator) (uint64, map[string]bool) {
+	written := uint64(0)
+	tagSets := map[string]bool{}
+	p := data.NewPoint()
+	for !sim.Finished() {
+		if sim.Next(p) {
+			written++
+			key := ""
+			for _, k := range TagKeys {
+				key += p.GetTagValue(k).(string) + ","
+			}
+			tagSets[key] = true
+		}
+		p.Reset()
+	}
+	return written, tagSets
+}
+
+func TestSimulatorNoChurn(t *testing.T) {
+	sim := newTestSimulator(20, 20, 0)
+	containers := uint64(0)
+	for _, p := range sim.cluster.pods {
+		containers += uint64(len(p.containers))
+	}
+	if want := 360 * containers; sim.maxPoints != want {
+		t.Fatalf("incorrect max points: got %d want %d", sim.maxPoints, want)
+	}
+
+	written, tagSets := runSimulator(sim)
+	if written != sim.maxPoints {
+		t.Errorf("incorrect number of written points: got %d want %d", written, sim.maxPoints)
+	}
+	if uint64(len(tagSets)) != containers {
+		t.Errorf("incorrect number of tag sets: got %d want %d", len(tagSets), containers)
+	}
+	if got := sim.ChurnedPods(); got != 0 {
+		t.Errorf("pods churned without a churn rate: %d", got)
+	}
+}
+
+func TestSimulatorChurn(t *testing.T) {
+	sim := newTestSimulator(100, 100, 0.01)
+	_, tagSets := runSimulator(sim)
+
+	// 1 pod out of 100 is replaced at each of the 359 ticks after the first
+	if got := sim.ChurnedPods(); got != 359 {
+		t.Errorf("incorrect number of churned pods: got %d want 359", got)
+	}
+	gotTagSets, gotSeries := sim.SeriesCount()
+	if gotTagSets != uint64(len(tagSets)) {
+		t.Errorf("incorrect count of tag sets: got %d want %d", gotTagSets, len(tagSets))
+	}
+	if want := gotTagSets * uint64(len(containerFields)); gotSeries != want {
+		t.Errorf("incorrect count of series: got %d want %d", gotSeries, want)
+	}
+	// every churned pod starts at least one new tag set
+	if gotTagSets < 100+359 {
+		t.Errorf("too few tag sets for the churned pods: %d", gotTagSets)
+	}
+}
+
+func TestSimulatorTimestamps(t *testing.T) {
+	// half of the pods churn at every tick, and the new ones must report at
+	// the time of the tick like the others
+	sim := newTestSimulator(10, 10, 0.5)
+	perTick := sim.maxPoints / 360
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	p := data.NewPoint()
+	for i := uint64(0); !sim.Finished(); i++ {
+		sim.Next(p)
+		want := start.Add(time.Duration(i/perTick) * 10 * time.Second)
+		if got := p.Timestamp(); !got.Equal(want) {
+			t.Fatalf("incorrect timestamp of point %d: got %v want %v", i, got, want)
+		}
+		p.Reset()
+	}
+}
+
+func TestSimulatorInitialPods(t *testing.T) {
+	sim := newTestSimulator(1, 10, 0.1)
+	written, _ := runSimulator(sim)
+	if written >= sim.maxPoints {
+		t.Errorf("all points written when starting with 1 pod: %d", written)
+	}
+	if sim.tickPods != 10 {
+		t.Errorf("incorrect number of pods at the last tick: got %d want 10", sim.tickPods)
+	}
+}
+
+func TestSimulatorHeaders(t *testing.T) {
+	sim := newTestSimulator(1, 1, 0)
+	var _ common.SeriesCounter = sim
+
+	h := sim.Headers()
+	if got := len(h.TagKeys); got != 6 || h.TagKeys[3] != "pod_uid" {
+		t.Errorf("incorrect tag keys: %v", h.TagKeys)
+	}
+	for _, typ := range h.TagTypes {
+		if typ != "string" {
+			t.Errorf("incorrect tag type: %s", typ)
+		}
+	}
+	fields := h.FieldKeys["container_usage"]
+	if len(h.FieldKeys) != 1 || len(fields) != 4 || fields[0] != "cpu_usage_millicores" {
+		t.Errorf("incorrect fields: %v", h.FieldKeys)
+	}
+}
@@ -4,8 +4,11 @@ import (
 	"fmt"
 	"github.com/timescale/tsbs/internal/utils"
 	"github.com/timescale/tsbs/pkg/data/usecases/common"
+	"github.com/timescale/tsbs/pkg/data/usecases/custom"
 	"github.com/timescale/tsbs/pkg/data/usecases/devops"
+	"github.com/timescale/tsbs/pkg/data/usecases/finance"
 	"github.com/timescale/tsbs/pkg/data/usecases/iot"
+	"github.com/timescale/tsbs/pkg/data/usecases/k8s"
 	"math"
 )
 
@@ -22,6 +25,10 @@ func GetSimulatorConfig(dgc *common.DataGeneratorConfig) (common.SimulatorConfig
 	if err != nil {
 		return nil, fmt.Errorf(errCannotParseTimeFmt, dgc.TimeEnd, err)
 	}
+	scaleStrategy, err := common.NewScaleStrategy(dgc.ScaleStrategy, dgc.ScaleSteps)
+	if err != nil {
+		return nil, err
+	}
 
 	switch dgc.Use {
 	case common.UseCaseDevops:
@@ -32,6 +39,7 @@ func GetSimulatorConfig(dgc *common.DataGeneratorConfig) (common.SimulatorConfig
 			InitHostCount:   dgc.InitialScale,
 			HostCount:       dgc.Scale,
 			HostConstructor: devops.NewHost,
+			ScaleStrategy:   scaleStrategy,
 		}
 	case common.UseCaseIoT:
 		ret = &iot.SimulatorConfig{
@@ -41,6 +49,16 @@ func GetSimulatorConfig(dgc *common.DataGeneratorConfig) (common.SimulatorConfig
 			InitGeneratorScale:   dgc.InitialScale,
 			GeneratorScale:       dgc.Scale,
 			GeneratorConstructor: iot.NewTruck,
+			ScaleStrategy:        scaleStrategy,
+		}
+	case common.UseCaseFinance:
+		ret = &finance.SimulatorConfig{
+			Start: tsStart,
+			End:   tsEnd,
+
+			InitSymbolCount: dgc.InitialScale,
+			SymbolCount:     dgc.Scale,
+			ScaleStrategy:   scaleStrategy,
 		}
 	case common.UseCaseCPUOnly:
 		ret = &devops.CPUOnlySimulatorConfig{
@@ -50,6 +68,7 @@ func GetSimulatorConfig(dgc *common.DataGeneratorConfig) (common.SimulatorConfig
 			InitHostCount:   dgc.InitialScale,
 			HostCount:       dgc.Scale,
 			HostConstructor: devops.NewHostCPUOnly,
+			ScaleStrategy:   scaleStrategy,
 		}
 	case common.UseCaseCPUSingle:
 		ret = &devops.CPUOnlySimulatorConfig{
@@ -59,6 +78,7 @@ func GetSimulatorConfig(dgc *common.DataGeneratorConfig) (common.SimulatorConfig
 			InitHostCount:   dgc.InitialScale,
 			HostCount:       dgc.Scale,
 			HostConstructor: devops.NewHostCPUSingle,
+			ScaleStrategy:   scaleStrategy,
 		}
 	case common.UseCaseDevopsGeneric:
 		if dgc.InitialScale == dgc.Scale {
@@ -74,8 +94,30 @@ func GetSimulatorConfig(dgc *common.DataGeneratorConfig) (common.SimulatorConfig
 				HostCount:       dgc.Scale,
 				HostConstructor: devops.NewHostGenericMetrics,
 				MaxMetricCount:  dgc.MaxMetricCountPerHost,
+				ScaleStrategy:   scaleStrategy,
 			},
 		}
+	case common.UseCaseK8s:
+		ret = &k8s.SimulatorConfig{
+			Start: tsStart,
+			End:   tsEnd,
+
+			InitPodCount:  dgc.InitialScale,
+			PodCount:      dgc.Scale,
+			ChurnRate:     dgc.ChurnRate,
+			ScaleStrategy: scaleStrategy,
+		}
+	case common.UseCaseCustom:
+		cfg, err := custom.NewSimulatorConfig(dgc.UseCaseFile, dgc.LogInterval)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Start = tsStart
+		cfg.End = tsEnd
+		cfg.InitEntityCount = dgc.InitialScale
+		cfg.EntityCount = dgc.Scale
+		cfg.ScaleStrategy = scaleStrategy
+		ret = cfg
 	default:
 		err = fmt.Errorf("unknown use case: '%s'", dgc.Use)
 	}
@@ -1,9 +1,15 @@
 package usecases
 
 import (
+	"io/ioutil"
+	"os"
+
 	"github.com/timescale/tsbs/pkg/data/usecases/common"
+	"github.com/timescale/tsbs/pkg/data/usecases/custom"
 	"github.com/timescale/tsbs/pkg/data/usecases/devops"
+	"github.com/timescale/tsbs/pkg/data/usecases/finance"
 	"github.com/timescale/tsbs/pkg/data/usecases/iot"
+	"github.com/timescale/tsbs/pkg/data/usecases/k8s"
 	"reflect"
 	"testing"
 	"time"
@@ -36,12 +42,45 @@ func TestGetSimulatorConfig(t *testing.T) {
 
 	checkType(common.UseCaseDevops, &devops.DevopsSimulatorConfig{})
 	checkType(common.UseCaseIoT, &iot.SimulatorConfig{})
+	checkType(common.UseCaseFinance, &finance.SimulatorConfig{})
 	checkType(common.UseCaseCPUOnly, &devops.CPUOnlySimulatorConfig{})
 	checkType(common.UseCaseCPUSingle, &devops.CPUOnlySimulatorConfig{})
+	checkType(common.UseCaseK8s, &k8s.SimulatorConfig{})
+
+	f, err := ioutil.TempFile("", "use-case-*.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	f.WriteString("measurements: [{name: m, fields: [{name: f, distribution: {type: nd, stddev: 1}}]}]")
+	f.Close()
+	dgc.UseCaseFile = f.Name()
+	checkType(common.UseCaseCustom, &custom.SimulatorConfig{})
+
+	dgc.UseCaseFile = ""
+	dgc.Use = common.UseCaseCustom
+	if _, err := GetSimulatorConfig(dgc); err == nil {
+		t.Errorf("unexpected lack of error for custom use case without a file")
+	}
 
 	dgc.Use = "bogus use case"
-	_, err := GetSimulatorConfig(dgc)
+	_, err = GetSimulatorConfig(dgc)
 	if err == nil {
 		t.Errorf("unexpected lack of error for bogus use case")
 	}
+
+	dgc.Use = common.UseCaseDevops
+	dgc.ScaleStrategy = common.ScaleStrategyDecommission
+	scfg, err := GetSimulatorConfig(dgc)
+	if err != nil {
+		t.Fatalf("unexpected error with scale strategy: %v", err)
+	}
+	if got := scfg.(*devops.DevopsSimulatorConfig).ScaleStrategy; got != (common.DecommissionScale{}) {
+		t.Errorf("incorrect scale strategy: got %#v", got)
+	}
+
+	dgc.ScaleStrategy = "bogus scale strategy"
+	if _, err := GetSimulatorConfig(dgc); err == nil {
+		t.Errorf("unexpected lack of error for bogus scale strategy")
+	}
 }
@@ -0,0 +1,165 @@
+package mixed
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/data/usecases/common"
+	"github.com/timescale/tsbs/pkg/query"
+	"github.com/timescale/tsbs/pkg/targets"
+	"golang.org/x/time/rate"
+)
+
+// monitoredBenchmark wraps a targets.Benchmark so that the data source is
+// rate limited and all processed batches are reported to a pressureMonitor
+type monitoredBenchmark struct {
+	targets.Benchmark
+	monitor *pressureMonitor
+	limiter *rate.Limiter
+}
+
+func (b *monitoredBenchmark) GetDataSource() targets.DataSource {
+	ds := b.Benchmark.GetDataSource()
+	if b.limiter == nil {
+		return ds
+	}
+	return &rateLimitedDataSource{DataSource: ds, limiter: b.limiter}
+}
+
+func (b *monitoredBenchmark) GetProcessor() targets.Processor {
+	return &monitoredProcessor{Processor: b.Benchmark.GetProcessor(), monitor: b.monitor}
+}
+
+// rateLimitedDataSource releases items from the wrapped DataSource no faster
+// than allowed by the limiter
+type rateLimitedDataSource struct {
+	targets.DataSource
+	limiter *rate.Limiter
+}
+
+func (d *rateLimitedDataSource) NextItem() data.LoadedPoint {
+	if err := d.limiter.Wait(context.Background()); err != nil {
+		panic(fmt.Sprintf("could not wait for write rate limiter: %v", err))
+	}
+	return d.DataSource.NextItem()
+}
+
+func (d *rateLimitedDataSource) Headers() *common.GeneratedDataHeaders {
+	return d.DataSource.Headers()
+}
+
+// monitoredProcessor reports every processed batch to the pressureMonitor
+type monitoredProcessor struct {
+	targets.Processor
+	monitor *pressureMonitor
+}
+
+func (p *monitoredProcessor) Init(workerNum int, doLoad, hashWorkers bool) {
+	p.Processor.Init(workerNum, doLoad, hashWorkers)
+	// workers are only started once the database has been created
+	p.monitor.markReady()
+}
+
+func (p *monitoredProcessor) ProcessBatch(b targets.Batch, doLoad bool) (metricCount, rowCount uint64) {
+	p.monitor.batchStarted()
+	metricCount, rowCount = p.Processor.ProcessBatch(b, doLoad)
+	p.monitor.batchDone(metricCount)
+	return metricCount, rowCount
+}
+
+// SetRetrier hands the retry policy to the wrapped processor, if it supports retries
+func (p *monitoredProcessor) SetRetrier(r targets.BatchRetrier) {
+	if rp, ok := p.Processor.(targets.RetryingProcessor); ok {
+		rp.SetRetrier(r)
+	}
+}
+
+// Close closes the wrapped processor, if it needs closing
+func (p *monitoredProcessor) Close(doLoad bool) {
+	if c, ok := p.Processor.(targets.ProcessorCloser); ok {
+		c.Close(doLoad)
+	}
+}
+
+// pressureProcessor wraps a query.Processor and additionally reports the
+// latency of each query under a label that includes the current write pressure
+type pressureProcessor struct {
+	query.Processor
+	monitor *pressureMonitor
+}
+
+// newPressureProcessor wraps p in a pressureProcessor that is a
+// query.ResultProcessor and a query.TimeoutProcessor if p is one
+func newPressureProcessor(p query.Processor, monitor *pressureMonitor) query.Processor {
+	pp := &pressureProcessor{Processor: p, monitor: monitor}
+	_, results := p.(query.ResultProcessor)
+	_, timeouts := p.(query.TimeoutProcessor)
+	switch {
+	case results && timeouts:
+		return &pressureResultTimeoutProcessor{pressureResultProcessor{pp}}
+	case results:
+		return &pressureResultProcessor{pp}
+	case timeouts:
+		return &pressureTimeoutProcessor{pp}
+	}
+	return pp
+}
+
+func (p *pressureProcessor) ProcessQuery(q query.Query, isWarm bool) ([]*query.Stat, error) {
+	pressure := p.monitor.current()
+	stats, err := p.Processor.ProcessQuery(q, isWarm)
+	if err != nil {
+		return stats, err
+	}
+	return withPressure(stats, pressure), nil
+}
+
+// withPressure appends a partial stat labeled with the write pressure to the
+// stats for every stat of a query
+func withPressure(stats []*query.Stat, pressure string) []*query.Stat {
+	n := len(stats)
+	for _, s := range stats[:n] {
+		if s.IsPartial() {
+			continue
+		}
+		label := fmt.Sprintf(pressureLabelFmt, s.Label(), pressure)
+		// partial so the query is not counted twice in the overall stats
+		stats = append(stats, query.GetPartialStat().Init([]byte(label), s.Value()))
+	}
+	return stats
+}
+
+// pressureResultProcessor is a pressureProcessor of a query.ResultProcessor
+type pressureResultProcessor struct {
+	*pressureProcessor
+}
+
+func (p *pressureResultProcessor) ProcessQueryResult(q query.Query, isWarm bool) ([]*query.Stat, *query.Result, error) {
+	pressure := p.monitor.current()
+	stats, result, err := p.Processor.(query.ResultProcessor).ProcessQueryResult(q, isWarm)
+	if err != nil {
+		return stats, result, err
+	}
+	return withPressure(stats, pressure), result, nil
+}
+
+// pressureTimeoutProcessor is a pressureProcessor of a query.TimeoutProcessor
+type pressureTimeoutProcessor struct {
+	*pressureProcessor
+}
+
+func (p *pressureTimeoutProcessor) SetQueryTimeout(timeout time.Duration) {
+	p.Processor.(query.TimeoutProcessor).SetQueryTimeout(timeout)
+}
+
+// pressureResultTimeoutProcessor is a pressureProcessor of a processor that
+// is both a query.ResultProcessor and a query.TimeoutProcessor
+type pressureResultTimeoutProcessor struct {
+	pressureResultProcessor
+}
+
+func (p *pressureResultTimeoutProcessor) SetQueryTimeout(timeout time.Duration) {
+	p.Processor.(query.TimeoutProcessor).SetQueryTimeout(timeout)
+}
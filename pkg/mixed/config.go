@@ -0,0 +1,72 @@
+package mixed
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/timescale/tsbs/load"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+// QueryFlagPrefix is the prefix of the query runner flags, which makes them
+// the query section of a BenchmarkRunnerConfig
+const QueryFlagPrefix = "query."
+
+const (
+	defaultPressureSamplePeriod = time.Second
+	pressureBucketSeparator     = ","
+	errBadPressureBucketsFmt    = "write pressure buckets must be comma-separated ascending positive numbers, got '%s'"
+)
+
+// BenchmarkRunnerConfig is the configuration of a mixed read/write benchmark.
+// The Load and Query parts are handed to the load and query runners as is,
+// with the exception of the rate limits, which are controlled by WriteRate
+// and ReadRate, and the database name of the queries, which is the one of
+// the load.
+type BenchmarkRunnerConfig struct {
+	Load  load.BenchmarkRunnerConfig  `yaml:"load" mapstructure:"load" json:"load"`
+	Query query.BenchmarkRunnerConfig `yaml:"query" mapstructure:"query" json:"query"`
+
+	// WriteRate is the maximum number of items (points) per second read from the data source, 0 = no limit
+	WriteRate uint64 `yaml:"write-rate" mapstructure:"write-rate" json:"write-rate"`
+	// ReadRate is the maximum number of queries per second, 0 = no limit
+	ReadRate uint64 `yaml:"read-rate" mapstructure:"read-rate" json:"read-rate"`
+	// PressureSamplePeriod is how often the current write rate is sampled
+	PressureSamplePeriod time.Duration `yaml:"pressure-sample-period" mapstructure:"pressure-sample-period" json:"pressure-sample-period"`
+	// PressureBuckets are the write rate (metrics/sec) boundaries used to split query latencies
+	PressureBuckets string `yaml:"pressure-buckets" mapstructure:"pressure-buckets" json:"pressure-buckets"`
+}
+
+// AddToFlagSet adds the command line flags specific to a mixed benchmark and
+// the flags of the query runner, prefixed with QueryFlagPrefix since their
+// names collide with the ones of the loader, to the flag set. The flags of
+// the loader are not added, so that they are the ones of the load program
+// of the target.
+func (c BenchmarkRunnerConfig) AddToFlagSet(fs *pflag.FlagSet) {
+	c.Query.AddToFlagSetWithPrefix(fs, QueryFlagPrefix)
+	fs.Uint64("write-rate", 0, "Limit the rate of points read from the data source per second, 0 = no limit")
+	fs.Uint64("read-rate", 0, "Limit the rate of queries per second, 0 = no limit")
+	fs.Duration("pressure-sample-period", defaultPressureSamplePeriod, "Period in which the current write rate is sampled")
+	fs.String("pressure-buckets", "", "Comma-separated write rates (metrics/sec) used to split query latencies by write pressure. "+
+		"Default '' => queries are only split into 'idle' and 'loading'")
+}
+
+// parsePressureBuckets parses a comma separated list of ascending write rates
+func parsePressureBuckets(buckets string) ([]float64, error) {
+	if strings.TrimSpace(buckets) == "" {
+		return nil, nil
+	}
+	parts := strings.Split(buckets, pressureBucketSeparator)
+	bounds := make([]float64, len(parts))
+	for i, part := range parts {
+		bound, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil || bound <= 0 || (i > 0 && bound <= bounds[i-1]) {
+			return nil, fmt.Errorf(errBadPressureBucketsFmt, buckets)
+		}
+		bounds[i] = bound
+	}
+	return bounds, nil
+}
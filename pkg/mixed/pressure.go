@@ -0,0 +1,129 @@
+package mixed
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	pressureIdle     = "idle"
+	pressureLoading  = "loading"
+	pressureLabelFmt = "%s [write pressure: %s]"
+)
+
+// pressureMonitor keeps track of the write load currently being handled by the
+// database. Load workers report every processed batch, and the current write
+// rate is sampled periodically, so queries can be labeled with the write
+// pressure under which they were executed.
+type pressureMonitor struct {
+	bounds []float64
+
+	metricCnt uint64
+	inFlight  int64
+	// currentRate holds the bits of the last sampled rate (float64) in metrics/sec
+	currentRate uint64
+	finished    uint32
+
+	readyOnce sync.Once
+	ready     chan struct{}
+}
+
+func newPressureMonitor(bounds []float64) *pressureMonitor {
+	return &pressureMonitor{
+		bounds: bounds,
+		ready:  make(chan struct{}),
+	}
+}
+
+// markReady signals that the load workers have started, i.e. that the
+// database has been created and can be queried
+func (m *pressureMonitor) markReady() {
+	m.readyOnce.Do(func() { close(m.ready) })
+}
+
+// markFinished signals that no more data is going to be written
+func (m *pressureMonitor) markFinished() {
+	atomic.StoreUint32(&m.finished, 1)
+	atomic.StoreUint64(&m.currentRate, math.Float64bits(0))
+	m.markReady()
+}
+
+func (m *pressureMonitor) batchStarted() {
+	atomic.AddInt64(&m.inFlight, 1)
+}
+
+func (m *pressureMonitor) batchDone(metricCnt uint64) {
+	atomic.AddUint64(&m.metricCnt, metricCnt)
+	atomic.AddInt64(&m.inFlight, -1)
+}
+
+// rate returns the last sampled write rate in metrics/sec
+func (m *pressureMonitor) rate() float64 {
+	return math.Float64frombits(atomic.LoadUint64(&m.currentRate))
+}
+
+// sample computes the write rate since the previous sample
+func (m *pressureMonitor) sample(prevCnt uint64, took time.Duration) uint64 {
+	cnt := atomic.LoadUint64(&m.metricCnt)
+	if atomic.LoadUint32(&m.finished) == 1 || took <= 0 {
+		return cnt
+	}
+	r := float64(cnt-prevCnt) / took.Seconds()
+	atomic.StoreUint64(&m.currentRate, math.Float64bits(r))
+	return cnt
+}
+
+// run samples the write rate every period until done is closed
+func (m *pressureMonitor) run(period time.Duration, done <-chan struct{}) {
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+	prevTime := time.Now()
+	prevCnt := uint64(0)
+	for {
+		select {
+		case <-done:
+			return
+		case now := <-ticker.C:
+			prevCnt = m.sample(prevCnt, now.Sub(prevTime))
+			prevTime = now
+		}
+	}
+}
+
+// current returns the name of the write pressure bucket the database is in at the moment
+func (m *pressureMonitor) current() string {
+	if atomic.LoadUint32(&m.finished) == 1 {
+		return pressureIdle
+	}
+	r := m.rate()
+	if r == 0 && atomic.LoadInt64(&m.inFlight) > 0 {
+		// no full sample period has passed yet, but data is already being written
+		return pressureLoading
+	}
+	return bucketLabel(r, m.bounds)
+}
+
+// bucketLabel returns the name of the bucket a given write rate belongs to.
+// A rate of 0 is always 'idle'. With no bounds all other rates are 'loading'.
+// Otherwise bounds b0 < b1 < ... < bn produce the buckets
+// '<b0', 'b0-b1', ..., '>=bn' (all in metrics/sec).
+func bucketLabel(r float64, bounds []float64) string {
+	if r <= 0 {
+		return pressureIdle
+	}
+	if len(bounds) == 0 {
+		return pressureLoading
+	}
+	if r < bounds[0] {
+		return fmt.Sprintf("<%.0f metrics/s", bounds[0])
+	}
+	for i := 1; i < len(bounds); i++ {
+		if r < bounds[i] {
+			return fmt.Sprintf("%.0f-%.0f metrics/s", bounds[i-1], bounds[i])
+		}
+	}
+	return fmt.Sprintf(">=%.0f metrics/s", bounds[len(bounds)-1])
+}
@@ -0,0 +1,104 @@
+package mixed
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParsePressureBuckets(t *testing.T) {
+	cases := []struct {
+		desc      string
+		in        string
+		want      []float64
+		shouldErr bool
+	}{
+		{desc: "empty", in: "", want: nil},
+		{desc: "single", in: "1000", want: []float64{1000}},
+		{desc: "multiple with spaces", in: "1000, 5000,10000", want: []float64{1000, 5000, 10000}},
+		{desc: "not a number", in: "1000,a", shouldErr: true},
+		{desc: "not ascending", in: "5000,1000", shouldErr: true},
+		{desc: "zero", in: "0,1000", shouldErr: true},
+	}
+	for _, c := range cases {
+		got, err := parsePressureBuckets(c.in)
+		if c.shouldErr {
+			if err == nil {
+				t.Errorf("%s: expected error, got none", c.desc)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", c.desc, err)
+			continue
+		}
+		if len(got) != len(c.want) {
+			t.Errorf("%s: wrong bounds: got %v want %v", c.desc, got, c.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != c.want[i] {
+				t.Errorf("%s: wrong bounds: got %v want %v", c.desc, got, c.want)
+			}
+		}
+	}
+}
+
+func TestBucketLabel(t *testing.T) {
+	bounds := []float64{1000, 5000}
+	cases := []struct {
+		rate   float64
+		bounds []float64
+		want   string
+	}{
+		{rate: 0, bounds: nil, want: pressureIdle},
+		{rate: 0, bounds: bounds, want: pressureIdle},
+		{rate: 10, bounds: nil, want: pressureLoading},
+		{rate: 10, bounds: bounds, want: "<1000 metrics/s"},
+		{rate: 1000, bounds: bounds, want: "1000-5000 metrics/s"},
+		{rate: 4999, bounds: bounds, want: "1000-5000 metrics/s"},
+		{rate: 5000, bounds: bounds, want: ">=5000 metrics/s"},
+	}
+	for _, c := range cases {
+		if got := bucketLabel(c.rate, c.bounds); got != c.want {
+			t.Errorf("wrong label for rate %f and bounds %v: got %s want %s", c.rate, c.bounds, got, c.want)
+		}
+	}
+}
+
+func TestPressureMonitor(t *testing.T) {
+	m := newPressureMonitor([]float64{100})
+	if got := m.current(); got != pressureIdle {
+		t.Errorf("expected %s before any writes, got %s", pressureIdle, got)
+	}
+
+	m.batchStarted()
+	if got := m.current(); got != pressureLoading {
+		t.Errorf("expected %s while first batch in flight, got %s", pressureLoading, got)
+	}
+	m.batchDone(300)
+
+	prev := m.sample(0, time.Second)
+	if prev != 300 {
+		t.Errorf("expected sample to return count 300, got %d", prev)
+	}
+	if got := m.current(); got != ">=100 metrics/s" {
+		t.Errorf("expected >=100 metrics/s after sample, got %s", got)
+	}
+
+	m.batchStarted()
+	m.batchDone(50)
+	m.sample(prev, time.Second)
+	if got := m.current(); got != "<100 metrics/s" {
+		t.Errorf("expected <100 metrics/s after second sample, got %s", got)
+	}
+
+	m.markFinished()
+	if got := m.current(); got != pressureIdle {
+		t.Errorf("expected %s after load finished, got %s", pressureIdle, got)
+	}
+	select {
+	case <-m.ready:
+	default:
+		t.Errorf("finished monitor should be marked as ready")
+	}
+}
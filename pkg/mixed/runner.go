@@ -0,0 +1,86 @@
+package mixed
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/timescale/tsbs/load"
+	"github.com/timescale/tsbs/pkg/query"
+	"github.com/timescale/tsbs/pkg/targets"
+	"golang.org/x/time/rate"
+)
+
+// BenchmarkRunner runs a load benchmark and a query benchmark against the same
+// database at the same time. Query latencies are reported both per query type
+// and per query type and write pressure at the time the query was sent.
+type BenchmarkRunner struct {
+	BenchmarkRunnerConfig
+	bounds  []float64
+	loader  load.BenchmarkRunner
+	querier *query.BenchmarkRunner
+	monitor *pressureMonitor
+}
+
+// NewBenchmarkRunner creates a new instance of a mixed read/write BenchmarkRunner
+func NewBenchmarkRunner(c BenchmarkRunnerConfig) (*BenchmarkRunner, error) {
+	bounds, err := parsePressureBuckets(c.PressureBuckets)
+	if err != nil {
+		return nil, err
+	}
+	if c.PressureSamplePeriod <= 0 {
+		c.PressureSamplePeriod = defaultPressureSamplePeriod
+	}
+	if c.ReadRate > 0 {
+		c.Query.LimitRPS = c.ReadRate
+	}
+	// the queries run against the database that is loaded
+	c.Query.DBName = c.Load.DBName
+	return &BenchmarkRunner{
+		BenchmarkRunnerConfig: c,
+		bounds:                bounds,
+		loader:                load.GetBenchmarkRunner(c.Load),
+		querier:               query.NewBenchmarkRunner(c.Query),
+		monitor:               newPressureMonitor(bounds),
+	}, nil
+}
+
+// QueryRunner returns the runner executing the queries, processors may use it
+// to read the query runner configuration (e.g. DoPrintResponses)
+func (r *BenchmarkRunner) QueryRunner() *query.BenchmarkRunner {
+	return r.querier
+}
+
+// Run loads the data from the Benchmark b and, as soon as the database is
+// created, starts executing the queries with processors created by processorCreateFn.
+// It returns when both the load and the queries are complete.
+func (r *BenchmarkRunner) Run(b targets.Benchmark, queryPool *sync.Pool, processorCreateFn query.ProcessorCreate) {
+	bench := &monitoredBenchmark{Benchmark: b, monitor: r.monitor}
+	if r.WriteRate > 0 {
+		burst := int(r.Load.BatchSize)
+		if burst < 1 {
+			burst = 1
+		}
+		bench.limiter = rate.NewLimiter(rate.Limit(r.WriteRate), burst)
+	}
+
+	done := make(chan struct{})
+	go r.monitor.run(r.PressureSamplePeriod, done)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		r.loader.RunBenchmark(bench)
+		r.monitor.markFinished()
+		fmt.Println("load complete, remaining queries run without write pressure")
+	}()
+
+	// don't query a database that is still being created
+	<-r.monitor.ready
+	r.querier.Run(queryPool, func() query.Processor {
+		return newPressureProcessor(processorCreateFn(), r.monitor)
+	})
+
+	wg.Wait()
+	close(done)
+}
@@ -0,0 +1,219 @@
+package mixed
+
+import (
+	"encoding/gob"
+	"io/ioutil"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/timescale/tsbs/load"
+	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/data/usecases/common"
+	"github.com/timescale/tsbs/pkg/query"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+type testBatch struct {
+	len uint
+}
+
+func (b *testBatch) Len() uint                 { return b.len }
+func (b *testBatch) Append(_ data.LoadedPoint) { b.len++ }
+
+type testFactory struct{}
+
+func (f *testFactory) New() targets.Batch { return &testBatch{} }
+
+type testDataSource struct {
+	left int
+}
+
+func (d *testDataSource) NextItem() data.LoadedPoint {
+	if d.left == 0 {
+		return data.LoadedPoint{}
+	}
+	d.left--
+	return data.NewLoadedPoint(d.left)
+}
+
+func (d *testDataSource) Headers() *common.GeneratedDataHeaders { return nil }
+
+type testLoadProcessor struct {
+	batches *uint64
+	closed  *uint64
+}
+
+func (p *testLoadProcessor) Init(int, bool, bool) {}
+
+func (p *testLoadProcessor) ProcessBatch(b targets.Batch, _ bool) (uint64, uint64) {
+	atomic.AddUint64(p.batches, 1)
+	return uint64(b.Len()), 0
+}
+
+func (p *testLoadProcessor) Close(bool) {
+	atomic.AddUint64(p.closed, 1)
+}
+
+type testBenchmark struct {
+	ds      *testDataSource
+	batches uint64
+	closed  uint64
+}
+
+func (b *testBenchmark) GetDataSource() targets.DataSource         { return b.ds }
+func (b *testBenchmark) GetBatchFactory() targets.BatchFactory     { return &testFactory{} }
+func (b *testBenchmark) GetPointIndexer(uint) targets.PointIndexer { return &targets.ConstantIndexer{} }
+func (b *testBenchmark) GetDBCreator() targets.DBCreator           { return nil }
+func (b *testBenchmark) GetProcessor() targets.Processor {
+	return &testLoadProcessor{batches: &b.batches, closed: &b.closed}
+}
+
+type testQueryProcessor struct {
+	count *uint64
+}
+
+func (p *testQueryProcessor) Init(int) {}
+
+func (p *testQueryProcessor) ProcessQuery(q query.Query, _ bool) ([]*query.Stat, error) {
+	atomic.AddUint64(p.count, 1)
+	return []*query.Stat{query.GetStat().Init(q.HumanLabelName(), 1.5)}, nil
+}
+
+func TestPressureProcessor(t *testing.T) {
+	var cnt uint64
+	m := newPressureMonitor(nil)
+	p := &pressureProcessor{Processor: &testQueryProcessor{count: &cnt}, monitor: m}
+	q := query.NewHTTP()
+	q.HumanLabel = []byte("foo")
+	stats, err := p.ProcessQuery(q, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(stats) != 2 {
+		t.Fatalf("expected 2 stats, got %d", len(stats))
+	}
+	if stats[0].IsPartial() || string(stats[0].Label()) != "foo" {
+		t.Errorf("original stat changed: %s partial %v", stats[0].Label(), stats[0].IsPartial())
+	}
+	if !stats[1].IsPartial() || string(stats[1].Label()) != "foo [write pressure: idle]" || stats[1].Value() != 1.5 {
+		t.Errorf("wrong pressure stat: %s %f partial %v", stats[1].Label(), stats[1].Value(), stats[1].IsPartial())
+	}
+}
+
+// testResultProcessor is a testQueryProcessor that also returns results and
+// takes a query timeout
+type testResultProcessor struct {
+	testQueryProcessor
+	timeout time.Duration
+}
+
+func (p *testResultProcessor) ProcessQueryResult(q query.Query, isWarm bool) ([]*query.Stat, *query.Result, error) {
+	stats, err := p.ProcessQuery(q, isWarm)
+	return stats, &query.Result{Rows: []query.ResultRow{{Values: []float64{1.5}}}}, err
+}
+
+func (p *testResultProcessor) SetQueryTimeout(timeout time.Duration) {
+	p.timeout = timeout
+}
+
+func TestNewPressureProcessor(t *testing.T) {
+	var cnt uint64
+	m := newPressureMonitor(nil)
+	if p := newPressureProcessor(&testQueryProcessor{count: &cnt}, m); isResultProcessor(p) || isTimeoutProcessor(p) {
+		t.Errorf("plain processor became a result or timeout processor")
+	}
+
+	inner := &testResultProcessor{testQueryProcessor: testQueryProcessor{count: &cnt}}
+	p := newPressureProcessor(inner, m)
+	if !isResultProcessor(p) || !isTimeoutProcessor(p) {
+		t.Fatalf("result and timeout processor lost its interfaces")
+	}
+	p.(query.TimeoutProcessor).SetQueryTimeout(time.Second)
+	if inner.timeout != time.Second {
+		t.Errorf("query timeout not passed on: got %v", inner.timeout)
+	}
+	q := query.NewHTTP()
+	q.HumanLabel = []byte("foo")
+	stats, result, err := p.(query.ResultProcessor).ProcessQueryResult(q, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(stats) != 2 || string(stats[1].Label()) != "foo [write pressure: idle]" {
+		t.Errorf("missing pressure stat: got %d stats", len(stats))
+	}
+	if result == nil || len(result.Rows) != 1 {
+		t.Errorf("result not passed on: %v", result)
+	}
+}
+
+func isResultProcessor(p query.Processor) bool {
+	_, ok := p.(query.ResultProcessor)
+	return ok
+}
+
+func isTimeoutProcessor(p query.Processor) bool {
+	_, ok := p.(query.TimeoutProcessor)
+	return ok
+}
+
+func TestBenchmarkRunnerRun(t *testing.T) {
+	const numQueries = 20
+	const numPoints = 95
+
+	f, err := ioutil.TempFile("", "mixed_queries_*")
+	if err != nil {
+		t.Fatalf("could not create temp file: %v", err)
+	}
+	defer os.Remove(f.Name())
+	enc := gob.NewEncoder(f)
+	for i := 0; i < numQueries; i++ {
+		q := query.NewHTTP()
+		q.HumanLabel = []byte("test query")
+		if err := enc.Encode(q); err != nil {
+			t.Fatalf("could not encode query: %v", err)
+		}
+	}
+	f.Close()
+
+	r, err := NewBenchmarkRunner(BenchmarkRunnerConfig{
+		Load: load.BenchmarkRunnerConfig{
+			BatchSize: 10,
+			Workers:   2,
+			DoLoad:    true,
+		},
+		Query: query.BenchmarkRunnerConfig{
+			Workers:  2,
+			FileName: f.Name(),
+		},
+		WriteRate:       10000,
+		PressureBuckets: "10,100",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	b := &testBenchmark{ds: &testDataSource{left: numPoints}}
+	var queries uint64
+	r.Run(b, &query.HTTPPool, func() query.Processor {
+		return &testQueryProcessor{count: &queries}
+	})
+
+	if b.batches != 10 {
+		t.Errorf("wrong number of batches processed: got %d want %d", b.batches, 10)
+	}
+	if b.closed != 2 {
+		t.Errorf("wrapped processors not closed: got %d want %d", b.closed, 2)
+	}
+	if queries != numQueries {
+		t.Errorf("wrong number of queries processed: got %d want %d", queries, numQueries)
+	}
+}
+
+func TestNewBenchmarkRunnerBadBuckets(t *testing.T) {
+	_, err := NewBenchmarkRunner(BenchmarkRunnerConfig{PressureBuckets: "x"})
+	if err == nil {
+		t.Errorf("expected error for bad pressure buckets")
+	}
+}
@@ -0,0 +1,50 @@
+package query
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/HdrHistogram/hdrhistogram-go"
+	"github.com/timescale/tsbs/internal/coordinator"
+)
+
+// joinCoordinator registers the runner as an agent with the coordinator, and
+// makes the scanner only send the queries of the shard of the agent
+func (b *BenchmarkRunner) joinCoordinator() {
+	a, err := coordinator.Dial(b.Coordinator, coordinator.KindQuery)
+	if err != nil {
+		log.Fatal(err)
+	}
+	b.agent = a
+	b.scanner.inShard = a.InShard
+	fmt.Printf("running shard %d of %d\n", a.Agent, a.Agents)
+}
+
+// sendResult sends the latencies and failed queries by label to the coordinator
+func (b *BenchmarkRunner) sendResult(start, end time.Time) {
+	histograms, failed := b.sp.agentResult()
+	r := &coordinator.Result{
+		Start:      start,
+		End:        end,
+		Counts:     failed,
+		Histograms: histograms,
+	}
+	if err := b.agent.Done(r); err != nil {
+		log.Fatalf("could not send the result to the coordinator: %v", err)
+	}
+}
+
+// agentResult returns the latencies and the failed queries by label, with
+// the labels of the results file
+func (sp *defaultStatProcessor) agentResult() (map[string]*hdrhistogram.Snapshot, map[string]uint64) {
+	histograms := make(map[string]*hdrhistogram.Snapshot, len(sp.statMapping))
+	for label, statGroup := range sp.statMapping {
+		histograms[stripRegex(label)] = statGroup.latencyHDRHistogram.Export()
+	}
+	failed := make(map[string]uint64, len(sp.failedMapping))
+	for label, count := range sp.failedMapping {
+		failed[stripRegex(label)] = count
+	}
+	return histograms, failed
+}
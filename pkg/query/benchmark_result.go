@@ -11,10 +11,13 @@ type LoaderTestResult struct {
 	RunnerConfig BenchmarkRunnerConfig `json:"RunnerConfig"`
 
 	// Run info
-	StartTime      int64 `json:"StartTime`
+	StartTime      int64 `json:"StartTime"`
 	EndTime        int64 `json:"EndTime"`
 	DurationMillis int64 `json:"DurationMillis"`
 
 	// Totals
 	Totals map[string]interface{} `json:"Totals"`
+
+	// Verification counts per query label, if results were verified
+	Verification map[string]interface{} `json:"Verification,omitempty"`
 }
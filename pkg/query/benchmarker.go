@@ -7,11 +7,14 @@ import (
 	"io/ioutil"
 	"log"
 	"os"
+	"path/filepath"
 	"runtime/pprof"
 	"sync"
 	"time"
 
 	"github.com/spf13/pflag"
+	"github.com/timescale/tsbs/internal/compression"
+	"github.com/timescale/tsbs/internal/coordinator"
 	"golang.org/x/time/rate"
 )
 
@@ -28,6 +31,7 @@ type BenchmarkRunnerConfig struct {
 	DBName           string `mapstructure:"db-name"`
 	Limit            uint64 `mapstructure:"max-queries"`
 	LimitRPS         uint64 `mapstructure:"max-rps"`
+	OpenLoop         bool   `mapstructure:"open-loop"`
 	MemProfile       string `mapstructure:"memprofile"`
 	HDRLatenciesFile string `mapstructure:"hdr-latencies"`
 	Workers          uint   `mapstructure:"workers"`
@@ -38,23 +42,53 @@ type BenchmarkRunnerConfig struct {
 	PrintInterval    uint64 `mapstructure:"print-interval"`
 	PrewarmQueries   bool   `mapstructure:"prewarm-queries"`
 	ResultsFile      string `mapstructure:"results-file"`
+	VerifyResults    string `mapstructure:"verify-results"`
+
+	LatencyIntervalsFile string        `mapstructure:"latency-intervals-file"`
+	LatencyInterval      time.Duration `mapstructure:"latency-interval"`
+
+	QueryTimeout time.Duration `mapstructure:"query-timeout"`
+	MaxRetries   uint          `mapstructure:"max-retries"`
+	RetryBackoff time.Duration `mapstructure:"retry-backoff"`
+	MaxErrors    uint64        `mapstructure:"max-errors"`
+	MaxErrorRate float64       `mapstructure:"max-error-rate"`
+
+	MetricsListen string `mapstructure:"metrics-listen"`
+	Coordinator   string `mapstructure:"coordinator"`
 }
 
 // AddToFlagSet adds command line flags needed by the BenchmarkRunnerConfig to the flag set.
 func (c BenchmarkRunnerConfig) AddToFlagSet(fs *pflag.FlagSet) {
-	fs.String("db-name", "benchmark", "Name of database to use for queries")
-	fs.Uint64("burn-in", 0, "Number of queries to ignore before collecting statistics.")
-	fs.Uint64("max-queries", 0, "Limit the number of queries to send, 0 = no limit")
-	fs.Uint64("max-rps", 0, "Limit the rate of queries per second, 0 = no limit")
-	fs.Uint64("print-interval", 100, "Print timing stats to stderr after this many queries (0 to disable)")
-	fs.String("memprofile", "", "Write a memory profile to this file.")
-	fs.String("hdr-latencies", "", "Write the High Dynamic Range (HDR) Histogram of Response Latencies to this file.")
-	fs.Uint("workers", 1, "Number of concurrent requests to make.")
-	fs.Bool("prewarm-queries", false, "Run each query twice in a row so the warm query is guaranteed to be a cache hit")
-	fs.Bool("print-responses", false, "Pretty print response bodies for correctness checking (default false).")
-	fs.Int("debug", 0, "Whether to print debug messages.")
-	fs.String("file", "", "File name to read queries from")
-	fs.String("results-file", "", "Write the test results summary json to this file")
+	c.AddToFlagSetWithPrefix(fs, "")
+}
+
+// AddToFlagSetWithPrefix adds the command line flags needed by the
+// BenchmarkRunnerConfig to the flag set, with the given prefix
+func (c BenchmarkRunnerConfig) AddToFlagSetWithPrefix(fs *pflag.FlagSet, flagPrefix string) {
+	fs.String(flagPrefix+"db-name", "benchmark", "Name of database to use for queries")
+	fs.Uint64(flagPrefix+"burn-in", 0, "Number of queries to ignore before collecting statistics.")
+	fs.Uint64(flagPrefix+"max-queries", 0, "Limit the number of queries to send, 0 = no limit")
+	fs.Uint64(flagPrefix+"max-rps", 0, "Limit the rate of queries per second, 0 = no limit")
+	fs.Bool(flagPrefix+"open-loop", false, "Send queries at fixed intended start times at the rate of max-rps, and measure latencies from the intended start so that waiting for a busy worker or database is included")
+	fs.Uint64(flagPrefix+"print-interval", 100, "Print timing stats to stderr after this many queries (0 to disable)")
+	fs.String(flagPrefix+"memprofile", "", "Write a memory profile to this file.")
+	fs.String(flagPrefix+"hdr-latencies", "", "Write the High Dynamic Range (HDR) Histogram of Response Latencies to this file.")
+	fs.Uint(flagPrefix+"workers", 1, "Number of concurrent requests to make.")
+	fs.Bool(flagPrefix+"prewarm-queries", false, "Run each query twice in a row so the warm query is guaranteed to be a cache hit")
+	fs.Bool(flagPrefix+"print-responses", false, "Pretty print response bodies for correctness checking (default false).")
+	fs.Int(flagPrefix+"debug", 0, "Whether to print debug messages.")
+	fs.String(flagPrefix+"file", "", "File name to read queries from")
+	fs.String(flagPrefix+"results-file", "", "Write the test results summary json to this file")
+	fs.String(flagPrefix+"verify-results", "", "Verify the query results against the reference results in this file (see tsbs_generate_queries --reference-file)")
+	fs.String(flagPrefix+"latency-intervals-file", "", "Write the latency quantiles per query type and interval to this file, as JSON lines if its name ends in .json and as CSV otherwise")
+	fs.Duration(flagPrefix+"latency-interval", 10*time.Second, "Length of the intervals written to the latency intervals file")
+	fs.Duration(flagPrefix+"query-timeout", 0, "Fail a query attempt that takes longer than this, 0 = no timeout")
+	fs.Uint(flagPrefix+"max-retries", 0, "Number of times to retry a failed query")
+	fs.Duration(flagPrefix+"retry-backoff", 100*time.Millisecond, "Wait before the first retry of a failed query, doubled for every following retry")
+	fs.Uint64(flagPrefix+"max-errors", 0, "Number of failed queries to tolerate before aborting the run")
+	fs.Float64(flagPrefix+"max-error-rate", 0, "Fraction of failed queries to tolerate after max-errors queries failed, 0 = none")
+	fs.String(flagPrefix+"metrics-listen", "", "Serve the progress of the run as OpenMetrics under /metrics on this address, e.g. ':9090'")
+	fs.String(flagPrefix+"coordinator", "", "Run a shard of the queries as an agent of the tsbs_coordinator at this address")
 }
 
 // BenchmarkRunner contains the common components for running a query benchmarking
@@ -65,6 +99,13 @@ type BenchmarkRunner struct {
 	sp      statProcessor
 	scanner *scanner
 	ch      chan Query
+	// verifier is nil unless query results are verified
+	verifier *verifier
+	// schedule is nil unless queries are sent open-loop
+	schedule *openLoopSchedule
+	errors   errorBudget
+	// agent is nil unless the runner is an agent of a coordinator
+	agent *coordinator.Agent
 }
 
 // NewBenchmarkRunner creates a new instance of BenchmarkRunner which is
@@ -72,15 +113,20 @@ type BenchmarkRunner struct {
 func NewBenchmarkRunner(config BenchmarkRunnerConfig) *BenchmarkRunner {
 	runner := &BenchmarkRunner{BenchmarkRunnerConfig: config}
 	runner.scanner = newScanner(&runner.Limit)
+	runner.errors.maxErrors = config.MaxErrors
+	runner.errors.maxErrorRate = config.MaxErrorRate
 	spArgs := &statProcessorArgs{
 		limit:            &runner.Limit,
 		printInterval:    runner.PrintInterval,
 		prewarmQueries:   runner.PrewarmQueries,
 		burnIn:           runner.BurnIn,
 		hdrLatenciesFile: runner.HDRLatenciesFile,
+		openLoop:         runner.OpenLoop,
+		intervalsFile:    runner.LatencyIntervalsFile,
+		interval:         runner.LatencyInterval,
 	}
 
-	runner.sp = newStatProcessor(spArgs)
+	runner.sp = newStatProcessor(spArgs, runner.Workers)
 	return runner
 }
 
@@ -116,19 +162,49 @@ type Processor interface {
 	ProcessQuery(q Query, isWarm bool) ([]*Stat, error)
 }
 
-// GetBufferedReader returns the buffered Reader that should be used by the loader
+// ResultProcessor is a Processor that can also hand back the normalized rows
+// of a query result, which is needed to verify the results of a run
+type ResultProcessor interface {
+	Processor
+
+	// ProcessQueryResult handles a given query like ProcessQuery, and also returns its normalized result
+	ProcessQueryResult(q Query, isWarm bool) ([]*Stat, *Result, error)
+}
+
+// TimeoutProcessor is a Processor that can cancel queries that take longer
+// than the query timeout. Other Processors are waited for and their queries
+// failed afterwards.
+type TimeoutProcessor interface {
+	Processor
+
+	// SetQueryTimeout sets the timeout after which ProcessQuery cancels a query and returns an error
+	SetQueryTimeout(timeout time.Duration)
+}
+
+// GetBufferedReader returns the buffered Reader that should be used by the loader.
+// Queries compressed with gzip, zstd or lz4 are decompressed.
 func (b *BenchmarkRunner) GetBufferedReader() *bufio.Reader {
 	if b.br == nil {
+		var br *bufio.Reader
 		if len(b.FileName) > 0 {
 			// Read from specified file
 			file, err := os.Open(b.FileName)
 			if err != nil {
 				panic(fmt.Sprintf("cannot open file for read %s: %v", b.FileName, err))
 			}
-			b.br = bufio.NewReaderSize(file, defaultReadSize)
+			br = bufio.NewReaderSize(file, defaultReadSize)
 		} else {
 			// Read from STDIN
-			b.br = bufio.NewReaderSize(os.Stdin, defaultReadSize)
+			br = bufio.NewReaderSize(os.Stdin, defaultReadSize)
+		}
+		r, err := compression.NewReader(br, b.FileName)
+		if err != nil {
+			panic(fmt.Sprintf("cannot read queries from %s: %v", b.FileName, err))
+		}
+		if r == br {
+			b.br = br
+		} else {
+			b.br = bufio.NewReaderSize(r, defaultReadSize)
 		}
 	}
 	return b.br
@@ -146,18 +222,60 @@ func (b *BenchmarkRunner) Run(queryPool *sync.Pool, processorCreateFn ProcessorC
 	if spArgs.burnIn > b.Limit {
 		panic("burn-in is larger than limit")
 	}
+	if b.OpenLoop && b.LimitRPS == 0 {
+		panic("open-loop requires max-rps")
+	}
 	b.ch = make(chan Query, b.Workers)
 
+	if len(b.VerifyResults) > 0 {
+		if _, ok := processorCreateFn().(ResultProcessor); !ok {
+			log.Fatalf("%s does not support --verify-results", filepath.Base(os.Args[0]))
+		}
+		v, err := newVerifier(b.VerifyResults, b.Debug)
+		if err != nil {
+			log.Fatal(err)
+		}
+		b.verifier = v
+	}
+
+	if len(b.MetricsListen) > 0 {
+		m, err := serveQueryMetrics(b.MetricsListen)
+		if err != nil {
+			log.Fatal(err)
+		}
+		spArgs.metrics = m
+	}
+
+	if len(b.Coordinator) > 0 {
+		b.joinCoordinator()
+	}
+
+	// All agents of a coordinator start at the same time. The wait for the
+	// other agents must not count in the rates and the open-loop schedule,
+	// so it comes before the stats processor and the schedule start.
+	if b.agent != nil {
+		if err := b.agent.Wait(coordinator.BarrierStart); err != nil {
+			log.Fatalf("could not wait for the other agents: %v", err)
+		}
+	}
+
 	// Launch the stats processor:
 	go b.sp.process(b.Workers)
 
 	rateLimiter := getRateLimiter(b.LimitRPS, b.Workers)
+	if b.OpenLoop {
+		b.schedule = newOpenLoopSchedule(time.Now(), b.LimitRPS)
+	}
 
 	// Launch query processors
 	var wg sync.WaitGroup
 	for i := 0; i < int(b.Workers); i++ {
 		wg.Add(1)
-		go b.processorHandler(&wg, rateLimiter, queryPool, processorCreateFn(), i)
+		processor := processorCreateFn()
+		if tp, ok := processor.(TimeoutProcessor); ok && b.QueryTimeout > 0 {
+			tp.SetQueryTimeout(b.QueryTimeout)
+		}
+		go b.processorHandler(&wg, rateLimiter, queryPool, processor, i)
 	}
 
 	// Read in jobs, closing the job channel when done:
@@ -178,6 +296,16 @@ func (b *BenchmarkRunner) Run(queryPool *sync.Pool, processorCreateFn ProcessorC
 		log.Fatal(err)
 	}
 
+	if b.agent != nil {
+		b.sendResult(wallStart, wallEnd)
+	}
+
+	if b.verifier != nil {
+		if err := b.verifier.writeSummary(os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+	}
+
 	// (Optional) create a memory profile:
 	if len(b.MemProfile) > 0 {
 		f, err := os.Create(b.MemProfile)
@@ -203,6 +331,9 @@ func (b *BenchmarkRunner) saveTestResult(took time.Duration, start time.Time, en
 		DurationMillis:      took.Milliseconds(),
 		Totals:              b.sp.GetTotalsMap(),
 	}
+	if b.verifier != nil {
+		testResult.Verification = b.verifier.getTotalsMap()
+	}
 
 	_, _ = fmt.Printf("Saving results json file to %s\n", b.BenchmarkRunnerConfig.ResultsFile)
 	file, err := json.MarshalIndent(testResult, "", " ")
@@ -219,14 +350,26 @@ func (b *BenchmarkRunner) saveTestResult(took time.Duration, start time.Time, en
 func (b *BenchmarkRunner) processorHandler(wg *sync.WaitGroup, rateLimiter *rate.Limiter, queryPool *sync.Pool, processor Processor, workerNum int) {
 	processor.Init(workerNum)
 	for query := range b.ch {
-		r := rateLimiter.Reserve()
-		time.Sleep(r.Delay())
+		var intendedStart time.Time
+		if b.schedule != nil {
+			intendedStart = b.schedule.nextStart()
+			time.Sleep(time.Until(intendedStart))
+		} else {
+			r := rateLimiter.Reserve()
+			time.Sleep(r.Delay())
+		}
 
-		stats, err := processor.ProcessQuery(query, false)
+		stats, start, err := b.runQuery(processor, query, false)
 		if err != nil {
-			panic(err)
+			b.queryFailed(query, false, err)
+			queryPool.Put(query)
+			continue
+		}
+		if b.schedule != nil {
+			chargeWait(stats, start.Sub(intendedStart))
 		}
 		b.sp.send(stats)
+		b.querySucceeded()
 
 		// If PrewarmQueries is set, we run the query as 'cold' first (see above),
 		// then we immediately run it a second time and report that as the 'warm' stat.
@@ -234,17 +377,54 @@ func (b *BenchmarkRunner) processorHandler(wg *sync.WaitGroup, rateLimiter *rate
 		spArgs := b.sp.getArgs()
 		if spArgs.prewarmQueries {
 			// Warm run
-			stats, err = processor.ProcessQuery(query, true)
+			stats, _, err = b.runQuery(processor, query, true)
 			if err != nil {
-				panic(err)
+				b.queryFailed(query, true, err)
+				queryPool.Put(query)
+				continue
+			}
+			if b.schedule != nil {
+				// warm runs are not scheduled, they don't wait
+				chargeWait(stats, 0)
 			}
 			b.sp.sendWarm(stats)
+			b.querySucceeded()
 		}
 		queryPool.Put(query)
 	}
 	wg.Done()
 }
 
+// querySucceeded records a successful query in the error budget
+func (b *BenchmarkRunner) querySucceeded() {
+	if err := b.errors.add(false); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// queryFailed records a failed query in the stats and the error budget,
+// aborting the run if the budget is exceeded
+func (b *BenchmarkRunner) queryFailed(q Query, isWarm bool, err error) {
+	log.Printf("query %d (%s) failed: %v", q.GetID(), q.HumanLabelName(), err)
+	b.sp.send([]*Stat{getFailedStat(q.HumanLabelName(), isWarm)})
+	if budgetErr := b.errors.add(true); budgetErr != nil {
+		log.Fatalf("%v, last error: %v", budgetErr, err)
+	}
+}
+
+// processQuery runs a query, verifying the result of its cold run if needed
+func (b *BenchmarkRunner) processQuery(processor Processor, q Query, isWarm bool) ([]*Stat, error) {
+	if b.verifier == nil || isWarm {
+		return processor.ProcessQuery(q, isWarm)
+	}
+	stats, result, err := processor.(ResultProcessor).ProcessQueryResult(q, false)
+	if err != nil {
+		return nil, err
+	}
+	b.verifier.check(q, result)
+	return stats, nil
+}
+
 func getRateLimiter(limitRPS uint64, workers uint) *rate.Limiter {
 	var requestRate = rate.Inf
 	var requestBurst = 0
@@ -1,6 +1,7 @@
 package query
 
 import (
+	"github.com/HdrHistogram/hdrhistogram-go"
 	"golang.org/x/time/rate"
 	"io/ioutil"
 	"math"
@@ -80,7 +81,7 @@ func TestProcessorHandlerPreWarm(t *testing.T) {
 	var requestBurst = 0
 	var rateLimiter *rate.Limiter = rate.NewLimiter(requestRate, requestBurst)
 
-	b.sp = newStatProcessor(spArgs)
+	b.sp = newStatProcessor(spArgs, 2)
 	b.ch = make(chan Query, 2)
 	var wg sync.WaitGroup
 	qPool := &testQueryPool
@@ -327,6 +328,9 @@ func (m *mockStatProcessor) GetTotalsMap() map[string]interface{} {
 	totals := make(map[string]interface{})
 	return totals
 }
+func (m *mockStatProcessor) agentResult() (map[string]*hdrhistogram.Snapshot, map[string]uint64) {
+	return nil, nil
+}
 
 type mockProcessor struct {
 	processRes []*Stat
@@ -2,12 +2,32 @@ package config
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/spf13/pflag"
 	"github.com/timescale/tsbs/internal/utils"
 	"github.com/timescale/tsbs/pkg/data/usecases/common"
 )
 
-const ErrEmptyQueryType = "query type cannot be empty"
+const (
+	ErrEmptyQueryType     = "query type cannot be empty"
+	ErrQueryTypeAndMixSet = "query type and query mix cannot both be set"
+	ErrNoReferenceSeed    = "reference seed must be set to the seed the data was generated with"
+
+	defaultReferenceLogInterval = 10 * time.Second
+
+	errBadQueryMixEntryFmt  = "invalid query mix entry '%s': expected query-type=weight"
+	errBadQueryMixWeightFmt = "invalid weight for query type '%s' in query mix: %s"
+	errDuplicateQueryMixFmt = "query type '%s' appears more than once in query mix"
+)
+
+// QueryMixEntry is a query type together with its relative weight in a query mix.
+type QueryMixEntry struct {
+	QueryType string
+	Weight    uint64
+}
 
 // QueryGeneratorConfig is the GeneratorConfig that should be used with a
 // QueryGenerator. It includes all the fields from a BaseConfig, as well as
@@ -17,9 +37,16 @@ type QueryGeneratorConfig struct {
 	common.BaseConfig
 	Limit                uint64 `mapstructure:"queries"`
 	QueryType            string `mapstructure:"query-type"`
+	QueryMix             string `mapstructure:"query-mix"`
 	InterleavedGroupID   uint   `mapstructure:"interleaved-generation-group-id"`
 	InterleavedNumGroups uint   `mapstructure:"interleaved-generation-groups"`
 
+	// Reference results are computed in memory over the data generated with
+	// ReferenceSeed and ReferenceLogInterval
+	ReferenceFile        string        `mapstructure:"reference-file"`
+	ReferenceSeed        int64         `mapstructure:"reference-seed"`
+	ReferenceLogInterval time.Duration `mapstructure:"reference-log-interval"`
+
 	// TODO - I think this needs some rethinking, but a simple, elegant solution escapes me right now
 	TimescaleUseJSON       bool `mapstructure:"timescale-use-json"`
 	TimescaleUseTags       bool `mapstructure:"timescale-use-tags"`
@@ -38,10 +65,24 @@ func (c *QueryGeneratorConfig) Validate() error {
 		return err
 	}
 
-	if c.QueryType == "" {
+	if c.QueryType == "" && c.QueryMix == "" {
 		return fmt.Errorf(ErrEmptyQueryType)
 	}
 
+	if c.QueryType != "" && c.QueryMix != "" {
+		return fmt.Errorf(ErrQueryTypeAndMixSet)
+	}
+
+	if c.QueryMix != "" {
+		if _, err := ParseQueryMix(c.QueryMix); err != nil {
+			return err
+		}
+	}
+
+	if c.ReferenceFile != "" && c.ReferenceSeed == 0 {
+		return fmt.Errorf(ErrNoReferenceSeed)
+	}
+
 	err = utils.ValidateGroups(c.InterleavedGroupID, c.InterleavedNumGroups)
 	return err
 }
@@ -50,12 +91,17 @@ func (c *QueryGeneratorConfig) AddToFlagSet(fs *pflag.FlagSet) {
 	c.BaseConfig.AddToFlagSet(fs)
 	fs.Uint64("queries", 1000, "Number of queries to generate.")
 	fs.String("query-type", "", "Query type. (Choices are in the use case matrix.)")
+	fs.String("query-mix", "", "Weighted mix of query types to interleave instead of a single query type, e.g. 'single-groupby-1-1-1=40,lastpoint=10,high-cpu-all=5'.")
 
 	fs.Uint("interleaved-generation-group-id", 0,
 		"Group (0-indexed) to perform round-robin serialization within. Use this to scale up data generation to multiple processes.")
 	fs.Uint("interleaved-generation-groups", 1,
 		"The number of round-robin serialization groups. Use this to scale up data generation to multiple processes.")
 
+	fs.String("reference-file", "", "Write the expected results of the generated queries, computed in memory over the simulated data, to this file (devops queries only).")
+	fs.Int64("reference-seed", 0, "Seed the data was generated with, needed to compute the reference results.")
+	fs.Duration("reference-log-interval", defaultReferenceLogInterval, "Log interval the data was generated with, needed to compute the reference results.")
+
 	fs.Bool("clickhouse-use-tags", true, "ClickHouse only: Use separate tags table when querying")
 	fs.Bool("mongo-use-naive", true, "MongoDB only: Generate queries for the 'naive' data storage format for Mongo")
 	fs.Bool("timescale-use-json", false, "TimescaleDB only: Use separate JSON tags table when querying")
@@ -64,3 +110,29 @@ func (c *QueryGeneratorConfig) AddToFlagSet(fs *pflag.FlagSet) {
 
 	fs.String("db-name", "benchmark", "Specify database name. Timestream requires it in order to generate the queries")
 }
+
+// ParseQueryMix parses a comma-separated list of query-type=weight pairs, e.g.
+// "single-groupby-1-1-1=40,lastpoint=10". Weights are relative and must be
+// positive integers.
+func ParseQueryMix(mix string) ([]QueryMixEntry, error) {
+	var entries []QueryMixEntry
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(mix, ",") {
+		part = strings.TrimSpace(part)
+		kv := strings.Split(part, "=")
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+			return nil, fmt.Errorf(errBadQueryMixEntryFmt, part)
+		}
+		queryType := strings.TrimSpace(kv[0])
+		weight, err := strconv.ParseUint(strings.TrimSpace(kv[1]), 10, 64)
+		if err != nil || weight == 0 {
+			return nil, fmt.Errorf(errBadQueryMixWeightFmt, queryType, kv[1])
+		}
+		if seen[queryType] {
+			return nil, fmt.Errorf(errDuplicateQueryMixFmt, queryType)
+		}
+		seen[queryType] = true
+		entries = append(entries, QueryMixEntry{QueryType: queryType, Weight: weight})
+	}
+	return entries, nil
+}
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseQueryMix(t *testing.T) {
+	cases := []struct {
+		desc      string
+		in        string
+		want      []QueryMixEntry
+		shouldErr bool
+	}{
+		{
+			desc: "single",
+			in:   "lastpoint=10",
+			want: []QueryMixEntry{{"lastpoint", 10}},
+		},
+		{
+			desc: "multiple with spaces",
+			in:   "single-groupby-1-1-1=40, lastpoint = 10,high-cpu-all=5",
+			want: []QueryMixEntry{{"single-groupby-1-1-1", 40}, {"lastpoint", 10}, {"high-cpu-all", 5}},
+		},
+		{desc: "empty", in: "", shouldErr: true},
+		{desc: "missing weight", in: "lastpoint", shouldErr: true},
+		{desc: "missing query type", in: "=10", shouldErr: true},
+		{desc: "zero weight", in: "lastpoint=0", shouldErr: true},
+		{desc: "negative weight", in: "lastpoint=-1", shouldErr: true},
+		{desc: "not a number", in: "lastpoint=a", shouldErr: true},
+		{desc: "duplicate", in: "lastpoint=1,lastpoint=2", shouldErr: true},
+	}
+	for _, c := range cases {
+		got, err := ParseQueryMix(c.in)
+		if c.shouldErr {
+			if err == nil {
+				t.Errorf("%s: expected error, got none", c.desc)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", c.desc, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s: wrong entries: got %v want %v", c.desc, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package query
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var intervalStatsCSVHeader = []string{"start", "end", "label", "count", "p50", "p95", "p99", "max"}
+
+// intervalStat holds the latency quantiles of a label over one interval of
+// the run. Start and end are in seconds since the start of the run,
+// latencies are in milliseconds.
+type intervalStat struct {
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+	Label string  `json:"label"`
+	Count int64   `json:"count"`
+	P50   float64 `json:"p50"`
+	P95   float64 `json:"p95"`
+	P99   float64 `json:"p99"`
+	Max   float64 `json:"max"`
+}
+
+// intervalStats collects latency histograms per label over fixed intervals
+// of the run, and writes their quantiles as a time series once an interval
+// is over, so that latency changes during a run become visible.
+type intervalStats struct {
+	interval time.Duration
+	start    time.Time
+	current  int64 // index of the interval being collected
+	groups   map[string]*statGroup
+
+	file io.Closer
+	w    *bufio.Writer
+	csv  *csv.Writer // nil if written as JSON lines
+}
+
+// newIntervalStats creates the intervalStats file fileName, written as JSON
+// lines if its name ends in .json and as CSV otherwise.
+func newIntervalStats(fileName string, interval time.Duration, start time.Time) (*intervalStats, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("latency interval must be positive, got %v", interval)
+	}
+	f, err := os.Create(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create latency intervals file %s: %v", fileName, err)
+	}
+	s := newIntervalStatsWriter(f, interval, start, strings.HasSuffix(fileName, ".json"))
+	s.file = f
+	if s.csv != nil {
+		if err := s.csv.Write(intervalStatsCSVHeader); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+func newIntervalStatsWriter(w io.Writer, interval time.Duration, start time.Time, asJSON bool) *intervalStats {
+	s := &intervalStats{
+		interval: interval,
+		start:    start,
+		groups:   make(map[string]*statGroup),
+		w:        bufio.NewWriter(w),
+	}
+	if !asJSON {
+		s.csv = csv.NewWriter(s.w)
+	}
+	return s
+}
+
+// push records the latency value of label, received at now. Intervals that
+// are over are written first.
+func (s *intervalStats) push(now time.Time, label string, value float64) error {
+	idx := int64(now.Sub(s.start) / s.interval)
+	if idx > s.current {
+		if err := s.flush(); err != nil {
+			return err
+		}
+		s.current = idx
+	}
+
+	g, ok := s.groups[label]
+	if !ok {
+		g = newStatGroup(0)
+		s.groups[label] = g
+	}
+	g.push(value)
+	return nil
+}
+
+// flush writes the quantiles of the current interval and resets its histograms
+func (s *intervalStats) flush() error {
+	labels := make([]string, 0, len(s.groups))
+	for label, g := range s.groups {
+		if g.count > 0 {
+			labels = append(labels, label)
+		}
+	}
+	sort.Strings(labels)
+
+	start := time.Duration(s.current) * s.interval
+	for _, label := range labels {
+		g := s.groups[label]
+		stat := intervalStat{
+			Start: start.Seconds(),
+			End:   (start + s.interval).Seconds(),
+			Label: label,
+			Count: g.count,
+			P50:   g.quantile(50.0),
+			P95:   g.quantile(95.0),
+			P99:   g.quantile(99.0),
+			Max:   g.Max(),
+		}
+		if err := s.write(&stat); err != nil {
+			return err
+		}
+		g.reset()
+	}
+	if s.csv != nil {
+		s.csv.Flush()
+		if err := s.csv.Error(); err != nil {
+			return err
+		}
+	}
+	return s.w.Flush()
+}
+
+func (s *intervalStats) write(stat *intervalStat) error {
+	if s.csv == nil {
+		b, err := json.Marshal(stat)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(s.w, "%s\n", b)
+		return err
+	}
+	return s.csv.Write([]string{
+		formatIntervalValue(stat.Start),
+		formatIntervalValue(stat.End),
+		stat.Label,
+		strconv.FormatInt(stat.Count, 10),
+		formatIntervalValue(stat.P50),
+		formatIntervalValue(stat.P95),
+		formatIntervalValue(stat.P99),
+		formatIntervalValue(stat.Max),
+	})
+}
+
+func formatIntervalValue(v float64) string {
+	return strconv.FormatFloat(v, 'f', 3, 64)
+}
+
+// close writes the last interval and closes the file
+func (s *intervalStats) close() error {
+	if err := s.flush(); err != nil {
+		return err
+	}
+	if s.file != nil {
+		return s.file.Close()
+	}
+	return nil
+}
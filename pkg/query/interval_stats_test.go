@@ -0,0 +1,81 @@
+package query
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIntervalStatsCSV(t *testing.T) {
+	var b bytes.Buffer
+	start := time.Unix(0, 0)
+	s := newIntervalStatsWriter(&b, time.Second, start, false)
+
+	pushes := []struct {
+		offset time.Duration
+		label  string
+		value  float64
+	}{
+		{100 * time.Millisecond, "b, with comma", 2},
+		{200 * time.Millisecond, "a", 1},
+		{300 * time.Millisecond, "a", 3},
+		// skips the interval [1s, 2s), which is not written
+		{2500 * time.Millisecond, "a", 5},
+	}
+	for _, p := range pushes {
+		if err := s.push(start.Add(p.offset), p.label, p.value); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if err := s.close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{
+		"0.000,1.000,a,2,1.000,3.000,3.000,3.000",
+		`0.000,1.000,"b, with comma",1,2.000,2.000,2.000,2.000`,
+		"2.000,3.000,a,1,5.000,5.000,5.000,5.000",
+	}
+	got := strings.Split(strings.TrimSpace(b.String()), "\n")
+	if len(got) != len(want) {
+		t.Fatalf("incorrect number of lines: got %d want %d\n%s", len(got), len(want), b.String())
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("incorrect line %d: got\n%s\nwant\n%s", i, got[i], want[i])
+		}
+	}
+}
+
+func TestIntervalStatsJSON(t *testing.T) {
+	var b bytes.Buffer
+	start := time.Unix(0, 0)
+	s := newIntervalStatsWriter(&b, 10*time.Second, start, true)
+	for i := 1; i <= 100; i++ {
+		if err := s.push(start.Add(15*time.Second), "q", float64(i)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if err := s.close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var stat intervalStat
+	if err := json.Unmarshal(b.Bytes(), &stat); err != nil {
+		t.Fatalf("cannot decode %q: %v", b.String(), err)
+	}
+	if stat.Start != 10 || stat.End != 20 || stat.Label != "q" || stat.Count != 100 {
+		t.Errorf("incorrect interval: got %+v", stat)
+	}
+	// the histogram keeps 4 significant digits
+	for _, c := range []struct{ got, want float64 }{
+		{stat.P50, 50}, {stat.P95, 95}, {stat.P99, 99}, {stat.Max, 100},
+	} {
+		if math.Abs(c.got-c.want) > 0.01 {
+			t.Errorf("incorrect quantile: got %f want %f", c.got, c.want)
+		}
+	}
+}
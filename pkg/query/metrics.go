@@ -0,0 +1,37 @@
+package query
+
+import (
+	"github.com/timescale/tsbs/internal/metrics"
+)
+
+// queryMetrics are the OpenMetrics served about the progress of a run
+type queryMetrics struct {
+	latencies *metrics.HistogramVec
+	failed    *metrics.CounterVec
+}
+
+func newQueryMetrics(r *metrics.Registry) *queryMetrics {
+	return &queryMetrics{
+		latencies: r.NewHistogramVec("tsbs_query_duration_seconds", "Latency of the successful queries by query type.", "query", metrics.DefaultBuckets),
+		failed:    r.NewCounterVec("tsbs_query_failed", "Number of failed queries by query type.", "query"),
+	}
+}
+
+// serveQueryMetrics serves the progress of the run on addr as OpenMetrics
+func serveQueryMetrics(addr string) (*queryMetrics, error) {
+	r := metrics.NewRegistry()
+	m := newQueryMetrics(r)
+	if err := metrics.Serve(addr, r); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// push records a Stat under its own label
+func (m *queryMetrics) push(stat *Stat) {
+	if stat.isFailed {
+		m.failed.Inc(string(stat.label))
+		return
+	}
+	m.latencies.Observe(string(stat.label), stat.value/1e3)
+}
@@ -0,0 +1,37 @@
+package query
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/timescale/tsbs/internal/metrics"
+)
+
+func TestQueryMetricsPush(t *testing.T) {
+	r := metrics.NewRegistry()
+	m := newQueryMetrics(r)
+	m.push(GetStat().Init([]byte("q"), 500))
+	m.push(GetStat().Init([]byte("q"), 2000))
+	m.push(getFailedStat([]byte("q"), false))
+
+	var b strings.Builder
+	if err := r.Write(&b); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"tsbs_query_duration_seconds_bucket{query=\"q\",le=\"0.5\"} 1\n",
+		"tsbs_query_duration_seconds_sum{query=\"q\"} 2.5\n",
+		"tsbs_query_duration_seconds_count{query=\"q\"} 2\n",
+		"tsbs_query_failed_total{query=\"q\"} 1\n",
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("metrics do not contain %q:\n%s", want, b.String())
+		}
+	}
+}
+
+func TestServeQueryMetrics(t *testing.T) {
+	if _, err := serveQueryMetrics("256.0.0.1:0"); err == nil {
+		t.Errorf("expected an error for an invalid address")
+	}
+}
@@ -0,0 +1,41 @@
+package query
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// openLoopSchedule assigns fixed intended start times to queries, evenly
+// spaced at a given rate from the start of the run. Unlike a rate limiter it
+// does not wait for the previous queries to finish, so a stalled database
+// delays the start of queries past their intended start instead of
+// lowering the rate at which they are intended to be sent.
+type openLoopSchedule struct {
+	start time.Time
+	rps   float64
+	next  uint64 // number of queries scheduled so far
+}
+
+func newOpenLoopSchedule(start time.Time, rps uint64) *openLoopSchedule {
+	return &openLoopSchedule{start: start, rps: float64(rps)}
+}
+
+// nextStart returns the intended start of the next query
+func (s *openLoopSchedule) nextStart() time.Time {
+	n := atomic.AddUint64(&s.next, 1) - 1
+	return s.start.Add(time.Duration(float64(n) / s.rps * float64(time.Second)))
+}
+
+// chargeWait adds the time a query waited after its intended start to the
+// values of its stats, keeping the time spent running it as their service
+// value.
+func chargeWait(stats []*Stat, wait time.Duration) {
+	if wait < 0 {
+		wait = 0
+	}
+	waitMillis := float64(wait.Nanoseconds()) / 1e6
+	for _, s := range stats {
+		s.serviceValue = s.value
+		s.value += waitMillis
+	}
+}
@@ -0,0 +1,108 @@
+package query
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestOpenLoopScheduleNextStart(t *testing.T) {
+	start := time.Unix(0, 0)
+	s := newOpenLoopSchedule(start, 4)
+	for i := 0; i < 6; i++ {
+		want := start.Add(time.Duration(i) * 250 * time.Millisecond)
+		if got := s.nextStart(); !got.Equal(want) {
+			t.Errorf("incorrect start of query %d: got %v want %v", i, got, want)
+		}
+	}
+}
+
+func TestOpenLoopScheduleConcurrent(t *testing.T) {
+	const workers, perWorker = 4, 100
+	s := newOpenLoopSchedule(time.Unix(0, 0), 1000)
+	var mu sync.Mutex
+	seen := make(map[int64]bool)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < perWorker; j++ {
+				start := s.nextStart().UnixNano()
+				mu.Lock()
+				seen[start] = true
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if len(seen) != workers*perWorker {
+		t.Errorf("intended starts are not unique: got %d want %d", len(seen), workers*perWorker)
+	}
+}
+
+func TestChargeWait(t *testing.T) {
+	stats := []*Stat{
+		GetStat().Init([]byte("a"), 10),
+		GetPartialStat().Init([]byte("b"), 2),
+	}
+	chargeWait(stats, 5*time.Millisecond)
+	for i, want := range []struct{ value, service float64 }{{15, 10}, {7, 2}} {
+		if stats[i].value != want.value || stats[i].serviceValue != want.service {
+			t.Errorf("incorrect stat %d: got value %f service %f want %f and %f",
+				i, stats[i].value, stats[i].serviceValue, want.value, want.service)
+		}
+	}
+
+	stat := GetStat().Init([]byte("a"), 10)
+	chargeWait([]*Stat{stat}, -time.Millisecond)
+	if stat.value != 10 || stat.serviceValue != 10 {
+		t.Errorf("negative wait was charged: got value %f service %f", stat.value, stat.serviceValue)
+	}
+}
+
+func TestProcessorHandlerOpenLoop(t *testing.T) {
+	const qLimit = 10
+	var stats []*Stat
+	b := &BenchmarkRunner{}
+	b.sp = &mockStatProcessor{
+		args:   &statProcessorArgs{openLoop: true},
+		onSend: func(s []*Stat) { stats = append(stats, s...) },
+	}
+	b.ch = make(chan Query, qLimit)
+	// all queries are due at once, so most of them wait for the single worker
+	b.schedule = newOpenLoopSchedule(time.Now(), 1e9)
+
+	var wg sync.WaitGroup
+	qPool := &testQueryPool
+	wg.Add(1)
+	for i := 0; i < qLimit; i++ {
+		b.ch <- qPool.Get().(*testQuery)
+	}
+	close(b.ch)
+	b.processorHandler(&wg, getRateLimiter(0, 1), qPool, &sleepingProcessor{sleep: 2 * time.Millisecond}, 0)
+
+	if len(stats) != qLimit {
+		t.Fatalf("incorrect number of stats: got %d want %d", len(stats), qLimit)
+	}
+	for i, s := range stats {
+		if s.serviceValue != 2 {
+			t.Errorf("incorrect service value of query %d: got %f want 2", i, s.serviceValue)
+		}
+	}
+	// the last query waits for the 9 queries before it
+	if last := stats[qLimit-1].value; last < 2+9*2 {
+		t.Errorf("response time does not include the wait: got %f", last)
+	}
+}
+
+type sleepingProcessor struct {
+	sleep time.Duration
+}
+
+func (p *sleepingProcessor) Init(_ int) {}
+
+func (p *sleepingProcessor) ProcessQuery(q Query, _ bool) ([]*Stat, error) {
+	time.Sleep(p.sleep)
+	return []*Stat{GetStat().Init(q.HumanLabelName(), 2)}, nil
+}
@@ -0,0 +1,222 @@
+package query
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	resultGroupColumn = "hostname"
+
+	// relative tolerance when comparing result values, to allow for the
+	// different ways databases compute aggregates such as averages
+	resultValueTolerance = 1e-6
+)
+
+// ResultRow is a row of a query result normalized so that it can be compared
+// across databases.
+type ResultRow struct {
+	// Time is the time of the row (or of the start of its time bucket) in
+	// nanoseconds since the epoch
+	Time int64
+	// Group is the hostname the row belongs to, empty if the row is not
+	// grouped by host or the database does not return it
+	Group string
+	// Values are the metric values of the row, in the order of the query
+	Values []float64
+}
+
+// Result is the normalized result of a query.
+type Result struct {
+	Rows []ResultRow
+}
+
+// NormalizeRow converts a row returned by a SQL-like database into a
+// ResultRow. The first time value becomes the row time, a 'hostname' column
+// becomes the group and all other numeric values, except for id columns
+// (named 'id' or ending in '_id'), become the row values. Everything else is
+// ignored.
+func NormalizeRow(columns []string, values []interface{}) ResultRow {
+	row := ResultRow{}
+	hasTime := false
+	for i, v := range values {
+		column := strings.ToLower(columns[i])
+		switch x := v.(type) {
+		case time.Time:
+			if !hasTime {
+				row.Time = x.UnixNano()
+				hasTime = true
+			}
+		case string:
+			if column == resultGroupColumn {
+				row.Group = x
+			}
+		case []byte:
+			if column == resultGroupColumn {
+				row.Group = string(x)
+			}
+		default:
+			if column == "id" || strings.HasSuffix(column, "_id") {
+				continue
+			}
+			if f, ok := toFloat64(v); ok {
+				row.Values = append(row.Values, f)
+			}
+		}
+	}
+	return row
+}
+
+// ReadSQLResult reads all the rows returned by a SQL database into a
+// normalized Result.
+func ReadSQLResult(rows *sql.Rows) (*Result, error) {
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{}
+	for rows.Next() {
+		values := make([]interface{}, len(cols))
+		for i := range values {
+			values[i] = new(interface{})
+		}
+		if err := rows.Scan(values...); err != nil {
+			return nil, fmt.Errorf("error while reading values: %v", err)
+		}
+		for i := range values {
+			values[i] = *values[i].(*interface{})
+			// NUMERIC values, e.g. the avg of integers, are returned as text
+			if types[i].DatabaseTypeName() == "NUMERIC" {
+				values[i] = parseNumeric(values[i])
+			}
+		}
+		result.Rows = append(result.Rows, NormalizeRow(cols, values))
+	}
+	return result, rows.Err()
+}
+
+func parseNumeric(v interface{}) interface{} {
+	var s string
+	switch x := v.(type) {
+	case string:
+		s = x
+	case []byte:
+		s = string(x)
+	default:
+		return v
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return v
+	}
+	return f
+}
+
+func toFloat64(v interface{}) (float64, bool) {
+	switch x := v.(type) {
+	case float64:
+		return x, true
+	case float32:
+		return float64(x), true
+	case int:
+		return float64(x), true
+	case int8:
+		return float64(x), true
+	case int16:
+		return float64(x), true
+	case int32:
+		return float64(x), true
+	case int64:
+		return float64(x), true
+	case uint:
+		return float64(x), true
+	case uint8:
+		return float64(x), true
+	case uint16:
+		return float64(x), true
+	case uint32:
+		return float64(x), true
+	case uint64:
+		return float64(x), true
+	}
+	return 0, false
+}
+
+// Sort orders the rows of the Result by time, group and values, so that two
+// results can be compared regardless of the order the database returned the
+// rows in.
+func (r *Result) Sort() {
+	sort.Slice(r.Rows, func(i, j int) bool {
+		a, b := r.Rows[i], r.Rows[j]
+		if a.Time != b.Time {
+			return a.Time < b.Time
+		}
+		if a.Group != b.Group {
+			return a.Group < b.Group
+		}
+		for k := 0; k < len(a.Values) && k < len(b.Values); k++ {
+			if a.Values[k] != b.Values[k] {
+				return a.Values[k] < b.Values[k]
+			}
+		}
+		return len(a.Values) < len(b.Values)
+	})
+}
+
+// Matches reports whether the actual Result r has the same rows as the
+// expected Result. If none of the actual rows has a group (e.g. the database
+// returns tag ids instead of hostnames), groups are ignored in the comparison.
+func (r *Result) Matches(expected *Result) bool {
+	if len(r.Rows) != len(expected.Rows) {
+		return false
+	}
+
+	ignoreGroups := true
+	for _, row := range r.Rows {
+		if row.Group != "" {
+			ignoreGroups = false
+			break
+		}
+	}
+
+	actual := &Result{Rows: append([]ResultRow(nil), r.Rows...)}
+	want := &Result{Rows: append([]ResultRow(nil), expected.Rows...)}
+	if ignoreGroups {
+		for i := range want.Rows {
+			want.Rows[i].Group = ""
+		}
+	}
+	actual.Sort()
+	want.Sort()
+
+	for i := range actual.Rows {
+		if !actual.Rows[i].matches(&want.Rows[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (row *ResultRow) matches(expected *ResultRow) bool {
+	if row.Time != expected.Time || row.Group != expected.Group || len(row.Values) != len(expected.Values) {
+		return false
+	}
+	for i, v := range row.Values {
+		e := expected.Values[i]
+		scale := math.Max(1, math.Max(math.Abs(v), math.Abs(e)))
+		if math.Abs(v-e) > resultValueTolerance*scale {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,80 @@
+package query
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNormalizeRow(t *testing.T) {
+	ts := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	columns := []string{"id", "hostname", "region", "time", "tags_id", "usage_user", "mean_usage_system", "later", "additional_tags"}
+	values := []interface{}{int64(3), []byte("host_3"), "eu-west-1", ts, int64(3), int64(58), 12.5, ts.Add(time.Hour), nil}
+
+	got := NormalizeRow(columns, values)
+	want := ResultRow{Time: ts.UnixNano(), Group: "host_3", Values: []float64{58, 12.5}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("incorrect row: got %v want %v", got, want)
+	}
+}
+
+func TestResultMatches(t *testing.T) {
+	expected := &Result{Rows: []ResultRow{
+		{Time: 1, Group: "host_1", Values: []float64{10, 20}},
+		{Time: 1, Group: "host_0", Values: []float64{30, 40}},
+		{Time: 2, Group: "host_0", Values: []float64{1.0 / 3}},
+	}}
+	cases := []struct {
+		desc   string
+		actual []ResultRow
+		want   bool
+	}{
+		{
+			desc: "same rows in other order",
+			actual: []ResultRow{
+				{Time: 2, Group: "host_0", Values: []float64{0.3333333333}},
+				{Time: 1, Group: "host_0", Values: []float64{30, 40}},
+				{Time: 1, Group: "host_1", Values: []float64{10, 20}},
+			},
+			want: true,
+		},
+		{
+			desc: "no groups",
+			actual: []ResultRow{
+				{Time: 1, Values: []float64{30, 40}},
+				{Time: 1, Values: []float64{10, 20}},
+				{Time: 2, Values: []float64{1.0 / 3}},
+			},
+			want: true,
+		},
+		{
+			desc: "missing row",
+			actual: []ResultRow{
+				{Time: 1, Group: "host_1", Values: []float64{10, 20}},
+				{Time: 1, Group: "host_0", Values: []float64{30, 40}},
+			},
+		},
+		{
+			desc: "wrong value",
+			actual: []ResultRow{
+				{Time: 1, Group: "host_1", Values: []float64{10, 20}},
+				{Time: 1, Group: "host_0", Values: []float64{30, 41}},
+				{Time: 2, Group: "host_0", Values: []float64{1.0 / 3}},
+			},
+		},
+		{
+			desc: "wrong group",
+			actual: []ResultRow{
+				{Time: 1, Group: "host_1", Values: []float64{10, 20}},
+				{Time: 1, Group: "host_2", Values: []float64{30, 40}},
+				{Time: 2, Group: "host_0", Values: []float64{1.0 / 3}},
+			},
+		},
+	}
+	for _, c := range cases {
+		r := &Result{Rows: c.actual}
+		if got := r.Matches(expected); got != c.want {
+			t.Errorf("%s: got %v want %v", c.desc, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package query
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// maxRetryBackoff caps the wait between two attempts of a query
+const maxRetryBackoff = 30 * time.Second
+
+// errorBudget decides when too many queries failed to continue the run. The
+// first maxErrors failed queries are always tolerated. Beyond that, failed
+// queries are tolerated as long as they are at most maxErrorRate of all
+// finished queries, if maxErrorRate is set.
+type errorBudget struct {
+	maxErrors    uint64
+	maxErrorRate float64
+
+	mu       sync.Mutex
+	finished uint64
+	failed   uint64
+}
+
+// add records a finished query and returns an error if the budget is
+// exceeded by it.
+func (e *errorBudget) add(failed bool) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.finished++
+	if !failed {
+		return nil
+	}
+	e.failed++
+	if e.failed <= e.maxErrors {
+		return nil
+	}
+	rate := float64(e.failed) / float64(e.finished)
+	if e.maxErrorRate > 0 && rate <= e.maxErrorRate {
+		return nil
+	}
+	return fmt.Errorf("error budget exceeded: %d of %d queries failed", e.failed, e.finished)
+}
+
+// runQuery runs a query until it succeeds or all its attempts failed, waiting
+// with an exponential backoff between the attempts. It returns the start of
+// the successful attempt.
+func (b *BenchmarkRunner) runQuery(processor Processor, q Query, isWarm bool) ([]*Stat, time.Time, error) {
+	backoff := b.RetryBackoff
+	for attempt := uint(0); ; attempt++ {
+		start := time.Now()
+		stats, err := b.attemptQuery(processor, q, isWarm)
+		if err == nil {
+			return stats, start, nil
+		}
+		if attempt == b.MaxRetries {
+			return nil, start, err
+		}
+		if b.Debug > 0 {
+			log.Printf("query %d failed, retrying in %v: %v", q.GetID(), backoff, err)
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+		if backoff > maxRetryBackoff {
+			backoff = maxRetryBackoff
+		}
+	}
+}
+
+// attemptQuery runs a query once. Processors that don't apply the query
+// timeout themselves can't be interrupted, so the query is waited for and
+// failed afterwards if it took longer than the timeout.
+func (b *BenchmarkRunner) attemptQuery(processor Processor, q Query, isWarm bool) ([]*Stat, error) {
+	if _, ok := processor.(TimeoutProcessor); ok || b.QueryTimeout <= 0 {
+		return b.processQuery(processor, q, isWarm)
+	}
+	start := time.Now()
+	stats, err := b.processQuery(processor, q, isWarm)
+	if err == nil && time.Since(start) > b.QueryTimeout {
+		for _, s := range stats {
+			statPool.Put(s)
+		}
+		return nil, fmt.Errorf("query timed out after %v", b.QueryTimeout)
+	}
+	return stats, err
+}
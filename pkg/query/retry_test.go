@@ -0,0 +1,162 @@
+package query
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestErrorBudget(t *testing.T) {
+	testCases := []struct {
+		desc         string
+		maxErrors    uint64
+		maxErrorRate float64
+		failed       []bool
+		wantErrAt    int // index of the query exceeding the budget, -1 if none
+	}{
+		{"no budget", 0, 0, []bool{false, true}, 1},
+		{"within max errors", 2, 0, []bool{true, false, true}, -1},
+		{"over max errors", 2, 0, []bool{true, true, false, true}, 3},
+		{"within max error rate", 0, 0.5, []bool{false, true, false, true}, -1},
+		{"over max error rate", 0, 0.5, []bool{false, true, true}, 2},
+		{"max errors before max error rate", 1, 0.1, []bool{true, false, false, true}, 3},
+	}
+	for _, tc := range testCases {
+		e := &errorBudget{maxErrors: tc.maxErrors, maxErrorRate: tc.maxErrorRate}
+		gotErrAt := -1
+		for i, failed := range tc.failed {
+			if err := e.add(failed); err != nil {
+				gotErrAt = i
+				break
+			}
+		}
+		if gotErrAt != tc.wantErrAt {
+			t.Errorf("%s: budget exceeded at query %d, want %d", tc.desc, gotErrAt, tc.wantErrAt)
+		}
+	}
+}
+
+// flakyProcessor fails the first failures queries, taking sleep for each
+type flakyProcessor struct {
+	failures int
+	sleep    time.Duration
+	calls    int
+}
+
+func (p *flakyProcessor) Init(_ int) {}
+
+func (p *flakyProcessor) ProcessQuery(q Query, _ bool) ([]*Stat, error) {
+	p.calls++
+	time.Sleep(p.sleep)
+	if p.calls <= p.failures {
+		return nil, errors.New("transient error")
+	}
+	return []*Stat{GetStat().Init(q.HumanLabelName(), 1)}, nil
+}
+
+func TestRunQueryRetries(t *testing.T) {
+	b := &BenchmarkRunner{}
+	b.MaxRetries = 2
+	b.RetryBackoff = time.Millisecond
+	q := testQueryPool.Get().(*testQuery)
+
+	p := &flakyProcessor{failures: 2}
+	stats, _, err := b.runQuery(p, q, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(stats) != 1 || p.calls != 3 {
+		t.Errorf("incorrect retries: got %d stats after %d calls", len(stats), p.calls)
+	}
+
+	p = &flakyProcessor{failures: 3}
+	if _, _, err = b.runQuery(p, q, false); err == nil {
+		t.Errorf("expected an error after all retries failed")
+	}
+	if p.calls != 3 {
+		t.Errorf("incorrect number of calls: got %d want 3", p.calls)
+	}
+}
+
+func TestAttemptQueryTimeout(t *testing.T) {
+	b := &BenchmarkRunner{}
+	b.QueryTimeout = time.Millisecond
+	q := testQueryPool.Get().(*testQuery)
+
+	if _, err := b.attemptQuery(&flakyProcessor{sleep: 5 * time.Millisecond}, q, false); err == nil {
+		t.Errorf("expected a timeout error")
+	}
+	b.QueryTimeout = time.Minute
+	if _, err := b.attemptQuery(&flakyProcessor{}, q, false); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestProcessorHandlerFailedQueries(t *testing.T) {
+	const qLimit = 10
+	var failed, succeeded int
+	b := &BenchmarkRunner{}
+	b.errors.maxErrors = qLimit
+	b.sp = &mockStatProcessor{
+		args: &statProcessorArgs{},
+		onSend: func(stats []*Stat) {
+			for _, s := range stats {
+				if s.isFailed {
+					failed++
+				} else {
+					succeeded++
+				}
+			}
+		},
+	}
+	b.ch = make(chan Query, qLimit)
+
+	var wg sync.WaitGroup
+	qPool := &testQueryPool
+	wg.Add(1)
+	for i := 0; i < qLimit; i++ {
+		b.ch <- qPool.Get().(*testQuery)
+	}
+	close(b.ch)
+	b.processorHandler(&wg, getRateLimiter(0, 1), qPool, &flakyProcessor{failures: 4}, 0)
+
+	if failed != 4 || succeeded != qLimit-4 {
+		t.Errorf("incorrect stats: got %d failed and %d succeeded want 4 and %d", failed, succeeded, qLimit-4)
+	}
+	if b.errors.failed != 4 || b.errors.finished != qLimit {
+		t.Errorf("incorrect error budget: got %d of %d failed", b.errors.failed, b.errors.finished)
+	}
+}
+
+func TestStatProcessorFailedQueries(t *testing.T) {
+	limit := uint64(0)
+	sp := &defaultStatProcessor{
+		args:          &statProcessorArgs{limit: &limit, prewarmQueries: true},
+		statMapping:   map[string]*statGroup{labelAllQueries: newStatGroup(0)},
+		failedMapping: make(map[string]uint64),
+	}
+	for _, stat := range []*Stat{getFailedStat([]byte("q"), false), getFailedStat([]byte("q"), true)} {
+		for _, label := range sp.statLabels(string(stat.label), stat) {
+			sp.failedMapping[label]++
+		}
+	}
+	want := map[string]uint64{"q": 2, labelAllQueries: 2, labelColdQueries: 1, labelWarmQueries: 1}
+	if !reflect.DeepEqual(sp.failedMapping, want) {
+		t.Errorf("incorrect failed queries: got %v want %v", sp.failedMapping, want)
+	}
+	failed := sp.GetTotalsMap()["failedQueries"].(map[string]interface{})
+	if got := failed[stripRegex(labelAllQueries)]; got != uint64(2) {
+		t.Errorf("incorrect number of failed queries in totals: got %v want 2", got)
+	}
+
+	var buf bytes.Buffer
+	if err := writeFailedMap(&buf, map[string]uint64{"b": 1, "a": 2}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "Failed queries:\na: 2\nb: 1\n"; buf.String() != want {
+		t.Errorf("incorrect output: got %q want %q", buf.String(), want)
+	}
+}
@@ -12,6 +12,9 @@ import (
 type scanner struct {
 	r     io.Reader
 	limit *uint64
+	// inShard is nil unless only the queries of a shard are sent, it returns
+	// whether the n-th query read is in the shard
+	inShard func(n uint64) bool
 }
 
 // newScanner returns a new scanner for a given Reader and its limit
@@ -30,6 +33,7 @@ func (s *scanner) scan(pool *sync.Pool, c chan Query) {
 	decoder := gob.NewDecoder(s.r)
 
 	n := uint64(0)
+	read := uint64(0)
 	for {
 		if *s.limit > 0 && n >= *s.limit {
 			// request queries limit reached, time to quit
@@ -46,9 +50,16 @@ func (s *scanner) scan(pool *sync.Pool, c chan Query) {
 			// Can't read, time to quit
 			log.Fatal(err)
 		}
+		read++
+		if s.inShard != nil && !s.inShard(read-1) {
+			pool.Put(q)
+			continue
+		}
 
-		// We have a query, send it to the runner
-		q.SetID(n)
+		// We have a query, send it to the runner. Its ID is its position in
+		// the whole file, like in the reference file, even if only a shard
+		// of the queries is sent.
+		q.SetID(read - 1)
 		c <- q
 
 		// Queries counter
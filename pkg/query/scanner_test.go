@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"encoding/gob"
 	"fmt"
+	"reflect"
 	"sync"
 	"testing"
 )
@@ -150,3 +151,83 @@ func TestScanTimescaleDB(t *testing.T) {
 		return nil
 	})
 }
+
+func TestScannerShard(t *testing.T) {
+	totalQueries := uint64(7)
+	var b bytes.Buffer
+	err := encodeQueries(&b, totalQueries, func(i uint64) Query {
+		return &testQuery{
+			HumanLabel:       []byte(fmt.Sprintf("label%d", i)),
+			HumanDescription: []byte("testDesc"),
+		}
+	})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	limit := uint64(0)
+	s := newScanner(&limit)
+	// the second of three shards
+	s.inShard = func(n uint64) bool { return n%3 == 1 }
+	queryChan := make(chan Query, totalQueries)
+	s.setReader(bytes.NewReader(b.Bytes())).scan(&testQueryPool, queryChan)
+	close(queryChan)
+
+	var got []string
+	for q := range queryChan {
+		got = append(got, string(q.HumanLabelName()))
+	}
+	if want := []string{"label1", "label4"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("incorrect queries of the shard: got %v want %v", got, want)
+	}
+}
+
+func TestScannerShardReferences(t *testing.T) {
+	totalQueries := uint64(7)
+	var b bytes.Buffer
+	err := encodeQueries(&b, totalQueries, func(i uint64) Query {
+		return &testQuery{
+			HumanLabel:       []byte("label"),
+			HumanDescription: []byte(fmt.Sprintf("desc%d", i)),
+		}
+	})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	// the references are for the positions of the queries in the whole file
+	result := &Result{Rows: []ResultRow{{Time: 1, Values: []float64{10}}}}
+	var refs bytes.Buffer
+	enc := gob.NewEncoder(&refs)
+	for i := uint64(0); i < totalQueries; i++ {
+		ref := &Reference{ID: i, Label: "label", Description: fmt.Sprintf("desc%d", i), Result: result}
+		if err := enc.Encode(ref); err != nil {
+			t.Fatal(err)
+		}
+	}
+	references, err := readReferences(&refs)
+	if err != nil {
+		t.Fatalf("unexpected error reading references: %v", err)
+	}
+	v := &verifier{references: references, counts: make(map[string]*verifyCounts)}
+
+	limit := uint64(0)
+	s := newScanner(&limit)
+	// the second of three shards
+	s.inShard = func(n uint64) bool { return n%3 == 1 }
+	queryChan := make(chan Query, totalQueries)
+	s.setReader(bytes.NewReader(b.Bytes())).scan(&testQueryPool, queryChan)
+	close(queryChan)
+
+	var ids []uint64
+	for q := range queryChan {
+		ids = append(ids, q.GetID())
+		v.check(q, result)
+	}
+	if want := []uint64{1, 4}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("incorrect IDs of the queries of the shard: got %v want %v", ids, want)
+	}
+	if got, want := *v.counts["label"], (verifyCounts{Checked: 2}); got != want {
+		t.Errorf("incorrect verification counts: got %+v want %+v", got, want)
+	}
+}
@@ -22,15 +22,19 @@ type statProcessor interface {
 	process(workers uint)
 	CloseAndWait()
 	GetTotalsMap() map[string]interface{}
+	agentResult() (map[string]*hdrhistogram.Snapshot, map[string]uint64)
 }
 
 type statProcessorArgs struct {
-	prewarmQueries   bool    // PrewarmQueries tells the StatProcessor whether we're running each query twice to prewarm the cache
-	limit            *uint64 // limit is the number of statistics to analyze before stopping
-	burnIn           uint64  // burnIn is the number of statistics to ignore before analyzing
-	printInterval    uint64  // printInterval is how often print intermediate stats (number of queries)
-	hdrLatenciesFile string  // hdrLatenciesFile is the filename to Write the High Dynamic Range (HDR) Histogram of Response Latencies to
-
+	prewarmQueries   bool          // PrewarmQueries tells the StatProcessor whether we're running each query twice to prewarm the cache
+	limit            *uint64       // limit is the number of statistics to analyze before stopping
+	burnIn           uint64        // burnIn is the number of statistics to ignore before analyzing
+	printInterval    uint64        // printInterval is how often print intermediate stats (number of queries)
+	hdrLatenciesFile string        // hdrLatenciesFile is the filename to Write the High Dynamic Range (HDR) Histogram of Response Latencies to
+	openLoop         bool          // openLoop tells the StatProcessor whether the Stats also have service values
+	intervalsFile    string        // intervalsFile is the filename to write the latency quantiles per interval to
+	interval         time.Duration // interval is the length of the intervals written to intervalsFile
+	metrics          *queryMetrics // metrics is nil unless the progress is served as OpenMetrics
 }
 
 // statProcessor is used to collect, analyze, and print query execution statistics.
@@ -42,13 +46,27 @@ type defaultStatProcessor struct {
 	startTime   time.Time
 	endTime     time.Time
 	statMapping map[string]*statGroup
+	// serviceMapping holds the service values of open-loop runs, the
+	// latencies without the wait for the intended start of the queries
+	serviceMapping map[string]*statGroup
+	// failedMapping holds the number of failed queries per label
+	failedMapping map[string]uint64
+	intervals     *intervalStats // nil unless latencies are written per interval
 }
 
-func newStatProcessor(args *statProcessorArgs) statProcessor {
+// newStatProcessor creates a statProcessor for the stats of workers
+// workers. The stats channel is created here rather than in process, so the
+// workers can send on it as soon as process is started in its goroutine.
+func newStatProcessor(args *statProcessorArgs, workers uint) statProcessor {
 	if args == nil {
 		panic("Stat Processor needs args")
 	}
-	return &defaultStatProcessor{args: args}
+	sp := &defaultStatProcessor{
+		args: args,
+		c:    make(chan *Stat, workers),
+	}
+	sp.wg.Add(1)
+	return sp
 }
 
 func (sp *defaultStatProcessor) getArgs() *statProcessorArgs {
@@ -79,8 +97,6 @@ func (sp *defaultStatProcessor) sendWarm(stats []*Stat) {
 // process collects latency results, aggregating them into summary
 // statistics. Optionally, they are printed to stderr at regular intervals.
 func (sp *defaultStatProcessor) process(workers uint) {
-	sp.c = make(chan *Stat, workers)
-	sp.wg.Add(1)
 	const allQueriesLabel = labelAllQueries
 	sp.statMapping = map[string]*statGroup{
 		allQueriesLabel: newStatGroup(*sp.args.limit),
@@ -90,11 +106,22 @@ func (sp *defaultStatProcessor) process(workers uint) {
 		sp.statMapping[labelColdQueries] = newStatGroup(*sp.args.limit)
 		sp.statMapping[labelWarmQueries] = newStatGroup(*sp.args.limit)
 	}
+	if sp.args.openLoop {
+		sp.serviceMapping = make(map[string]*statGroup)
+	}
+	sp.failedMapping = make(map[string]uint64)
 
 	i := uint64(0)
 	sp.startTime = time.Now()
 	prevTime := sp.startTime
 	prevRequestCount := uint64(0)
+	if len(sp.args.intervalsFile) > 0 {
+		intervals, err := newIntervalStats(sp.args.intervalsFile, sp.args.interval, sp.startTime)
+		if err != nil {
+			log.Fatal(err)
+		}
+		sp.intervals = intervals
+	}
 
 	for stat := range sp.c {
 		atomic.AddUint64(&sp.opsCount, 1)
@@ -108,30 +135,22 @@ func (sp *defaultStatProcessor) process(workers uint) {
 				log.Fatal(err)
 			}
 		}
-		if _, ok := sp.statMapping[string(stat.label)]; !ok {
-			sp.statMapping[string(stat.label)] = newStatGroup(*sp.args.limit)
+		if sp.args.metrics != nil {
+			sp.args.metrics.push(stat)
 		}
-
-		sp.statMapping[string(stat.label)].push(stat.value)
-
-		if !stat.isPartial {
-			sp.statMapping[allQueriesLabel].push(stat.value)
-
-			// Only needed when differentiating between cold & warm
-			if sp.args.prewarmQueries {
-				if stat.isWarm {
-					sp.statMapping[labelWarmQueries].push(stat.value)
-				} else {
-					sp.statMapping[labelColdQueries].push(stat.value)
-				}
+		if stat.isFailed {
+			for _, label := range sp.statLabels(string(stat.label), stat) {
+				sp.failedMapping[label]++
 			}
+		} else {
+			sp.pushStat(stat)
+		}
 
-			// If we're prewarming queries (i.e., running them twice in a row),
-			// only increment the counter for the first (cold) query. Otherwise,
-			// increment for every query.
-			if !sp.args.prewarmQueries || !stat.isWarm {
-				i++
-			}
+		// If we're prewarming queries (i.e., running them twice in a row),
+		// only increment the counter for the first (cold) query. Otherwise,
+		// increment for every query.
+		if !stat.isPartial && (!sp.args.prewarmQueries || !stat.isWarm) {
+			i++
 		}
 
 		statPool.Put(stat)
@@ -156,6 +175,10 @@ func (sp *defaultStatProcessor) process(workers uint) {
 			if err != nil {
 				log.Fatal(err)
 			}
+			err = writeFailedMap(os.Stderr, sp.failedMapping)
+			if err != nil {
+				log.Fatal(err)
+			}
 			_, err = fmt.Fprintf(os.Stderr, "\n")
 			if err != nil {
 				log.Fatal(err)
@@ -175,6 +198,26 @@ func (sp *defaultStatProcessor) process(workers uint) {
 	if err != nil {
 		log.Fatal(err)
 	}
+	err = writeFailedMap(os.Stdout, sp.failedMapping)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if sp.serviceMapping != nil {
+		_, err = fmt.Printf("Service times (without the wait for the intended start of the queries):\n")
+		if err != nil {
+			log.Fatal(err)
+		}
+		err = writeStatGroupMap(os.Stdout, sp.serviceMapping)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if sp.intervals != nil {
+		if err := sp.intervals.close(); err != nil {
+			log.Fatal(err)
+		}
+	}
 
 	if len(sp.args.hdrLatenciesFile) > 0 {
 		_, _ = fmt.Printf("Saving High Dynamic Range (HDR) Histogram of Response Latencies to %s\n", sp.args.hdrLatenciesFile)
@@ -194,6 +237,73 @@ func (sp *defaultStatProcessor) process(workers uint) {
 	sp.wg.Done()
 }
 
+// pushStat records the value of a successful query
+func (sp *defaultStatProcessor) pushStat(stat *Stat) {
+	if _, ok := sp.statMapping[string(stat.label)]; !ok {
+		sp.statMapping[string(stat.label)] = newStatGroup(*sp.args.limit)
+	}
+
+	sp.statMapping[string(stat.label)].push(stat.value)
+	sp.pushInterval(string(stat.label), stat)
+	sp.pushService(string(stat.label), stat)
+
+	if !stat.isPartial {
+		sp.statMapping[labelAllQueries].push(stat.value)
+
+		// Only needed when differentiating between cold & warm
+		if sp.args.prewarmQueries {
+			if stat.isWarm {
+				sp.statMapping[labelWarmQueries].push(stat.value)
+			} else {
+				sp.statMapping[labelColdQueries].push(stat.value)
+			}
+		}
+	}
+}
+
+// statLabels returns the labels stat is recorded under: its own label, and
+// unless it is partial the same aggregate labels as the overall statistics.
+func (sp *defaultStatProcessor) statLabels(label string, stat *Stat) []string {
+	labels := []string{label}
+	if !stat.isPartial {
+		labels = append(labels, labelAllQueries)
+		if sp.args.prewarmQueries {
+			if stat.isWarm {
+				labels = append(labels, labelWarmQueries)
+			} else {
+				labels = append(labels, labelColdQueries)
+			}
+		}
+	}
+	return labels
+}
+
+// pushInterval records the latency of stat in the current interval
+func (sp *defaultStatProcessor) pushInterval(label string, stat *Stat) {
+	if sp.intervals == nil {
+		return
+	}
+	now := time.Now()
+	for _, l := range sp.statLabels(label, stat) {
+		if err := sp.intervals.push(now, l, stat.value); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+// pushService records the service value of stat, if the run is open-loop
+func (sp *defaultStatProcessor) pushService(label string, stat *Stat) {
+	if sp.serviceMapping == nil {
+		return
+	}
+	for _, l := range sp.statLabels(label, stat) {
+		if _, ok := sp.serviceMapping[l]; !ok {
+			sp.serviceMapping[l] = newStatGroup(*sp.args.limit)
+		}
+		sp.serviceMapping[l].push(stat.serviceValue)
+	}
+}
+
 func generateQuantileMap(hist *hdrhistogram.Histogram) (int64, map[string]float64) {
 	ops := hist.TotalCount()
 	q0 := 0.0
@@ -238,6 +348,20 @@ func (sp *defaultStatProcessor) GetTotalsMap() map[string]interface{} {
 		quantiles[stripRegex(label)] = all
 	}
 	totals["overallQuantiles"] = quantiles
+	// count failed queries
+	failed := map[string]interface{}{stripRegex(labelAllQueries): uint64(0)}
+	for label, count := range sp.failedMapping {
+		failed[stripRegex(label)] = count
+	}
+	totals["failedQueries"] = failed
+	if sp.serviceMapping != nil {
+		serviceQuantiles := make(map[string]interface{})
+		for label, statGroup := range sp.serviceMapping {
+			_, all := generateQuantileMap(statGroup.latencyHDRHistogram)
+			serviceQuantiles[stripRegex(label)] = all
+		}
+		totals["overallServiceQuantiles"] = serviceQuantiles
+	}
 	return totals
 }
 
@@ -20,6 +20,11 @@ type Stat struct {
 	value     float64
 	isWarm    bool
 	isPartial bool
+	// serviceValue is the value without the wait for the intended start of
+	// the query, only set in open-loop runs
+	serviceValue float64
+	// isFailed is set if the query failed, in which case there is no value
+	isFailed bool
 }
 
 var statPool = &sync.Pool{
@@ -43,20 +48,47 @@ func GetPartialStat() *Stat {
 	return s
 }
 
+// getFailedStat returns a Stat from the pool that records a failed query
+func getFailedStat(label []byte, isWarm bool) *Stat {
+	s := GetStat().Init(label, 0)
+	s.isWarm = isWarm
+	s.isFailed = true
+	return s
+}
+
 // Init safely initializes a Stat while minimizing heap allocations.
 func (s *Stat) Init(label []byte, value float64) *Stat {
 	s.label = s.label[:0] // clear
 	s.label = append(s.label, label...)
 	s.value = value
+	s.serviceValue = 0.0
 	s.isWarm = false
+	s.isFailed = false
 	return s
 }
 
+// Label returns the label of the Stat
+func (s *Stat) Label() []byte {
+	return s.label
+}
+
+// Value returns the measured value of the Stat (typically a latency in milliseconds)
+func (s *Stat) Value() float64 {
+	return s.value
+}
+
+// IsPartial returns whether the Stat measures only a part of a query
+func (s *Stat) IsPartial() bool {
+	return s.isPartial
+}
+
 func (s *Stat) reset() *Stat {
 	s.label = s.label[:0]
 	s.value = 0.0
+	s.serviceValue = 0.0
 	s.isWarm = false
 	s.isPartial = false
+	s.isFailed = false
 	return s
 }
 
@@ -107,6 +139,18 @@ func (s *statGroup) write(w io.Writer) error {
 	return err
 }
 
+// quantile returns the value at quantile q (0-100) of the StatGroup in milliseconds
+func (s *statGroup) quantile(q float64) float64 {
+	return float64(s.latencyHDRHistogram.ValueAtQuantile(q)) / hdrScaleFactor
+}
+
+// reset clears all the values pushed to the StatGroup
+func (s *statGroup) reset() {
+	s.latencyHDRHistogram.Reset()
+	s.sum = 0
+	s.count = 0
+}
+
 // Median returns the Median value of the StatGroup in milliseconds
 func (s *statGroup) Median() float64 {
 	return float64(s.latencyHDRHistogram.ValueAtQuantile(50.0)) / hdrScaleFactor
@@ -163,3 +207,25 @@ func writeStatGroupMap(w io.Writer, statGroups map[string]*statGroup) error {
 	}
 	return nil
 }
+
+// writeFailedMap writes the number of failed queries per label, if any
+// query failed
+func writeFailedMap(w io.Writer, failed map[string]uint64) error {
+	if len(failed) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(failed))
+	for k := range failed {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	if _, err := fmt.Fprintf(w, "Failed queries:\n"); err != nil {
+		return err
+	}
+	for _, k := range keys {
+		if _, err := fmt.Fprintf(w, "%s: %d\n", k, failed[k]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
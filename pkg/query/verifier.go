@@ -0,0 +1,148 @@
+package query
+
+import (
+	"encoding/gob"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+)
+
+// Reference is the expected result of a generated query, as written by
+// tsbs_generate_queries when a reference file is requested.
+type Reference struct {
+	// ID is the position of the query in the generated queries file
+	ID          uint64
+	Label       string
+	Description string
+	// Result is nil if the expected result of the query could not be computed
+	Result *Result
+}
+
+// verifyCounts are the verification counts for a single query label
+type verifyCounts struct {
+	Checked    uint64 `json:"checked"`
+	Mismatched uint64 `json:"mismatched"`
+	Unverified uint64 `json:"unverified"`
+}
+
+// verifier compares the results of the queries against their references and
+// keeps count of the mismatches per query label.
+type verifier struct {
+	references map[uint64]*Reference
+	debug      int
+
+	mu     sync.Mutex
+	counts map[string]*verifyCounts
+}
+
+// newVerifier reads the references from the given file and returns a
+// verifier for them.
+func newVerifier(fileName string, debug int) (*verifier, error) {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open reference file %s: %v", fileName, err)
+	}
+	defer f.Close()
+
+	references, err := readReferences(f)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read reference file %s: %v", fileName, err)
+	}
+	return &verifier{
+		references: references,
+		debug:      debug,
+		counts:     make(map[string]*verifyCounts),
+	}, nil
+}
+
+func readReferences(r io.Reader) (map[uint64]*Reference, error) {
+	references := make(map[uint64]*Reference)
+	decoder := gob.NewDecoder(r)
+	for {
+		ref := &Reference{}
+		err := decoder.Decode(ref)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		references[ref.ID] = ref
+	}
+	return references, nil
+}
+
+// check compares the result of q with its reference. Queries without a
+// reference, or whose reference could not be computed, are counted as
+// unverified.
+func (v *verifier) check(q Query, result *Result) {
+	label := string(q.HumanLabelName())
+	ref, ok := v.references[q.GetID()]
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	counts, ok2 := v.counts[label]
+	if !ok2 {
+		counts = &verifyCounts{}
+		v.counts[label] = counts
+	}
+
+	if !ok || ref.Result == nil {
+		counts.Unverified++
+		return
+	}
+
+	counts.Checked++
+	if ref.Description != string(q.HumanDescriptionName()) {
+		counts.Mismatched++
+		if v.debug > 0 {
+			fmt.Fprintf(os.Stderr, "query %d does not match its reference: got '%s', reference is for '%s'\n",
+				q.GetID(), q.HumanDescriptionName(), ref.Description)
+		}
+		return
+	}
+	if result == nil || !result.Matches(ref.Result) {
+		counts.Mismatched++
+		if v.debug > 0 {
+			fmt.Fprintf(os.Stderr, "result mismatch for query %d (%s):\ngot:  %v\nwant: %v\n",
+				q.GetID(), q.HumanDescriptionName(), result, ref.Result)
+		}
+	}
+}
+
+// writeSummary writes the verification counts per query label to w
+func (v *verifier) writeSummary(w io.Writer) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	labels := make([]string, 0, len(v.counts))
+	for label := range v.counts {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+
+	if _, err := fmt.Fprintf(w, "Result verification:\n"); err != nil {
+		return err
+	}
+	for _, label := range labels {
+		c := v.counts[label]
+		_, err := fmt.Fprintf(w, "%s: %d checked, %d mismatched, %d unverified\n", label, c.Checked, c.Mismatched, c.Unverified)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getTotalsMap returns the verification counts per query label
+func (v *verifier) getTotalsMap() map[string]interface{} {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	totals := make(map[string]interface{}, len(v.counts))
+	for label, c := range v.counts {
+		totals[label] = *c
+	}
+	return totals
+}
@@ -0,0 +1,62 @@
+package query
+
+import (
+	"bytes"
+	"encoding/gob"
+	"testing"
+)
+
+func TestVerifierCheck(t *testing.T) {
+	result := &Result{Rows: []ResultRow{{Time: 1, Values: []float64{10}}}}
+	refs := []*Reference{
+		{ID: 0, Label: "a", Description: "a: 1", Result: result},
+		{ID: 1, Label: "a", Description: "a: 2", Result: result},
+		{ID: 2, Label: "b", Description: "b: 1"},
+	}
+	var buf bytes.Buffer
+	enc := gob.NewEncoder(&buf)
+	for _, r := range refs {
+		if err := enc.Encode(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	references, err := readReferences(&buf)
+	if err != nil {
+		t.Fatalf("unexpected error reading references: %v", err)
+	}
+	v := &verifier{references: references, counts: make(map[string]*verifyCounts)}
+
+	newQuery := func(id uint64, label, desc string) Query {
+		q := NewHTTP()
+		q.HumanLabel = []byte(label)
+		q.HumanDescription = []byte(desc)
+		q.SetID(id)
+		return q
+	}
+	wrong := &Result{Rows: []ResultRow{{Time: 1, Values: []float64{11}}}}
+
+	v.check(newQuery(0, "a", "a: 1"), result)
+	v.check(newQuery(1, "a", "a: 2"), wrong)
+	v.check(newQuery(1, "a", "a: 3"), result)
+	v.check(newQuery(2, "b", "b: 1"), result)
+	v.check(newQuery(3, "b", "b: 2"), result)
+
+	want := map[string]verifyCounts{
+		"a": {Checked: 3, Mismatched: 2},
+		"b": {Unverified: 2},
+	}
+	for label, w := range want {
+		if got := *v.counts[label]; got != w {
+			t.Errorf("incorrect counts for %s: got %+v want %+v", label, got, w)
+		}
+	}
+
+	var out bytes.Buffer
+	if err := v.writeSummary(&out); err != nil {
+		t.Fatal(err)
+	}
+	wantSummary := "Result verification:\na: 3 checked, 2 mismatched, 0 unverified\nb: 0 checked, 0 mismatched, 2 unverified\n"
+	if got := out.String(); got != wantSummary {
+		t.Errorf("incorrect summary:\ngot\n%s\nwant\n%s", got, wantSummary)
+	}
+}
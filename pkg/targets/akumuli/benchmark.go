@@ -1,27 +1,51 @@
 package akumuli
 
 import (
-	"github.com/timescale/tsbs/load"
-	"github.com/timescale/tsbs/pkg/targets"
+	"bytes"
 	"sync"
+
+	"github.com/blagojts/viper"
+	"github.com/timescale/tsbs/internal/inputs"
+	"github.com/timescale/tsbs/pkg/data/source"
+	"github.com/timescale/tsbs/pkg/targets"
 )
 
-func NewBenchmark(loadFileName, endpoint string, bufPool *sync.Pool) targets.Benchmark {
-	return &benchmark{
-		loadFileName: loadFileName,
-		endpoint:     endpoint,
-		bufPool:      bufPool,
+type SpecificConfig struct {
+	Endpoint string `yaml:"endpoint" mapstructure:"endpoint"`
+}
+
+func parseSpecificConfig(v *viper.Viper) (*SpecificConfig, error) {
+	var conf SpecificConfig
+	if err := v.Unmarshal(&conf); err != nil {
+		return nil, err
 	}
+	return &conf, nil
+}
+
+func NewBenchmark(conf *SpecificConfig, dataSourceConfig *source.DataSourceConfig) (targets.Benchmark, error) {
+	br, err := inputs.GetDataSourceReader(dataSourceConfig, NewTarget())
+	if err != nil {
+		return nil, err
+	}
+	return &benchmark{
+		dataSource: &fileDataSource{reader: br},
+		endpoint:   conf.Endpoint,
+		bufPool: &sync.Pool{
+			New: func() interface{} {
+				return bytes.NewBuffer(make([]byte, 0, 4*1024*1024))
+			},
+		},
+	}, nil
 }
 
 type benchmark struct {
-	loadFileName string
-	endpoint     string
-	bufPool      *sync.Pool
+	dataSource targets.DataSource
+	endpoint   string
+	bufPool    *sync.Pool
 }
 
 func (b *benchmark) GetDataSource() targets.DataSource {
-	return &fileDataSource{reader: load.GetBufferedReader(b.loadFileName)}
+	return b.dataSource
 }
 
 func (b *benchmark) GetBatchFactory() targets.BatchFactory {
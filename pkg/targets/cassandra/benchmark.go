@@ -2,25 +2,20 @@ package cassandra
 
 import (
 	"bufio"
-	"errors"
 	"fmt"
 	"github.com/gocql/gocql"
-	"github.com/timescale/tsbs/load"
+	"github.com/timescale/tsbs/internal/inputs"
 	"github.com/timescale/tsbs/pkg/data/source"
 	"github.com/timescale/tsbs/pkg/targets"
 	"log"
 )
 
 type benchmark struct {
-	dbc                *dbCreator
-	dataSourceFileName string
+	dbc        *dbCreator
+	dataSource targets.DataSource
 }
 
 func NewBenchmark(dbSpecificConfig *SpecificConfig, dsConfig *source.DataSourceConfig) (targets.Benchmark, error) {
-	if dsConfig.Type != source.FileDataSourceType {
-		return nil, errors.New("only FILE data source implemented for Cassandra")
-	}
-
 	if _, ok := consistencyMapping[dbSpecificConfig.ConsistencyLevel]; !ok {
 		return nil, fmt.Errorf(
 			"invalid consistency level %s; allowed: %v",
@@ -28,6 +23,10 @@ func NewBenchmark(dbSpecificConfig *SpecificConfig, dsConfig *source.DataSourceC
 			consistencyMapping,
 		)
 	}
+	br, err := inputs.GetDataSourceReader(dsConfig, NewTarget())
+	if err != nil {
+		return nil, err
+	}
 	return &benchmark{
 		dbc: &dbCreator{
 			hosts:             dbSpecificConfig.Hosts,
@@ -35,12 +34,12 @@ func NewBenchmark(dbSpecificConfig *SpecificConfig, dsConfig *source.DataSourceC
 			replicationFactor: dbSpecificConfig.ReplicationFactor,
 			writeTimeout:      dbSpecificConfig.WriteTimeout,
 		},
-		dataSourceFileName: dsConfig.File.Location,
+		dataSource: &fileDataSource{scanner: bufio.NewScanner(br)},
 	}, nil
 }
 
 func (b *benchmark) GetDataSource() targets.DataSource {
-	return &fileDataSource{scanner: bufio.NewScanner(load.GetBufferedReader(b.dataSourceFileName))}
+	return b.dataSource
 }
 
 func (b *benchmark) GetBatchFactory() targets.BatchFactory {
@@ -5,22 +5,32 @@ import (
 	"fmt"
 	"log"
 
-	"github.com/timescale/tsbs/load"
+	"github.com/blagojts/viper"
+	"github.com/timescale/tsbs/internal/inputs"
 	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/data/source"
 	"github.com/timescale/tsbs/pkg/targets"
 )
 
 const dbType = "clickhouse"
 
 type ClickhouseConfig struct {
-	Host     string
-	User     string
-	Password string
+	Host     string `yaml:"host" mapstructure:"host"`
+	User     string `yaml:"user" mapstructure:"user"`
+	Password string `yaml:"password" mapstructure:"password"`
+
+	LogBatches bool   `yaml:"log-batches" mapstructure:"log-batches"`
+	InTableTag bool   `yaml:"-" mapstructure:"-"`
+	Debug      int    `yaml:"debug" mapstructure:"debug"`
+	DbName     string `yaml:"-" mapstructure:"-"`
+}
 
-	LogBatches bool
-	InTableTag bool
-	Debug      int
-	DbName     string
+func parseSpecificConfig(v *viper.Viper) (*ClickhouseConfig, error) {
+	var conf ClickhouseConfig
+	if err := v.Unmarshal(&conf); err != nil {
+		return nil, err
+	}
+	return &conf, nil
 }
 
 // String values of tags and fields to insert - string representation
@@ -90,21 +100,23 @@ func (f *factory) New() targets.Batch {
 
 const tagsPrefix = "tags"
 
-func NewBenchmark(file string, hashWorkers bool, conf *ClickhouseConfig) targets.Benchmark {
+func NewBenchmark(conf *ClickhouseConfig, dataSourceConfig *source.DataSourceConfig) (targets.Benchmark, error) {
+	br, err := inputs.GetDataSourceReader(dataSourceConfig, NewTarget())
+	if err != nil {
+		return nil, err
+	}
 	return &benchmark{
 		ds: &fileDataSource{
-			scanner: bufio.NewScanner(load.GetBufferedReader(file)),
+			scanner: bufio.NewScanner(br),
 		},
-		hashWorkers: hashWorkers,
-		conf:        conf,
-	}
+		conf: conf,
+	}, nil
 }
 
 // targets.Benchmark interface implementation
 type benchmark struct {
-	ds          targets.DataSource
-	hashWorkers bool
-	conf        *ClickhouseConfig
+	ds   targets.DataSource
+	conf *ClickhouseConfig
 }
 
 func (b *benchmark) GetDataSource() targets.DataSource {
@@ -116,7 +128,7 @@ func (b *benchmark) GetBatchFactory() targets.BatchFactory {
 }
 
 func (b *benchmark) GetPointIndexer(maxPartitions uint) targets.PointIndexer {
-	if b.hashWorkers {
+	if maxPartitions > 1 {
 		return &hostnameIndexer{
 			partitions: maxPartitions,
 		}
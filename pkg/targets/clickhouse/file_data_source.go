@@ -21,7 +21,6 @@ func (d *fileDataSource) NextItem() data.LoadedPoint {
 	// tags,hostname=host_0,region=eu-west-1,datacenter=eu-west-1b,rack=67,os=Ubuntu16.10,arch=x86,team=NYC,service=7,service_version=0,service_environment=production
 	// cpu,1451606400000000000,58,2,24,61,22,63,6,44,80,38
 
-	newPoint := &insertData{}
 	ok := d.scanner.Scan()
 	if !ok && d.scanner.Err() == nil {
 		// nothing scanned & no error = EOF
@@ -30,11 +29,35 @@ func (d *fileDataSource) NextItem() data.LoadedPoint {
 		fatal("scan error: %v", d.scanner.Err())
 		return data.LoadedPoint{}
 	}
+	// the scanner reuses its buffer for the data line
+	tags := append([]byte(nil), d.scanner.Bytes()...)
+
+	// Scan again to get the data line
+	ok = d.scanner.Scan()
+	if !ok {
+		fatal("scan error: %v", d.scanner.Err())
+		return data.LoadedPoint{}
+	}
+	return d.DecodeLines([][]byte{tags, d.scanner.Bytes()})
+}
+
+// Lines returns the scanner of the lines of the points, once the headers are read
+func (d *fileDataSource) Lines() *bufio.Scanner {
+	return d.scanner
+}
+
+// LinesPerItem returns 2, as every point is a line of tags and a line of fields
+func (d *fileDataSource) LinesPerItem() int {
+	return 2
+}
 
+// DecodeLines decodes the point of a line of tags and a line of fields
+func (d *fileDataSource) DecodeLines(lines [][]byte) data.LoadedPoint {
+	newPoint := &insertData{}
 	// The first line is a CSV line of tags with the first element being "tags"
 	// Ex.:
 	// tags,hostname=host_0,region=eu-west-1,datacenter=eu-west-1b,rack=67,os=Ubuntu16.10,arch=x86,team=NYC,service=7,service_version=0,service_environment=production
-	parts := strings.SplitN(d.scanner.Text(), ",", 2) // prefix & then rest of line
+	parts := strings.SplitN(string(lines[0]), ",", 2) // prefix & then rest of line
 	prefix := parts[0]
 	if prefix != tagsPrefix {
 		fatal("data file in invalid format; got %s expected %s", prefix, tagsPrefix)
@@ -42,14 +65,9 @@ func (d *fileDataSource) NextItem() data.LoadedPoint {
 	}
 	newPoint.tags = parts[1]
 
-	// Scan again to get the data line
+	// The second line is the data line
 	// cpu,1451606400000000000,58,2,24,61,22,63,6,44,80,38
-	ok = d.scanner.Scan()
-	if !ok {
-		fatal("scan error: %v", d.scanner.Err())
-		return data.LoadedPoint{}
-	}
-	parts = strings.SplitN(d.scanner.Text(), ",", 2) // prefix & then rest of line
+	parts = strings.SplitN(string(lines[1]), ",", 2) // prefix & then rest of line
 	prefix = parts[0]
 	newPoint.fields = parts[1]
 
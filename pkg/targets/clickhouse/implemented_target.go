@@ -16,8 +16,14 @@ func NewTarget() targets.ImplementedTarget {
 
 type clickhouseTarget struct{}
 
-func (c clickhouseTarget) Benchmark(string, *source.DataSourceConfig, *viper.Viper) (targets.Benchmark, error) {
-	panic("implement me")
+func (c clickhouseTarget) Benchmark(targetDB string, dataSourceConfig *source.DataSourceConfig, v *viper.Viper) (targets.Benchmark, error) {
+	clickhouseSpecificConfig, err := parseSpecificConfig(v)
+	if err != nil {
+		return nil, err
+	}
+	clickhouseSpecificConfig.DbName = targetDB
+
+	return NewBenchmark(clickhouseSpecificConfig, dataSourceConfig)
 }
 
 func (c clickhouseTarget) Serializer() serialize.PointSerializer {
@@ -1,4 +1,4 @@
-package main
+package crate
 
 import (
 	"bufio"
@@ -73,10 +73,24 @@ func (d *fileDataSource) NextItem() data.LoadedPoint {
 		fatal("scan error: %v", d.scanner.Err())
 		return data.LoadedPoint{}
 	}
+	return d.DecodeLines([][]byte{d.scanner.Bytes()})
+}
+
+// Lines returns the scanner of the lines of the items
+func (d *fileDataSource) Lines() *bufio.Scanner {
+	return d.scanner
+}
+
+// LinesPerItem returns 1, as every line is a point
+func (d *fileDataSource) LinesPerItem() int {
+	return 1
+}
 
+// DecodeLines decodes the point of a line
+func (d *fileDataSource) DecodeLines(lines [][]byte) data.LoadedPoint {
 	// split a point record into a measurement type, timestamp, tags,
 	// and field values
-	parts := strings.SplitN(d.scanner.Text(), "\t", 4)
+	parts := strings.SplitN(string(lines[0]), "\t", 4)
 	if len(parts) != 4 {
 		fatal("incorrect point format, some fields are missing")
 		return data.LoadedPoint{}
@@ -0,0 +1,96 @@
+package influx
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/blagojts/viper"
+	"github.com/timescale/tsbs/internal/inputs"
+	"github.com/timescale/tsbs/load"
+	"github.com/timescale/tsbs/pkg/data/source"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+var consistencyChoices = map[string]struct{}{
+	"any":    {},
+	"one":    {},
+	"quorum": {},
+	"all":    {},
+}
+
+type SpecificConfig struct {
+	URLs              []string      `yaml:"urls" mapstructure:"urls"`
+	ReplicationFactor int           `yaml:"replication-factor" mapstructure:"replication-factor"`
+	Consistency       string        `yaml:"consistency" mapstructure:"consistency"`
+	Backoff           time.Duration `yaml:"backoff" mapstructure:"backoff"`
+	UseGzip           bool          `yaml:"gzip" mapstructure:"gzip"`
+}
+
+func parseSpecificConfig(v *viper.Viper) (*SpecificConfig, error) {
+	var conf SpecificConfig
+	if err := v.Unmarshal(&conf); err != nil {
+		return nil, err
+	}
+	return &conf, nil
+}
+
+// loader.Benchmark interface implementation
+type benchmark struct {
+	conf       *SpecificConfig
+	dbName     string
+	dataSource targets.DataSource
+	bufPool    *sync.Pool
+}
+
+func NewBenchmark(dbName string, conf *SpecificConfig, dataSourceConfig *source.DataSourceConfig) (targets.Benchmark, error) {
+	if _, ok := consistencyChoices[conf.Consistency]; !ok {
+		return nil, fmt.Errorf("invalid consistency settings: %s", conf.Consistency)
+	}
+	if len(conf.URLs) == 0 {
+		return nil, errors.New("missing 'urls' flag")
+	}
+
+	br, err := inputs.GetDataSourceReader(dataSourceConfig, NewTarget())
+	if err != nil {
+		return nil, err
+	}
+	ds := &fileDataSource{scanner: bufio.NewScanner(br)}
+	if dataSourceConfig.Type == source.FileDataSourceType {
+		ds.offsets = load.TrackLineOffsets(ds.scanner, dataSourceConfig.File.Location)
+	}
+	return &benchmark{
+		conf:       conf,
+		dbName:     dbName,
+		dataSource: ds,
+		bufPool: &sync.Pool{
+			New: func() interface{} {
+				return bytes.NewBuffer(make([]byte, 0, 4*1024*1024))
+			},
+		},
+	}, nil
+}
+
+func (b *benchmark) GetDataSource() targets.DataSource {
+	return b.dataSource
+}
+
+func (b *benchmark) GetBatchFactory() targets.BatchFactory {
+	return &factory{bufPool: b.bufPool}
+}
+
+func (b *benchmark) GetPointIndexer(_ uint) targets.PointIndexer {
+	return &targets.ConstantIndexer{}
+}
+
+func (b *benchmark) GetProcessor() targets.Processor {
+	return &processor{conf: b.conf, dbName: b.dbName, bufPool: b.bufPool}
+}
+
+func (b *benchmark) GetDBCreator() targets.DBCreator {
+	// pick first URL since it always exists
+	return &dbCreator{daemonURL: b.conf.URLs[0], replicationFactor: b.conf.ReplicationFactor}
+}
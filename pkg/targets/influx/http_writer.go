@@ -1,4 +1,4 @@
-package main
+package influx
 
 // This file lifted wholesale from mountainflux by Mark Rushakoff.
 
@@ -1,33 +1,41 @@
-package main
+package influx
 
 import (
 	"bytes"
 	"fmt"
-	"github.com/timescale/tsbs/pkg/targets"
+	"log"
+	"sync"
 	"time"
 
+	"github.com/timescale/tsbs/pkg/targets"
 	"github.com/valyala/fasthttp"
 )
 
 const backingOffChanCap = 100
 
 // allows for testing
-var printFn = fmt.Printf
+var (
+	printFn = fmt.Printf
+	fatal   = log.Fatalf
+)
 
 type processor struct {
 	backingOffChan chan bool
 	backingOffDone chan struct{}
 	httpWriter     *HTTPWriter
+	conf           *SpecificConfig
+	dbName         string
+	bufPool        *sync.Pool
 }
 
 func (p *processor) Init(numWorker int, _, _ bool) {
-	daemonURL := daemonURLs[numWorker%len(daemonURLs)]
+	daemonURL := p.conf.URLs[numWorker%len(p.conf.URLs)]
 	cfg := HTTPWriterConfig{
 		DebugInfo: fmt.Sprintf("worker #%d, dest url: %s", numWorker, daemonURL),
 		Host:      daemonURL,
-		Database:  loader.DatabaseName(),
+		Database:  p.dbName,
 	}
-	w := NewHTTPWriter(cfg, consistency)
+	w := NewHTTPWriter(cfg, p.conf.Consistency)
 	p.initWithHTTPWriter(numWorker, w)
 }
 
@@ -50,20 +58,20 @@ func (p *processor) ProcessBatch(b targets.Batch, doLoad bool) (uint64, uint64)
 	if doLoad {
 		var err error
 		for {
-			if useGzip {
-				compressedBatch := bufPool.Get().(*bytes.Buffer)
+			if p.conf.UseGzip {
+				compressedBatch := p.bufPool.Get().(*bytes.Buffer)
 				fasthttp.WriteGzip(compressedBatch, batch.buf.Bytes())
 				_, err = p.httpWriter.WriteLineProtocol(compressedBatch.Bytes(), true)
 				// Return the compressed batch buffer to the pool.
 				compressedBatch.Reset()
-				bufPool.Put(compressedBatch)
+				p.bufPool.Put(compressedBatch)
 			} else {
 				_, err = p.httpWriter.WriteLineProtocol(batch.buf.Bytes(), false)
 			}
 
 			if err == errBackoff {
 				p.backingOffChan <- true
-				time.Sleep(backoff)
+				time.Sleep(p.conf.Backoff)
 			} else {
 				p.backingOffChan <- false
 				break
@@ -78,7 +86,7 @@ func (p *processor) ProcessBatch(b targets.Batch, doLoad bool) (uint64, uint64)
 
 	// Return the batch buffer to the pool.
 	batch.buf.Reset()
-	bufPool.Put(batch.buf)
+	p.bufPool.Put(batch.buf)
 	return metricCnt, uint64(rowCnt)
 }
 
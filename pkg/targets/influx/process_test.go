@@ -1,4 +1,4 @@
-package main
+package influx
 
 import (
 	"bytes"
@@ -17,26 +17,28 @@ func emptyLog(_ string, _ ...interface{}) (int, error) {
 }
 
 func TestProcessorInit(t *testing.T) {
-	daemonURLs = []string{"url1", "url2"}
+	daemonURLs := []string{"url1", "url2"}
+	conf := &SpecificConfig{URLs: daemonURLs, Consistency: testConsistency}
+	dbName := "benchmark"
 	printFn = emptyLog
-	p := &processor{}
+	p := &processor{conf: conf, dbName: dbName}
 	p.Init(0, false, false)
 	p.Close(true)
 	if got := p.httpWriter.c.Host; got != daemonURLs[0] {
 		t.Errorf("incorrect host: got %s want %s", got, daemonURLs[0])
 	}
-	if got := p.httpWriter.c.Database; got != loader.DatabaseName() {
-		t.Errorf("incorrect database: got %s want %s", got, loader.DatabaseName())
+	if got := p.httpWriter.c.Database; got != dbName {
+		t.Errorf("incorrect database: got %s want %s", got, dbName)
 	}
 
-	p = &processor{}
+	p = &processor{conf: conf, dbName: dbName}
 	p.Init(1, false, false)
 	p.Close(true)
 	if got := p.httpWriter.c.Host; got != daemonURLs[1] {
 		t.Errorf("incorrect host: got %s want %s", got, daemonURLs[1])
 	}
 
-	p = &processor{}
+	p = &processor{conf: conf, dbName: dbName}
 	p.Init(len(daemonURLs), false, false)
 	p.Close(true)
 	if got := p.httpWriter.c.Host; got != daemonURLs[0] {
@@ -86,12 +88,12 @@ func TestProcessorInitWithHTTPWriterConfig(t *testing.T) {
 }
 
 func TestProcessorProcessBatch(t *testing.T) {
-	bufPool = sync.Pool{
+	bufPool := &sync.Pool{
 		New: func() interface{} {
 			return bytes.NewBuffer(make([]byte, 0, 4*1024*1024))
 		},
 	}
-	f := &factory{}
+	f := &factory{bufPool: bufPool}
 	b := f.New().(*batch)
 	pt := data.LoadedPoint{
 		Data: []byte("tag1=tag1val,tag2=tag2val col1=0.0,col2=0.0 140"),
@@ -142,7 +144,7 @@ func TestProcessorProcessBatch(t *testing.T) {
 			ch = launchHTTPServer()
 		}
 
-		p := &processor{}
+		p := &processor{conf: &SpecificConfig{UseGzip: c.useGzip}, bufPool: bufPool}
 		w := NewHTTPWriter(testConf, testConsistency)
 
 		// If the case should backoff, we tell our dummy server to do so by
@@ -154,7 +156,6 @@ func TestProcessorProcessBatch(t *testing.T) {
 		}
 
 		p.initWithHTTPWriter(0, w)
-		useGzip = c.useGzip
 		mCnt, rCnt := p.ProcessBatch(b, c.doLoad)
 		if c.shouldFatal {
 			if !fatalCalled {
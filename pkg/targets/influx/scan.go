@@ -0,0 +1,126 @@
+package influx
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/timescale/tsbs/load"
+	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/data/usecases/common"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+const errNotThreeTuplesFmt = "parse error: line does not have 3 tuples, has %d"
+
+var newLine = []byte("\n")
+
+type fileDataSource struct {
+	scanner *bufio.Scanner
+	// offsets is nil unless the data is read from a file
+	offsets *load.LineOffsets
+}
+
+func (d *fileDataSource) NextItem() data.LoadedPoint {
+	ok := d.scanner.Scan()
+	if !ok && d.scanner.Err() == nil { // nothing scanned & no error = EOF
+		return data.LoadedPoint{}
+	} else if !ok {
+		fatal("scan error: %v", d.scanner.Err())
+		return data.LoadedPoint{}
+	}
+	return data.NewLoadedPoint(d.scanner.Bytes())
+}
+
+func (d *fileDataSource) Headers() *common.GeneratedDataHeaders { return nil }
+
+// Lines returns the scanner of the lines of the items
+func (d *fileDataSource) Lines() *bufio.Scanner { return d.scanner }
+
+// LinesPerItem returns 1, as every line is an item
+func (d *fileDataSource) LinesPerItem() int { return 1 }
+
+// DecodeLines counts the metrics of the line when decoding in parallel, so
+// that appending it to a batch is cheap
+func (d *fileDataSource) DecodeLines(lines [][]byte) data.LoadedPoint {
+	return data.NewLoadedPoint(&decodedLine{line: lines[0], metrics: countMetrics(lines[0])})
+}
+
+// decodedLine is a line of which the metrics are counted
+type decodedLine struct {
+	line    []byte
+	metrics uint64
+}
+
+// countMetrics returns the number of fields of a line
+func countMetrics(line []byte) uint64 {
+	// Each influx line is format "csv-tags csv-fields timestamp", so we split by space
+	// and then on the middle element, we split by comma to count number of fields added
+	args := strings.Split(string(line), " ")
+	if len(args) != 3 {
+		fatal(errNotThreeTuplesFmt, len(args))
+		return 0
+	}
+	return uint64(len(strings.Split(args[1], ",")))
+}
+
+// Offset returns the byte offset of the next item in the file, or -1 if the
+// data is not read from a file
+func (d *fileDataSource) Offset() int64 {
+	if d.offsets == nil {
+		return -1
+	}
+	return d.offsets.Offset()
+}
+
+// ResumeAt continues reading the file at byte offset offset
+func (d *fileDataSource) ResumeAt(offset int64) error {
+	if d.offsets == nil {
+		return errors.New("cannot resume: the data is not read from a file")
+	}
+	scanner, err := d.offsets.Reopen(offset)
+	if err != nil {
+		return err
+	}
+	d.scanner = scanner
+	return nil
+}
+
+type batch struct {
+	buf     *bytes.Buffer
+	rows    uint
+	metrics uint64
+}
+
+func (b *batch) Len() uint {
+	return b.rows
+}
+
+func (b *batch) Append(item data.LoadedPoint) {
+	var that []byte
+	b.rows++
+	if l, ok := item.Data.(*decodedLine); ok {
+		that = l.line
+		b.metrics += l.metrics
+	} else {
+		that = item.Data.([]byte)
+		metrics := countMetrics(that)
+		if metrics == 0 {
+			return
+		}
+		b.metrics += metrics
+	}
+
+	b.buf.Write(that)
+	b.buf.Write(newLine)
+}
+
+type factory struct {
+	bufPool *sync.Pool
+}
+
+func (f *factory) New() targets.Batch {
+	return &batch{buf: f.bufPool.Get().(*bytes.Buffer)}
+}
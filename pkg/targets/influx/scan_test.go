@@ -1,4 +1,4 @@
-package main
+package influx
 
 import (
 	"bufio"
@@ -11,12 +11,12 @@ import (
 )
 
 func TestBatch(t *testing.T) {
-	bufPool = sync.Pool{
+	bufPool := &sync.Pool{
 		New: func() interface{} {
 			return bytes.NewBuffer(make([]byte, 0, 4*1024*1024))
 		},
 	}
-	f := &factory{}
+	f := &factory{bufPool: bufPool}
 	b := f.New().(*batch)
 	if b.Len() != 0 {
 		t.Errorf("batch not initialized with count 0")
@@ -103,3 +103,18 @@ func TestDecodeEOF(t *testing.T) {
 		t.Errorf("expected p to be nil, got %v", p)
 	}
 }
+
+func TestFileDataSourceDecodeLines(t *testing.T) {
+	ds := &fileDataSource{}
+	line := []byte("cpu,tag1=tag1text col1=0.0,col2=0.0,col3=1 140")
+	p := ds.DecodeLines([][]byte{line})
+	bufPool := &sync.Pool{New: func() interface{} { return &bytes.Buffer{} }}
+	b := (&factory{bufPool: bufPool}).New().(*batch)
+	b.Append(p)
+	if b.rows != 1 || b.metrics != 3 {
+		t.Errorf("incorrect counts of a decoded line: got %d rows, %d metrics", b.rows, b.metrics)
+	}
+	if got := b.buf.String(); got != string(line)+"\n" {
+		t.Errorf("incorrect batch of a decoded line: got %q", got)
+	}
+}
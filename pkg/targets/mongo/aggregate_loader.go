@@ -1,4 +1,4 @@
-package main
+package mongo
 
 import (
 	"fmt"
@@ -9,10 +9,8 @@ import (
 
 	"github.com/globalsign/mgo"
 	"github.com/globalsign/mgo/bson"
-	"github.com/timescale/tsbs/load"
 	"github.com/timescale/tsbs/pkg/data"
 	"github.com/timescale/tsbs/pkg/targets"
-	"github.com/timescale/tsbs/pkg/targets/mongo"
 )
 
 type hostnameIndexer struct {
@@ -20,8 +18,8 @@ type hostnameIndexer struct {
 }
 
 func (i *hostnameIndexer) GetIndex(item data.LoadedPoint) uint {
-	p := item.Data.(*mongo.MongoPoint)
-	t := &mongo.MongoTag{}
+	p := item.Data.(*MongoPoint)
+	t := &MongoTag{}
 	for j := 0; j < p.TagsLength(); j++ {
 		p.Tags(t, j)
 		key := string(t.Key())
@@ -43,21 +41,27 @@ type aggBenchmark struct {
 	mongoBenchmark
 }
 
-func newAggBenchmark(l load.BenchmarkRunner, conf *load.BenchmarkRunnerConfig) *aggBenchmark {
+func newAggBenchmark(base mongoBenchmark) *aggBenchmark {
 	// Pre-create the needed empty subdoc for new aggregate docs
 	generateEmptyHourDoc()
 
-	return &aggBenchmark{mongoBenchmark{conf.FileName, l, &dbCreator{}}}
+	return &aggBenchmark{base}
 }
 
 func (b *aggBenchmark) GetProcessor() targets.Processor {
-	return &aggProcessor{dbc: b.dbc}
+	return &aggProcessor{dbc: b.dbc, dbName: b.dbName}
 }
 
 func (b *aggBenchmark) GetPointIndexer(maxPartitions uint) targets.PointIndexer {
 	return &hostnameIndexer{partitions: maxPartitions}
 }
 
+// RequiresHashWorkers is true, since the aggregated document of a host and
+// hour is only built correctly if all its points are loaded by one worker
+func (b *aggBenchmark) RequiresHashWorkers() bool {
+	return true
+}
+
 // point is a reusable data structure to store a BSON data document for Mongo,
 // that can then be manipulated for bookkeeping and final document preparation
 type point struct {
@@ -78,6 +82,7 @@ var pPool = &sync.Pool{New: func() interface{} { return &point{} }}
 
 type aggProcessor struct {
 	dbc        *dbCreator
+	dbName     string
 	collection *mgo.Collection
 
 	createdDocs map[string]bool
@@ -87,7 +92,7 @@ type aggProcessor struct {
 func (p *aggProcessor) Init(_ int, doLoad, _ bool) {
 	if doLoad {
 		sess := p.dbc.session.Copy()
-		db := sess.DB(loader.DatabaseName())
+		db := sess.DB(p.dbName)
 		p.collection = db.C(collectionName)
 	}
 	p.createdDocs = make(map[string]bool)
@@ -127,7 +132,7 @@ func (p *aggProcessor) ProcessBatch(b targets.Batch, doLoad bool) (uint64, uint6
 	eventCnt := uint64(0)
 	for _, event := range batch.arr {
 		tagsMap := map[string]string{}
-		t := &mongo.MongoTag{}
+		t := &MongoTag{}
 		for j := 0; j < event.TagsLength(); j++ {
 			event.Tags(t, j)
 			tagsMap[string(t.Key())] = string(t.Value())
@@ -161,7 +166,7 @@ func (p *aggProcessor) ProcessBatch(b targets.Batch, doLoad bool) (uint64, uint6
 		}
 		x := pPool.Get().(*point)
 		x.Fields = map[string]interface{}{}
-		f := &mongo.MongoReading{}
+		f := &MongoReading{}
 		for j := 0; j < event.FieldsLength(); j++ {
 			event.Fields(f, j)
 			x.Fields[string(f.Key())] = f.Value()
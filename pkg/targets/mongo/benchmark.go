@@ -0,0 +1,54 @@
+package mongo
+
+import (
+	"time"
+
+	"github.com/blagojts/viper"
+	"github.com/timescale/tsbs/internal/inputs"
+	"github.com/timescale/tsbs/pkg/data/source"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+const (
+	collectionName     = "point_data"
+	aggDocID           = "doc_id"
+	aggDateFmt         = "20060102_15" // see Go docs for how we arrive at this time format
+	aggKeyID           = "key_id"
+	aggInsertBatchSize = 500 // found via trial-and-error
+	timestampField     = "timestamp_ns"
+)
+
+type SpecificConfig struct {
+	URL              string        `yaml:"url" mapstructure:"url"`
+	WriteTimeout     time.Duration `yaml:"write-timeout" mapstructure:"write-timeout"`
+	DocumentPerEvent bool          `yaml:"document-per-event" mapstructure:"document-per-event"`
+}
+
+func parseSpecificConfig(v *viper.Viper) (*SpecificConfig, error) {
+	var conf SpecificConfig
+	if err := v.Unmarshal(&conf); err != nil {
+		return nil, err
+	}
+	return &conf, nil
+}
+
+// NewBenchmark creates a benchmark storing one document per event if
+// DocumentPerEvent is set and aggregated documents per host and hour otherwise.
+// The aggregated documents are only created correctly if the loader hashes the
+// data of a host to the same worker (hash-workers), so that benchmark is a
+// targets.HashingBenchmark.
+func NewBenchmark(dbName string, conf *SpecificConfig, dataSourceConfig *source.DataSourceConfig) (targets.Benchmark, error) {
+	br, err := inputs.GetDataSourceReader(dataSourceConfig, NewTarget())
+	if err != nil {
+		return nil, err
+	}
+	base := mongoBenchmark{
+		dataSource: &fileDataSource{lenBuf: make([]byte, 8), r: br},
+		dbName:     dbName,
+		dbc:        &dbCreator{conf: conf},
+	}
+	if conf.DocumentPerEvent {
+		return newNaiveBenchmark(base), nil
+	}
+	return newAggBenchmark(base), nil
+}
@@ -1,4 +1,4 @@
-package main
+package mongo
 
 import (
 	"fmt"
@@ -11,11 +11,12 @@ import (
 
 type dbCreator struct {
 	session *mgo.Session
+	conf    *SpecificConfig
 }
 
 func (d *dbCreator) Init() {
 	var err error
-	d.session, err = mgo.DialWithTimeout(daemonURL, writeTimeout)
+	d.session, err = mgo.DialWithTimeout(d.conf.URL, d.conf.WriteTimeout)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -70,7 +71,7 @@ func (d *dbCreator) CreateDB(dbName string) error {
 
 	collection := d.session.DB(dbName).C(collectionName)
 	var key []string
-	if documentPer {
+	if d.conf.DocumentPerEvent {
 		key = []string{"measurement", "tags.hostname", timestampField}
 	} else {
 		key = []string{aggKeyID, "measurement", "tags.hostname"}
@@ -89,7 +90,7 @@ func (d *dbCreator) CreateDB(dbName string) error {
 
 	// To make updates for new records more efficient, we need a efficient doc
 	// lookup index
-	if !documentPer {
+	if !d.conf.DocumentPerEvent {
 		err = collection.EnsureIndex(mgo.Index{
 			Key:        []string{aggDocID},
 			Unique:     false,
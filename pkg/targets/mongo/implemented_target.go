@@ -31,6 +31,11 @@ func (t *mongoTarget) Serializer() serialize.PointSerializer {
 	return &Serializer{}
 }
 
-func (t *mongoTarget) Benchmark(string, *source.DataSourceConfig, *viper.Viper) (targets.Benchmark, error) {
-	panic("not implemented")
+func (t *mongoTarget) Benchmark(targetDB string, dataSourceConfig *source.DataSourceConfig, v *viper.Viper) (targets.Benchmark, error) {
+	mongoSpecificConfig, err := parseSpecificConfig(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewBenchmark(targetDB, mongoSpecificConfig, dataSourceConfig)
 }
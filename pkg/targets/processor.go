@@ -14,3 +14,27 @@ type ProcessorCloser interface {
 	// Close cleans up after a Processor
 	Close(doLoad bool)
 }
+
+// BatchRetrier runs the writes of batches according to the retry policy of
+// the loader, which also counts the retried and failed batches
+type BatchRetrier interface {
+	// Do runs write until it succeeds, returning the last error if all retries failed
+	Do(write func() error) error
+}
+
+// RetryingProcessor is a Processor that reports the failed writes of its
+// batches into the retry policy of the loader
+type RetryingProcessor interface {
+	Processor
+	// SetRetrier sets the BatchRetrier to write batches with, before Init is called
+	SetRetrier(r BatchRetrier)
+}
+
+// Retry runs write with the BatchRetrier r, or only once if r is nil, as for
+// Processors used without a loader
+func Retry(r BatchRetrier, write func() error) error {
+	if r == nil {
+		return write()
+	}
+	return r.Do(write)
+}
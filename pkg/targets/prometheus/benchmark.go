@@ -82,18 +82,26 @@ func (pd *FileDataSource) Headers() *common.GeneratedDataHeaders {
 type Processor struct {
 	client    *Client
 	batchPool *sync.Pool
+	retrier   targets.BatchRetrier
 }
 
 func (pp *Processor) Init(_ int, _, _ bool) {}
 
+// SetRetrier sets the retry policy for failed writes
+func (pp *Processor) SetRetrier(r targets.BatchRetrier) {
+	pp.retrier = r
+}
+
 // ProcessBatch ..
 func (pp *Processor) ProcessBatch(b targets.Batch, doLoad bool) (uint64, uint64) {
 	promBatch := b.(*Batch)
 	nrSamples := uint64(promBatch.Len())
 	if doLoad {
-		err := pp.client.Post(promBatch.series)
+		err := targets.Retry(pp.retrier, func() error {
+			return pp.client.Post(promBatch.series)
+		})
 		if err != nil {
-			panic(err)
+			nrSamples = 0
 		}
 	}
 	// reset batch
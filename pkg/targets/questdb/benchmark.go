@@ -0,0 +1,73 @@
+package questdb
+
+import (
+	"bufio"
+	"bytes"
+	"sync"
+
+	"github.com/blagojts/viper"
+	"github.com/timescale/tsbs/internal/inputs"
+	"github.com/timescale/tsbs/load"
+	"github.com/timescale/tsbs/pkg/data/source"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+type SpecificConfig struct {
+	URL       string `yaml:"url" mapstructure:"url"`
+	ILPBindTo string `yaml:"ilp-bind-to" mapstructure:"ilp-bind-to"`
+}
+
+func parseSpecificConfig(v *viper.Viper) (*SpecificConfig, error) {
+	var conf SpecificConfig
+	if err := v.Unmarshal(&conf); err != nil {
+		return nil, err
+	}
+	return &conf, nil
+}
+
+// loader.Benchmark interface implementation
+type benchmark struct {
+	conf       *SpecificConfig
+	dataSource targets.DataSource
+	bufPool    *sync.Pool
+}
+
+func NewBenchmark(conf *SpecificConfig, dataSourceConfig *source.DataSourceConfig) (targets.Benchmark, error) {
+	br, err := inputs.GetDataSourceReader(dataSourceConfig, NewTarget())
+	if err != nil {
+		return nil, err
+	}
+	ds := &fileDataSource{scanner: bufio.NewScanner(br)}
+	if dataSourceConfig.Type == source.FileDataSourceType {
+		ds.offsets = load.TrackLineOffsets(ds.scanner, dataSourceConfig.File.Location)
+	}
+	return &benchmark{
+		conf:       conf,
+		dataSource: ds,
+		bufPool: &sync.Pool{
+			New: func() interface{} {
+				return bytes.NewBuffer(make([]byte, 0, 4*1024*1024))
+			},
+		},
+	}, nil
+}
+
+func (b *benchmark) GetDataSource() targets.DataSource {
+	return b.dataSource
+}
+
+func (b *benchmark) GetBatchFactory() targets.BatchFactory {
+	return &factory{bufPool: b.bufPool}
+}
+
+func (b *benchmark) GetPointIndexer(_ uint) targets.PointIndexer {
+	return &targets.ConstantIndexer{}
+}
+
+func (b *benchmark) GetProcessor() targets.Processor {
+	return &processor{ilpBindTo: b.conf.ILPBindTo, bufPool: b.bufPool}
+}
+
+func (b *benchmark) GetDBCreator() targets.DBCreator {
+	return &dbCreator{questdbRESTEndPoint: b.conf.URL}
+}
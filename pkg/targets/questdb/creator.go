@@ -1,4 +1,4 @@
-package main
+package questdb
 
 import (
 	"encoding/json"
@@ -15,12 +15,10 @@ type dbCreator struct {
 	questdbRESTEndPoint string
 }
 
-func (d *dbCreator) Init() {
-	d.questdbRESTEndPoint = questdbRESTEndPoint
-}
+func (d *dbCreator) Init() {}
 
 func (d *dbCreator) DBExists(dbName string) bool {
-	r, err := execQuery(questdbRESTEndPoint, "SHOW TABLES")
+	r, err := execQuery(d.questdbRESTEndPoint, "SHOW TABLES")
 	if err != nil {
 		panic(fmt.Errorf("fatal error, failed to query questdb: %s", err))
 	}
@@ -0,0 +1,90 @@
+package questdb
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"sync"
+
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+// allows for testing
+var (
+	printFn = fmt.Printf
+	fatal   = log.Fatalf
+)
+
+type processor struct {
+	ilpBindTo string
+	bufPool   *sync.Pool
+	ilpConn   (*net.TCPConn)
+	retrier   targets.BatchRetrier
+}
+
+func (p *processor) Init(numWorker int, _, _ bool) {
+	if err := p.connect(); err != nil {
+		fatal("%s\n", err.Error())
+	}
+}
+
+// SetRetrier sets the retry policy for failed writes
+func (p *processor) SetRetrier(r targets.BatchRetrier) {
+	p.retrier = r
+}
+
+func (p *processor) connect() error {
+	tcpAddr, err := net.ResolveTCPAddr("tcp4", p.ilpBindTo)
+	if err != nil {
+		return fmt.Errorf("Failed to resolve %s: %s", p.ilpBindTo, err.Error())
+	}
+	p.ilpConn, err = net.DialTCP("tcp", nil, tcpAddr)
+	if err != nil {
+		return fmt.Errorf("Failed connect to %s: %s", p.ilpBindTo, err.Error())
+	}
+	return nil
+}
+
+func (p *processor) Close(_ bool) {
+	if p.ilpConn != nil {
+		p.ilpConn.Close()
+	}
+}
+
+func (p *processor) ProcessBatch(b targets.Batch, doLoad bool) (uint64, uint64) {
+	batch := b.(*batch)
+
+	metricCnt := batch.metrics
+	rowCnt := batch.rows
+
+	// Write the batch, retrying as the loader's retry policy allows
+	if doLoad {
+		err := targets.Retry(p.retrier, func() error {
+			return p.write(batch.buf.Bytes())
+		})
+		if err != nil {
+			metricCnt, rowCnt = 0, 0
+		}
+	}
+
+	// Return the batch buffer to the pool.
+	batch.buf.Reset()
+	p.bufPool.Put(batch.buf)
+	return metricCnt, uint64(rowCnt)
+}
+
+// write writes the lines in b, reconnecting first if the previous write failed
+func (p *processor) write(b []byte) error {
+	if p.ilpConn == nil {
+		if err := p.connect(); err != nil {
+			return err
+		}
+	}
+	if _, err := p.ilpConn.Write(b); err != nil {
+		// a retry must not continue a partially written line on the same connection
+		p.ilpConn.Close()
+		p.ilpConn = nil
+		return fmt.Errorf("Error writing: %s", err.Error())
+	}
+	return nil
+}
@@ -1,4 +1,4 @@
-package main
+package questdb
 
 import (
 	"bytes"
@@ -49,7 +49,7 @@ func mockServerStart() *mockServer {
 					rc, err := conn.Read(data)
 					if err != nil {
 						if err != io.EOF {
-							fatal("failed to read from connection: ", err.Error())
+							fatal("failed to read from connection: %s\n", err.Error())
 						}
 						return
 					}
@@ -64,24 +64,24 @@ func mockServerStart() *mockServer {
 func TestProcessorInit(t *testing.T) {
 	ms := mockServerStart()
 	defer mockServerStop(ms)
-	questdbILPBindTo = fmt.Sprintf("127.0.0.1:%d", ms.listenPort)
+	ilpBindTo := fmt.Sprintf("127.0.0.1:%d", ms.listenPort)
 	printFn = emptyLog
-	p := &processor{}
+	p := &processor{ilpBindTo: ilpBindTo}
 	p.Init(0, false, false)
 	p.Close(true)
 
-	p = &processor{}
+	p = &processor{ilpBindTo: ilpBindTo}
 	p.Init(1, false, false)
 	p.Close(true)
 }
 
 func TestProcessorProcessBatch(t *testing.T) {
-	bufPool = sync.Pool{
+	bufPool := &sync.Pool{
 		New: func() interface{} {
 			return bytes.NewBuffer(make([]byte, 0, 4*1024*1024))
 		},
 	}
-	f := &factory{}
+	f := &factory{bufPool: bufPool}
 	b := f.New().(*batch)
 	pt := data.LoadedPoint{
 		Data: []byte("tag1=tag1val,tag2=tag2val col1=0.0,col2=0.0 140\n"),
@@ -106,9 +106,7 @@ func TestProcessorProcessBatch(t *testing.T) {
 		}
 
 		ms := mockServerStart()
-		questdbILPBindTo = fmt.Sprintf("127.0.0.1:%d", ms.listenPort)
-
-		p := &processor{}
+		p := &processor{ilpBindTo: fmt.Sprintf("127.0.0.1:%d", ms.listenPort), bufPool: bufPool}
 		p.Init(0, true, true)
 		mCnt, rCnt := p.ProcessBatch(b, c.doLoad)
 		if mCnt != b.metrics {
@@ -0,0 +1,73 @@
+package siridb
+
+import (
+	"log"
+
+	"github.com/blagojts/viper"
+	"github.com/timescale/tsbs/internal/inputs"
+	"github.com/timescale/tsbs/pkg/data/source"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+// allows for testing
+var fatal = log.Fatal
+
+type SpecificConfig struct {
+	DBUser       string `yaml:"dbuser" mapstructure:"dbuser"`
+	DBPass       string `yaml:"dbpass" mapstructure:"dbpass"`
+	Hosts        string `yaml:"hosts" mapstructure:"hosts"`
+	Replica      bool   `yaml:"replica" mapstructure:"replica"`
+	LogBatches   bool   `yaml:"log-batches" mapstructure:"log-batches"`
+	WriteTimeout int    `yaml:"write-timeout" mapstructure:"write-timeout"`
+}
+
+func parseSpecificConfig(v *viper.Viper) (*SpecificConfig, error) {
+	var conf SpecificConfig
+	if err := v.Unmarshal(&conf); err != nil {
+		return nil, err
+	}
+	return &conf, nil
+}
+
+// loader.Benchmark interface implementation
+type benchmark struct {
+	conf       *SpecificConfig
+	dbName     string
+	dataSource targets.DataSource
+}
+
+func NewBenchmark(dbName string, conf *SpecificConfig, dataSourceConfig *source.DataSourceConfig) (targets.Benchmark, error) {
+	br, err := inputs.GetDataSourceReader(dataSourceConfig, NewTarget())
+	if err != nil {
+		return nil, err
+	}
+	return &benchmark{
+		conf:   conf,
+		dbName: dbName,
+		dataSource: &fileDataSource{
+			buf: make([]byte, 0),
+			len: 0,
+			br:  br,
+		},
+	}, nil
+}
+
+func (b *benchmark) GetDataSource() targets.DataSource {
+	return b.dataSource
+}
+
+func (b *benchmark) GetBatchFactory() targets.BatchFactory {
+	return &factory{}
+}
+
+func (b *benchmark) GetPointIndexer(maxPartitions uint) targets.PointIndexer {
+	return &targets.ConstantIndexer{}
+}
+
+func (b *benchmark) GetProcessor() targets.Processor {
+	return &processor{conf: b.conf, dbName: b.dbName}
+}
+
+func (b *benchmark) GetDBCreator() targets.DBCreator {
+	return &dbCreator{conf: b.conf}
+}
@@ -1,4 +1,4 @@
-package main
+package siridb
 
 import (
 	"errors"
@@ -21,12 +21,12 @@ const (
 type dbCreator struct {
 	connection []*siridb.Connection
 	hosts      []string
-	replica    []string
+	conf       *SpecificConfig
 }
 
 // Init should set up any connection or other setup for talking to the DB, but should NOT create any databases
 func (d *dbCreator) Init() {
-	d.hosts = strings.Split(hosts, ",")
+	d.hosts = strings.Split(d.conf.Hosts, ",")
 	d.connection = make([]*siridb.Connection, 0)
 	for _, hostport := range d.hosts {
 		x := strings.Split(hostport, ":")
@@ -42,7 +42,7 @@ func (d *dbCreator) Init() {
 // DBExists checks if a database with the given name currently exists.
 func (d *dbCreator) DBExists(dbName string) bool {
 	for _, conn := range d.connection {
-		if err := conn.Connect(dbUser, dbPass, dbName); err == nil {
+		if err := conn.Connect(d.conf.DBUser, d.conf.DBPass, dbName); err == nil {
 			return true
 		}
 	}
@@ -76,13 +76,13 @@ func (d *dbCreator) CreateDB(dbName string) error {
 			fatal(err)
 		}
 
-		if !replica {
+		if !d.conf.Replica {
 			optionsNewPool := make(map[string]interface{})
 			optionsNewPool["dbname"] = dbName
 			optionsNewPool["host"] = host
 			optionsNewPool["port"] = port
-			optionsNewPool["username"] = dbUser
-			optionsNewPool["password"] = dbPass
+			optionsNewPool["username"] = d.conf.DBUser
+			optionsNewPool["password"] = d.conf.DBPass
 
 			if _, err := d.connection[1].Manage(account, password, siridb.AdminNewPool, optionsNewPool); err != nil {
 				return err
@@ -93,8 +93,8 @@ func (d *dbCreator) CreateDB(dbName string) error {
 			optionsNewReplica["dbname"] = dbName
 			optionsNewReplica["host"] = host
 			optionsNewReplica["port"] = port
-			optionsNewReplica["username"] = dbUser
-			optionsNewReplica["password"] = dbPass
+			optionsNewReplica["username"] = d.conf.DBUser
+			optionsNewReplica["password"] = d.conf.DBPass
 			optionsNewReplica["pool"] = 0
 
 			if _, err := d.connection[1].Manage(account, password, siridb.AdminNewReplica, optionsNewReplica); err != nil {
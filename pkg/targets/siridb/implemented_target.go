@@ -35,6 +35,11 @@ func (t *siriTarget) Serializer() serialize.PointSerializer {
 	return &Serializer{}
 }
 
-func (t *siriTarget) Benchmark(string, *source.DataSourceConfig, *viper.Viper) (targets.Benchmark, error) {
-	panic("not implemented")
+func (t *siriTarget) Benchmark(targetDB string, dataSourceConfig *source.DataSourceConfig, v *viper.Viper) (targets.Benchmark, error) {
+	siriSpecificConfig, err := parseSpecificConfig(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewBenchmark(targetDB, siriSpecificConfig, dataSourceConfig)
 }
@@ -1,6 +1,8 @@
 package targets
 
 import (
+	"bufio"
+
 	"github.com/blagojts/viper"
 	"github.com/spf13/pflag"
 	"github.com/timescale/tsbs/pkg/data"
@@ -71,7 +73,41 @@ type Benchmark interface {
 	GetDBCreator() DBCreator
 }
 
+// HashingBenchmark is a Benchmark that only loads correctly if the loader
+// sends all the items with the same index of its PointIndexer to the same
+// worker, i.e. with hash-workers
+type HashingBenchmark interface {
+	Benchmark
+	// RequiresHashWorkers returns whether the loader must run with hash-workers
+	RequiresHashWorkers() bool
+}
+
 type DataSource interface {
 	NextItem() data.LoadedPoint
 	Headers() *common.GeneratedDataHeaders
 }
+
+// OffsetDataSource is a DataSource that knows the byte offsets of its items
+// in a file, so that a load can be resumed from a checkpoint by seeking
+// instead of reading all the items loaded before
+type OffsetDataSource interface {
+	DataSource
+	// Offset returns the byte offset of the next item, or -1 if it is unknown
+	Offset() int64
+	// ResumeAt continues reading items at byte offset offset
+	ResumeAt(offset int64) error
+}
+
+// LineDataSource is a DataSource of which each item is decoded from a fixed
+// number of lines of its input, so that the loader can split the input into
+// chunks of lines and decode the chunks in parallel
+type LineDataSource interface {
+	DataSource
+	// Lines returns the scanner of the lines of the items, once the headers are read
+	Lines() *bufio.Scanner
+	// LinesPerItem returns the number of lines each item is decoded from
+	LinesPerItem() int
+	// DecodeLines decodes the item of lines. It is called concurrently, and
+	// the item may keep the lines.
+	DecodeLines(lines [][]byte) data.LoadedPoint
+}
@@ -80,7 +80,6 @@ func (d *fileDataSource) NextItem() data.LoadedPoint {
 		fatal("headers not read before starting to decode points")
 		return data.LoadedPoint{}
 	}
-	newPoint := &insertData{}
 	ok := d.scanner.Scan()
 	if !ok && d.scanner.Err() == nil { // nothing scanned & no error = EOF
 		return data.LoadedPoint{}
@@ -88,9 +87,33 @@ func (d *fileDataSource) NextItem() data.LoadedPoint {
 		fatal("scan error: %v", d.scanner.Err())
 		return data.LoadedPoint{}
 	}
+	// the scanner reuses its buffer for the data line
+	tags := append([]byte(nil), d.scanner.Bytes()...)
+
+	// Scan again to get the data line
+	ok = d.scanner.Scan()
+	if !ok {
+		fatal("scan error: %v", d.scanner.Err())
+		return data.LoadedPoint{}
+	}
+	return d.DecodeLines([][]byte{tags, d.scanner.Bytes()})
+}
+
+// Lines returns the scanner of the lines of the points, once the headers are read
+func (d *fileDataSource) Lines() *bufio.Scanner {
+	return d.scanner
+}
+
+// LinesPerItem returns 2, as every point is a line of tags and a line of fields
+func (d *fileDataSource) LinesPerItem() int {
+	return 2
+}
 
+// DecodeLines decodes the point of a line of tags and a line of fields
+func (d *fileDataSource) DecodeLines(lines [][]byte) data.LoadedPoint {
+	newPoint := &insertData{}
 	// The first line is a CSV line of tags with the first element being "tags"
-	parts := strings.SplitN(d.scanner.Text(), ",", 2) // prefix & then rest of line
+	parts := strings.SplitN(string(lines[0]), ",", 2) // prefix & then rest of line
 	prefix := parts[0]
 	if prefix != tagsKey {
 		fatal("data file in invalid format; got %s expected %s", prefix, tagsKey)
@@ -98,13 +121,7 @@ func (d *fileDataSource) NextItem() data.LoadedPoint {
 	}
 	newPoint.tags = parts[1]
 
-	// Scan again to get the data line
-	ok = d.scanner.Scan()
-	if !ok {
-		fatal("scan error: %v", d.scanner.Err())
-		return data.LoadedPoint{}
-	}
-	parts = strings.SplitN(d.scanner.Text(), ",", 2) // prefix & then rest of line
+	parts = strings.SplitN(string(lines[1]), ",", 2) // prefix & then rest of line
 	prefix = parts[0]
 	newPoint.fields = parts[1]
 
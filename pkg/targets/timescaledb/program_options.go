@@ -5,6 +5,8 @@ import (
 	"regexp"
 	"strings"
 	"time"
+
+	"github.com/blagojts/viper"
 )
 
 // Loading option vars:
@@ -41,6 +43,52 @@ type LoadingOptions struct {
 	UseInsert          bool     `yaml:"use-insert" mapstructure:"use-insert"`
 }
 
+// ParseLoadingOptions reads the LoadingOptions from the flags added by the
+// TargetSpecificFlags of the timescaledb target to v
+func ParseLoadingOptions(v *viper.Viper) *LoadingOptions {
+	opts := LoadingOptions{}
+	v.SetTypeByDefaultValue(true)
+	opts.PostgresConnect = v.GetString("postgres")
+	opts.Host = v.GetString("host")
+	opts.Port = v.GetString("port")
+	opts.User = v.GetString("user")
+	opts.Pass = v.GetString("pass")
+	opts.ConnDB = v.GetString("admin-db-name")
+	opts.LogBatches = v.GetBool("log-batches")
+
+	opts.UseHypertable = v.GetBool("use-hypertable")
+	opts.ChunkTime = v.GetDuration("chunk-time")
+
+	opts.UseJSON = v.GetBool("use-jsonb-tags")
+
+	// This must be set to 'true' if you are going to test
+	// distributed hypertable queries and insert. Replication
+	// factor must also be set to true for distributed hypertables
+	opts.InTableTag = v.GetBool("in-table-partition-tag")
+
+	// 	We currently use `create_hypertable` for all variations. When
+	//   `replication-factor`>=1, we automatically create a distributed
+	//   hypertable.
+	opts.ReplicationFactor = v.GetInt("replication-factor")
+	// Currently ignored for distributed hypertables. We assume all
+	// data nodes will be used based on the partition-column above
+	opts.NumberPartitions = v.GetInt("partitions")
+
+	opts.TimeIndex = v.GetBool("time-index")
+	opts.TimePartitionIndex = v.GetBool("time-partition-index")
+	opts.PartitionIndex = v.GetBool("partition-index")
+	opts.FieldIndex = v.GetString("field-index")
+	opts.FieldIndexCount = v.GetInt("field-index-count")
+
+	opts.ProfileFile = v.GetString("write-profile")
+	opts.ReplicationStatsFile = v.GetString("write-replication-stats")
+	opts.CreateMetricsTable = v.GetBool("create-metrics-table")
+
+	opts.ForceTextFormat = v.GetBool("force-text-format")
+	opts.UseInsert = v.GetBool("use-insert")
+	return &opts
+}
+
 func (o *LoadingOptions) GetConnectString(dbName string) string {
 	// User might be passing in host=hostname the connect string out of habit which may override the
 	// multi host configuration. Same for dbname= and user=. This sanitizes that.
@@ -35,10 +35,10 @@ func NewBenchmark(vmSpecificConfig *SpecificConfig, dataSourceConfig *source.Dat
 	}
 
 	br := load.GetBufferedReader(dataSourceConfig.File.Location)
+	ds := &fileDataSource{scanner: bufio.NewScanner(br)}
+	ds.offsets = load.TrackLineOffsets(ds.scanner, dataSourceConfig.File.Location)
 	return &benchmark{
-		dataSource: &fileDataSource{
-			scanner: bufio.NewScanner(br),
-		},
+		dataSource: ds,
 		serverURLs: vmSpecificConfig.ServerURLs,
 	}, nil
 }
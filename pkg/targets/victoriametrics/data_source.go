@@ -2,6 +2,8 @@ package victoriametrics
 
 import (
 	"bufio"
+	"errors"
+	"github.com/timescale/tsbs/load"
 	"github.com/timescale/tsbs/pkg/data"
 	"github.com/timescale/tsbs/pkg/data/usecases/common"
 	"log"
@@ -9,6 +11,8 @@ import (
 
 type fileDataSource struct {
 	scanner *bufio.Scanner
+	// offsets is nil unless the data is read from a file
+	offsets *load.LineOffsets
 }
 
 func (f fileDataSource) NextItem() data.LoadedPoint {
@@ -25,6 +29,43 @@ func (f fileDataSource) Headers() *common.GeneratedDataHeaders {
 	return nil
 }
 
+// Lines returns the scanner of the lines of the items
+func (f *fileDataSource) Lines() *bufio.Scanner {
+	return f.scanner
+}
+
+// LinesPerItem returns 1, as every line is an item
+func (f *fileDataSource) LinesPerItem() int {
+	return 1
+}
+
+// DecodeLines returns the line as the item
+func (f *fileDataSource) DecodeLines(lines [][]byte) data.LoadedPoint {
+	return data.NewLoadedPoint(lines[0])
+}
+
+// Offset returns the byte offset of the next item in the file, or -1 if the
+// data is not read from a file
+func (f *fileDataSource) Offset() int64 {
+	if f.offsets == nil {
+		return -1
+	}
+	return f.offsets.Offset()
+}
+
+// ResumeAt continues reading the file at byte offset offset
+func (f *fileDataSource) ResumeAt(offset int64) error {
+	if f.offsets == nil {
+		return errors.New("cannot resume: the data is not read from a file")
+	}
+	scanner, err := f.offsets.Reopen(offset)
+	if err != nil {
+		return err
+	}
+	f.scanner = scanner
+	return nil
+}
+
 type decoder struct {
 	scanner *bufio.Scanner
 }
@@ -2,21 +2,26 @@ package victoriametrics
 
 import (
 	"bytes"
+	"fmt"
 	"github.com/timescale/tsbs/pkg/targets"
-	"log"
 	"net/http"
-	"time"
 )
 
 type processor struct {
-	url    string
-	vmURLs []string
+	url     string
+	vmURLs  []string
+	retrier targets.BatchRetrier
 }
 
 func (p *processor) Init(workerNum int, doLoad, hashWorkers bool) {
 	p.url = p.vmURLs[workerNum%len(p.vmURLs)]
 }
 
+// SetRetrier sets the retry policy for failed writes
+func (p *processor) SetRetrier(r targets.BatchRetrier) {
+	p.retrier = r
+}
+
 func (p *processor) ProcessBatch(b targets.Batch, doLoad bool) (metricCount, rowCount uint64) {
 	batch := b.(*batch)
 	if !doLoad {
@@ -27,22 +32,28 @@ func (p *processor) ProcessBatch(b targets.Batch, doLoad bool) (metricCount, row
 }
 
 func (p *processor) do(b *batch) (uint64, uint64) {
-	for {
-		r := bytes.NewReader(b.buf.Bytes())
-		req, err := http.NewRequest("POST", p.url, r)
-		if err != nil {
-			log.Fatalf("error while creating new request: %s", err)
-		}
-		resp, err := http.DefaultClient.Do(req)
-		if err != nil {
-			log.Fatalf("error while executing request: %s", err)
-		}
-		resp.Body.Close()
-		if resp.StatusCode == http.StatusNoContent {
-			b.buf.Reset()
-			return b.metrics, b.rows
-		}
-		log.Printf("server returned HTTP status %d. Retrying", resp.StatusCode)
-		time.Sleep(time.Millisecond * 10)
+	err := targets.Retry(p.retrier, func() error {
+		return p.write(b.buf.Bytes())
+	})
+	b.buf.Reset()
+	if err != nil {
+		return 0, 0
+	}
+	return b.metrics, b.rows
+}
+
+func (p *processor) write(body []byte) error {
+	req, err := http.NewRequest("POST", p.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("error while creating new request: %s", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error while executing request: %s", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("server returned HTTP status %d", resp.StatusCode)
 	}
+	return nil
 }
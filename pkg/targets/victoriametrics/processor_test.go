@@ -2,6 +2,7 @@ package victoriametrics
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"github.com/timescale/tsbs/pkg/data"
 	"net/http"
@@ -87,10 +88,62 @@ func TestProcessorProcessBatch(t *testing.T) {
 	}
 }
 
+// countingRetrier retries every failed write up to retries times
+type countingRetrier struct {
+	retries int
+	calls   int
+}
+
+func (r *countingRetrier) Do(write func() error) error {
+	var err error
+	for i := 0; i <= r.retries; i++ {
+		r.calls++
+		if err = write(); err == nil {
+			return nil
+		}
+	}
+	return errors.New("all retries failed")
+}
+
+func TestProcessorProcessBatchRetries(t *testing.T) {
+	f := &factory{bufPool: &sync.Pool{
+		New: func() interface{} {
+			return bytes.NewBuffer(make([]byte, 0, 1024))
+		},
+	}}
+	vm := startFakeVMServer(t)
+	atomic.StoreInt64(&vm.failures, 2)
+	newBatch := func() *batch {
+		b := f.New().(*batch)
+		b.Append(data.LoadedPoint{Data: []byte("tag1=tag1val col1=0.0 140")})
+		return b
+	}
+
+	p := &processor{vmURLs: []string{vm.server.URL}}
+	p.Init(1, false, false)
+	r := &countingRetrier{retries: 2}
+	p.SetRetrier(r)
+	if metrics, rows := p.ProcessBatch(newBatch(), true); metrics != 1 || rows != 1 {
+		t.Errorf("expected batch to be written after retries; got %d metrics %d rows", metrics, rows)
+	}
+	if r.calls != 3 {
+		t.Errorf("expected 3 attempts; got %d", r.calls)
+	}
+
+	atomic.StoreInt64(&vm.failures, 3)
+	r = &countingRetrier{retries: 2}
+	p.SetRetrier(r)
+	if metrics, rows := p.ProcessBatch(newBatch(), true); metrics != 0 || rows != 0 {
+		t.Errorf("expected failed batch to not be counted; got %d metrics %d rows", metrics, rows)
+	}
+}
+
 type fakeVMServer struct {
 	t      *testing.T
 	calls  uint64
 	server *httptest.Server
+	// failures is the number of the following requests that fail
+	failures int64
 }
 
 func (vm *fakeVMServer) incCalls()        { atomic.AddUint64(&vm.calls, 1) }
@@ -101,6 +154,10 @@ func (vm *fakeVMServer) handler(w http.ResponseWriter, r *http.Request) {
 		vm.t.Fatalf("unexpected HTTP method %q", r.Method)
 	}
 	vm.incCalls()
+	if atomic.AddInt64(&vm.failures, -1) >= 0 {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
 	w.WriteHeader(http.StatusNoContent)
 }
 